@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"ai-bridge/pkg/mcp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultToolTimeout 命令工具的默认超时时间
+const defaultToolTimeout = 30 * time.Second
+
+// Config MCP服务端配置文件
+type Config struct {
+	Name         string       `yaml:"name"`
+	Version      string       `yaml:"version"`
+	Instructions string       `yaml:"instructions"`
+	Transport    string       `yaml:"transport"` // stdio（默认）或 http
+	Addr         string       `yaml:"addr"`      // HTTP 监听地址，默认 127.0.0.1:8090（仅本机）
+	Path         string       `yaml:"path"`      // HTTP 路径，默认 /mcp
+	Examples     bool         `yaml:"examples"`  // 是否发布 mcp.ExampleTools
+	Tools        []ToolConfig `yaml:"tools"`
+
+	// HTTP 访问控制
+	Token          string        `yaml:"token"`            // Bearer 令牌（为空时读取 MCP_SERVER_TOKEN 环境变量，仍为空则不校验）
+	AllowedOrigins []string      `yaml:"allowed_origins"`  // 允许的浏览器来源（本机来源始终允许）
+	SessionIdleTTL time.Duration `yaml:"session_idle_ttl"` // 会话空闲超时，默认30分钟
+	MaxSessions    int           `yaml:"max_sessions"`     // 最大会话数，默认1000
+}
+
+// ToolConfig 命令工具配置
+// 调用时以JSON格式将参数写入命令的标准输入，标准输出作为工具结果；命令失败时返回标准错误输出。
+type ToolConfig struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Parameters  map[string]interface{} `yaml:"parameters"` // 参数的 JSON Schema
+	Command     []string               `yaml:"command"`
+	Dir         string                 `yaml:"dir"`
+	Env         []string               `yaml:"env"` // 追加的环境变量（KEY=VALUE）
+	Timeout     time.Duration          `yaml:"timeout"`
+}
+
+// LoadConfig 加载配置文件
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if cfg.Name == "" {
+		cfg.Name = "ai-bridge-mcp"
+	}
+	if cfg.Version == "" {
+		cfg.Version = "1.0.0"
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:8090"
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("MCP_SERVER_TOKEN")
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/mcp"
+	}
+	return &cfg, nil
+}
+
+// Registry 根据配置创建工具注册表
+func (c *Config) Registry() (*mcp.ToolRegistry, error) {
+	registry := mcp.NewToolRegistry()
+	if c.Examples {
+		for _, t := range mcp.ExampleTools() {
+			registry.Register(t)
+		}
+	}
+	for _, tc := range c.Tools {
+		if tc.Name == "" {
+			return nil, fmt.Errorf("tool name is required")
+		}
+		if len(tc.Command) == 0 {
+			return nil, fmt.Errorf("tool %s: command is required", tc.Name)
+		}
+		registry.Register(mcp.NewTool(tc.Name, tc.Description, tc.Parameters, tc.handler()))
+	}
+	return registry, nil
+}
+
+// handler 创建执行命令的工具处理函数
+func (tc ToolConfig) handler() mcp.ToolHandler {
+	timeout := tc.Timeout
+	if timeout <= 0 {
+		timeout = defaultToolTimeout
+	}
+	return func(ctx context.Context, params map[string]interface{}) (string, error) {
+		input, err := json.Marshal(params)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode arguments: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, tc.Command[0], tc.Command[1:]...)
+		cmd.Dir = tc.Dir
+		cmd.Env = append(os.Environ(), tc.Env...)
+		cmd.Stdin = bytes.NewReader(input)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("%s: %w: %s", tc.Name, err, msg)
+			}
+			return "", fmt.Errorf("%s: %w", tc.Name, err)
+		}
+		return stdout.String(), nil
+	}
+}
@@ -0,0 +1,91 @@
+// mcp-server 将配置文件中的工具作为MCP服务端发布（stdio 或 Streamable HTTP）
+//
+//	mcp-server -config tools.yaml
+//	mcp-server -config tools.yaml -transport http -addr 127.0.0.1:8090
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"ai-bridge/pkg/mcp"
+)
+
+func main() {
+	configPath := flag.String("config", "mcp-server.yaml", "配置文件路径")
+	transport := flag.String("transport", "", "传输方式：stdio 或 http（覆盖配置文件）")
+	addr := flag.String("addr", "", "HTTP 监听地址（覆盖配置文件，默认仅监听本机）")
+	flag.Parse()
+
+	// stdio 模式下标准输出用于协议消息，日志只能写到标准错误
+	log.SetOutput(os.Stderr)
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *transport != "" {
+		cfg.Transport = *transport
+	}
+	if *addr != "" {
+		cfg.Addr = *addr
+	}
+
+	registry, err := cfg.Registry()
+	if err != nil {
+		log.Fatalf("Failed to load tools: %v", err)
+	}
+	server := mcp.NewServer(registry,
+		mcp.WithServerInfo(cfg.Name, cfg.Version),
+		mcp.WithInstructions(cfg.Instructions),
+		mcp.WithAuthToken(cfg.Token),
+		mcp.WithAllowedOrigins(cfg.AllowedOrigins...),
+		mcp.WithSessionLimits(cfg.SessionIdleTTL, cfg.MaxSessions),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch cfg.Transport {
+	case "", "stdio":
+		if err := server.ServeStdio(ctx); err != nil && ctx.Err() == nil {
+			log.Fatalf("MCP server failed: %v", err)
+		}
+	case "http":
+		mux := http.NewServeMux()
+		mux.Handle(cfg.Path, server.HTTPHandler())
+		httpServer := &http.Server{Addr: cfg.Addr, Handler: mux}
+		go func() {
+			<-ctx.Done()
+			httpServer.Shutdown(context.Background())
+		}()
+		if cfg.Token == "" && !isLoopback(cfg.Addr) {
+			log.Printf("Warning: %s is reachable from other hosts without a token; set token or MCP_SERVER_TOKEN", cfg.Addr)
+		}
+		log.Printf("MCP server %s listening on %s%s", cfg.Name, cfg.Addr, cfg.Path)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("MCP server failed: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown transport %q (want stdio or http)", cfg.Transport)
+	}
+}
+
+// isLoopback 判断监听地址是否只绑定本机
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
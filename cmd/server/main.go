@@ -3,16 +3,28 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cloudwego/eino/schema"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 
 	"ai-bridge/pkg/bridge"
+	"ai-bridge/pkg/circuit"
+	"ai-bridge/pkg/cost"
+	"ai-bridge/pkg/metrics"
 	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/telemetry"
 	"ai-bridge/pkg/types"
 )
 
@@ -23,6 +35,7 @@ type ChatRequest struct {
 	Messages  []Message         `json:"messages"`
 	APIKey    string            `json:"api_key,omitempty"`
 	Stream    bool              `json:"stream,omitempty"`
+	Tag       string            `json:"tag,omitempty"` // 费用统计标签（如用户、团队）
 	Options   map[string]interface{} `json:"options,omitempty"`
 }
 
@@ -34,11 +47,16 @@ type Message struct {
 
 // ChatResponse 聊天响应
 type ChatResponse struct {
-	Content      string    `json:"content,omitempty"`
-	Error        string    `json:"error,omitempty"`
-	Provider     string    `json:"provider"`
-	Model        string    `json:"model"`
-	Stream       bool      `json:"stream"`
+	Content      string       `json:"content,omitempty"`
+	Error        string       `json:"error,omitempty"`
+	Provider     string       `json:"provider"`
+	Model        string       `json:"model"`
+	Stream       bool         `json:"stream"`
+	FinishReason string       `json:"finish_reason,omitempty"`
+	Usage        *types.Usage `json:"usage,omitempty"`
+	RequestID    string       `json:"request_id,omitempty"`
+	LatencyMs    int64        `json:"latency_ms,omitempty"`
+	Cost         float64      `json:"cost,omitempty"` // 本次费用（美元）
 }
 
 // ProvidersResponse 厂商列表响应
@@ -59,29 +77,280 @@ type ModelInfo struct {
 	MaxTokens   int    `json:"max_tokens"`
 }
 
+// costTracker 全局费用追踪器
+var costTracker = cost.NewCostTracker(nil)
+
+// errAPIKeyMissing 请求体和环境变量中均未提供API Key
+var errAPIKeyMissing = errors.New("api key not provided")
+
+// keyPools 各厂商的密钥池（来自环境变量 <provider>_API_KEYS，所有请求共享）
+var (
+	keyPoolsMu sync.Mutex
+	keyPools   = make(map[string]*bridge.KeyPool)
+)
+
+// 熔断器与健康探测（所有请求共享）
+var (
+	breakers        = circuit.NewRegistry()
+	breakerSettings = circuit.Settings{
+		OnStateChange: func(name string, from, to circuit.State) {
+			log.Printf("Circuit breaker %s: %s -> %s", name, from, to)
+		},
+	}
+	prober *bridge.HealthProber
+)
+
+// promMetrics Prometheus 指标（/metrics）
+var promMetrics = mustNewMetrics()
+
+// middlewares 所有客户端共享的调用中间件（第一个位于最外层）
+var middlewares = []types.Middleware{
+	bridge.RecoverMiddleware(),
+	telemetry.Middleware(),
+	promMetrics.Middleware(),
+}
+
+// mustNewMetrics 创建并注册 Prometheus 指标
+func mustNewMetrics() *metrics.Metrics {
+	m, err := metrics.New(prometheus.DefaultRegisterer)
+	if err != nil {
+		log.Fatalf("Failed to register metrics: %v", err)
+	}
+	return m
+}
+
+// ProviderHealth 厂商健康状态
+type ProviderHealth struct {
+	Status string                  `json:"status"` // ok、degraded、down
+	Models map[string]*ModelHealth `json:"models"`
+}
+
+// ModelHealth 模型健康状态
+type ModelHealth struct {
+	Healthy bool                `json:"healthy"`
+	Circuit *circuit.Status     `json:"circuit,omitempty"`
+	Probe   *bridge.ProbeResult `json:"probe,omitempty"`
+}
+
 func main() {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	// 加载价格配置文件（可选，覆盖内置价格）
+	if path := os.Getenv("PRICES_FILE"); path != "" {
+		if err := costTracker.Prices().LoadFile(path); err != nil {
+			log.Fatalf("Failed to load prices: %v", err)
+		}
+	}
+
+	// 后台健康探测（可选）：HEALTH_PROBE_TARGETS=gpt/gpt-4o-mini,deepseek/deepseek-chat
+	if targets := os.Getenv("HEALTH_PROBE_TARGETS"); targets != "" {
+		interval, _ := time.ParseDuration(os.Getenv("HEALTH_PROBE_INTERVAL"))
+		if err := startProber(targets, interval); err != nil {
+			log.Fatalf("Failed to start health prober: %v", err)
+		}
+	}
+
 	http.HandleFunc("/health", healthHandler)
 	http.HandleFunc("/providers", providersHandler)
-	http.HandleFunc("/chat", chatHandler)
-	http.HandleFunc("/chat/stream", chatStreamHandler)
+	// 链路追踪：从请求头提取上游链路，经中间件和适配器传递给厂商（traceparent）
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	http.Handle("/chat", telemetry.Handler("POST /chat", http.HandlerFunc(chatHandler)))
+	http.Handle("/chat/stream", telemetry.Handler("POST /chat/stream", http.HandlerFunc(chatStreamHandler)))
+	http.HandleFunc("/usage", usageHandler)
+	http.HandleFunc("/keys", keysHandler)
+	http.Handle("/metrics", promhttp.Handler())
 
 	log.Printf("AI Bridge Server starting on port %s", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
 
+// healthHandler 返回服务状态及各厂商的熔断和探测状态
+// 任一厂商不健康时整体状态为 degraded
 func healthHandler(w http.ResponseWriter, r *http.Request) {
+	providers := make(map[string]*ProviderHealth)
+	model := func(name string) *ModelHealth {
+		provider, modelName, _ := strings.Cut(name, "/")
+		p, ok := providers[provider]
+		if !ok {
+			p = &ProviderHealth{Models: make(map[string]*ModelHealth)}
+			providers[provider] = p
+		}
+		m, ok := p.Models[modelName]
+		if !ok {
+			m = &ModelHealth{Healthy: true}
+			p.Models[modelName] = m
+		}
+		return m
+	}
+
+	for _, status := range breakers.Statuses() {
+		status := status
+		m := model(status.Name)
+		m.Circuit = &status
+		m.Healthy = m.Healthy && status.State == circuit.StateClosed
+	}
+	if prober != nil {
+		for name, result := range prober.Results() {
+			result := result
+			m := model(name)
+			m.Probe = &result
+			m.Healthy = m.Healthy && result.Healthy
+		}
+	}
+
+	status := "ok"
+	for _, p := range providers {
+		unhealthy := 0
+		for _, m := range p.Models {
+			if !m.Healthy {
+				unhealthy++
+			}
+		}
+		switch {
+		case unhealthy == 0:
+			p.Status = "ok"
+		case unhealthy == len(p.Models):
+			p.Status = "down"
+		default:
+			p.Status = "degraded"
+		}
+		if p.Status != "ok" {
+			status = "degraded"
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "ok",
-		"time":   time.Now().Format(time.RFC3339),
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    status,
+		"time":      time.Now().Format(time.RFC3339),
+		"providers": providers,
 	})
 }
 
+// startProber 为 provider/model 列表创建客户端并启动后台健康探测
+func startProber(targets string, interval time.Duration) error {
+	prober = bridge.NewHealthProber(interval, 0)
+	for _, target := range strings.Split(targets, ",") {
+		target = strings.TrimSpace(target)
+		provider, model, ok := strings.Cut(target, "/")
+		if !ok {
+			return fmt.Errorf("invalid probe target %q, expected provider/model", target)
+		}
+		// 探测请求不经过中间件，直接作用于熔断器
+		client, err := createBreakerClient(&ChatRequest{Provider: provider, Model: model})
+		if err != nil {
+			return fmt.Errorf("failed to create probe client for %s: %w", target, err)
+		}
+		prober.Add(target, client)
+	}
+	prober.Start(context.Background())
+	return nil
+}
+
+// usageHandler 返回费用统计，DELETE 请求清空统计
+func usageHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		costTracker.Reset()
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(costTracker.Summary())
+}
+
+// keysHandler 返回各厂商密钥池中每个密钥的健康状态
+func keysHandler(w http.ResponseWriter, r *http.Request) {
+	keyPoolsMu.Lock()
+	health := make(map[string][]bridge.KeyHealth, len(keyPools))
+	for provider, pool := range keyPools {
+		health[provider] = pool.Health()
+	}
+	keyPoolsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(health)
+}
+
+// createClient 创建AI客户端，最外层为中间件
+func createClient(req *ChatRequest, opts ...options.Option) (types.AIBridge, error) {
+	// 重试次数记录到 Prometheus 指标
+	opts = append(opts[:len(opts):len(opts)], options.WithOnRetry(promMetrics.OnRetry))
+	client, err := createBreakerClient(req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return bridge.NewMiddlewareClient(client, middlewares...), nil
+}
+
+// createBreakerClient 创建带熔断的客户端，按 厂商/模型 共享熔断器
+func createBreakerClient(req *ChatRequest, opts ...options.Option) (*bridge.CircuitBreakerClient, error) {
+	client, err := createKeyedClient(req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	breaker := breakers.Get(req.Provider+"/"+req.Model, breakerSettings)
+	return bridge.NewCircuitBreakerClient(client, breaker), nil
+}
+
+// createKeyedClient 创建单密钥或多密钥客户端
+// API Key 优先从请求体获取，其次为环境变量中的密钥池（<provider>_API_KEYS），最后为单个密钥（<provider>_API_KEY）
+func createKeyedClient(req *ChatRequest, opts ...options.Option) (types.AIBridge, error) {
+	provider := types.Provider(req.Provider)
+	if req.APIKey != "" {
+		return bridge.NewAIClient(provider, req.Model, append(opts, options.WithAPIKey(req.APIKey))...)
+	}
+
+	pool, err := keyPoolFor(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if pool != nil {
+		pooled, err := bridge.NewPooledClient(pool, func(cred bridge.Credential) (types.AIBridge, error) {
+			// 401/403/429 不在单个密钥上重试，由密钥池立即换用其他密钥
+			keyOpts := append(opts[:len(opts):len(opts)], options.WithAPIKey(cred.APIKey), options.WithRetryIf(bridge.RetryOnSameKey))
+			return bridge.NewAIClient(provider, req.Model, keyOpts...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return pooled, nil
+	}
+
+	apiKey := os.Getenv(fmt.Sprintf("%s_API_KEY", req.Provider))
+	if apiKey == "" {
+		return nil, errAPIKeyMissing
+	}
+	return bridge.NewAIClient(provider, req.Model, append(opts, options.WithAPIKey(apiKey))...)
+}
+
+// keyPoolFor 获取或创建厂商的密钥池，未配置 <provider>_API_KEYS 时返回 nil
+// 选择策略由 KEY_STRATEGY 环境变量指定（round_robin/least_used/weighted）
+func keyPoolFor(provider string) (*bridge.KeyPool, error) {
+	keyPoolsMu.Lock()
+	defer keyPoolsMu.Unlock()
+
+	if pool, ok := keyPools[provider]; ok {
+		return pool, nil
+	}
+	creds := bridge.ParseCredentials(os.Getenv(fmt.Sprintf("%s_API_KEYS", provider)))
+	if len(creds) == 0 {
+		return nil, nil
+	}
+	pool, err := bridge.NewKeyPool(creds, bridge.KeyStrategy(os.Getenv("KEY_STRATEGY")), 0)
+	if err != nil {
+		return nil, err
+	}
+	keyPools[provider] = pool
+	return pool, nil
+}
+
 func providersHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -127,21 +396,8 @@ func chatHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 获取API Key（优先从请求体，其次从环境变量）
-	apiKey := req.APIKey
-	if apiKey == "" {
-		apiKey = os.Getenv(fmt.Sprintf("%s_API_KEY", req.Provider))
-	}
-
-	if apiKey == "" {
-		http.Error(w, "API key not provided", http.StatusUnauthorized)
-		return
-	}
-
 	// 构建选项
-	opts := []options.Option{
-		options.WithAPIKey(apiKey),
-	}
+	var opts []options.Option
 
 	// 应用额外选项
 	if temp, ok := req.Options["temperature"].(float64); ok {
@@ -155,7 +411,11 @@ func chatHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 创建客户端
-	client, err := bridge.NewAIClient(types.Provider(req.Provider), req.Model, opts...)
+	client, err := createClient(&req, opts...)
+	if errors.Is(err, errAPIKeyMissing) {
+		http.Error(w, "API key not provided", http.StatusUnauthorized)
+		return
+	}
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -187,11 +447,12 @@ func chatHandler(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	// 执行对话
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	// 执行对话（保留请求中的链路信息，不随客户端断开而取消）
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 60*time.Second)
 	defer cancel()
+	ctx = metrics.WithEndpoint(ctx, "/chat")
 
-	resp, err := client.Chat(ctx, messages)
+	resp, err := bridge.NewSDKClient(client).Chat(ctx, messages, bridge.WithStream(false))
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -203,12 +464,19 @@ func chatHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	callCost := costTracker.Record(types.Provider(req.Provider), req.Model, resp.Usage, req.Tag)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ChatResponse{
-		Content:  resp.Content,
-		Provider: req.Provider,
-		Model:    req.Model,
-		Stream:   false,
+		Content:      resp.Content,
+		Provider:     req.Provider,
+		Model:        req.Model,
+		Stream:       false,
+		FinishReason: resp.FinishReason,
+		Usage:        &resp.Usage,
+		RequestID:    resp.RequestID,
+		LatencyMs:    resp.Latency.Milliseconds(),
+		Cost:         callCost,
 	})
 }
 
@@ -230,24 +498,12 @@ func chatStreamHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 获取API Key
-	apiKey := req.APIKey
-	if apiKey == "" {
-		apiKey = os.Getenv(fmt.Sprintf("%s_API_KEY", req.Provider))
-	}
-
-	if apiKey == "" {
+	// 创建客户端
+	client, err := createClient(&req)
+	if errors.Is(err, errAPIKeyMissing) {
 		http.Error(w, "API key not provided", http.StatusUnauthorized)
 		return
 	}
-
-	// 构建选项
-	opts := []options.Option{
-		options.WithAPIKey(apiKey),
-	}
-
-	// 创建客户端
-	client, err := bridge.NewAIClient(types.Provider(req.Provider), req.Model, opts...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -278,11 +534,12 @@ func chatStreamHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
-	// 执行流式对话
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	// 执行流式对话（保留请求中的链路信息，不随客户端断开而取消）
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 60*time.Second)
 	defer cancel()
+	ctx = metrics.WithEndpoint(ctx, "/chat/stream")
 
-	stream, err := client.ChatStream(ctx, messages)
+	stream, err := bridge.NewSDKClient(client).ChatStream(ctx, messages, bridge.WithTimeout(0))
 	if err != nil {
 		fmt.Fprintf(w, "data: %s\n\n", `{"error": "`+err.Error()+`"}`)
 		return
@@ -293,15 +550,24 @@ func chatStreamHandler(w http.ResponseWriter, r *http.Request) {
 	for {
 		msg, err := stream.Recv()
 		if err != nil {
-			if err.Error() == "EOF" {
-				fmt.Fprintf(w, "data: %s\n\n", `{"done": true}`)
+			if err == io.EOF {
+				// 最后一个块携带结束原因和token使用情况
+				done := types.StreamChunk{Done: true}
+				if result := stream.Result(); result != nil {
+					done.FinishReason = result.FinishReason
+					done.Usage = &result.Usage
+					done.RequestID = result.RequestID
+					costTracker.Record(types.Provider(req.Provider), req.Model, result.Usage, req.Tag)
+				}
+				data, _ := json.Marshal(done)
+				fmt.Fprintf(w, "data: %s\n\n", string(data))
 				w.(http.Flusher).Flush()
 			}
 			break
 		}
 
-		data, _ := json.Marshal(map[string]string{
-			"content": msg.Content,
+		data, _ := json.Marshal(types.StreamChunk{
+			Content: msg.Content,
 		})
 		fmt.Fprintf(w, "data: %s\n\n", string(data))
 		w.(http.Flusher).Flush()
@@ -5,14 +5,47 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
 
 	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
 
 	"ai-bridge/pkg/options"
 	"ai-bridge/pkg/types"
 )
 
+// 统一的结束原因（与OpenAI的 finish_reason 保持一致）
+const (
+	FinishReasonStop          = "stop"
+	FinishReasonLength        = "length"
+	FinishReasonToolCalls     = "tool_calls"
+	FinishReasonContentFilter = "content_filter"
+)
+
+// ExtraKeyRequestID schema.Message.Extra 中保存厂商请求ID的键
+const ExtraKeyRequestID = "request_id"
+
+// extraKeyOpenAIRequestID eino-ext OpenAI兼容模型保存请求ID的键
+const extraKeyOpenAIRequestID = "openai-request-id"
+
+// RequestIDOf 获取响应消息中的厂商请求ID，不存在时返回空字符串
+func RequestIDOf(msg *schema.Message) string {
+	if msg == nil {
+		return ""
+	}
+	for _, key := range []string{ExtraKeyRequestID, extraKeyOpenAIRequestID} {
+		// eino-ext 使用自定义字符串类型保存，需要按底层类型读取
+		if v := reflect.ValueOf(msg.Extra[key]); v.IsValid() && v.Kind() == reflect.String && v.String() != "" {
+			return v.String()
+		}
+	}
+	return ""
+}
+
 // BaseAdapter 基础适配器
 type BaseAdapter struct {
 	Provider  types.Provider
@@ -20,6 +53,9 @@ type BaseAdapter struct {
 	Config    *types.Config
 	ModelInfo *types.ModelInfo
 	ChatModel model.ChatModel
+
+	loggerOnce sync.Once
+	log        *slog.Logger
 }
 
 // Chat 执行对话（非流式）
@@ -31,7 +67,10 @@ func (b *BaseAdapter) Chat(ctx context.Context, messages []*schema.Message) (*sc
 	// 如果有系统提示词，添加到消息列表开头
 	messages = b.prependSystemMessage(messages)
 
-	return b.ChatModel.Generate(ctx, messages)
+	start := time.Now()
+	resp, err := b.generateWithRetry(ctx, messages)
+	b.logResult(ctx, "chat", start, resp, err)
+	return resp, err
 }
 
 // ChatStream 执行对话（流式）
@@ -43,7 +82,16 @@ func (b *BaseAdapter) ChatStream(ctx context.Context, messages []*schema.Message
 	// 如果有系统提示词，添加到消息列表开头
 	messages = b.prependSystemMessage(messages)
 
-	return b.ChatModel.Stream(ctx, messages)
+	start := time.Now()
+	stream, err := b.streamWithRetry(ctx, messages)
+	if err != nil {
+		b.logResult(ctx, "chat_stream", start, nil, err)
+		return nil, err
+	}
+	if b.logger() != nil {
+		stream = b.logStream(ctx, "chat_stream", start, stream)
+	}
+	return stream, nil
 }
 
 // Generate 生成文本（简化接口）
@@ -60,7 +108,9 @@ func (b *BaseAdapter) Generate(ctx context.Context, prompt string) (string, erro
 
 	messages = append(messages, schema.UserMessage(prompt))
 
-	resp, err := b.ChatModel.Generate(ctx, messages)
+	start := time.Now()
+	resp, err := b.generateWithRetry(ctx, messages)
+	b.logResult(ctx, "generate", start, resp, err)
 	if err != nil {
 		return "", err
 	}
@@ -82,10 +132,15 @@ func (b *BaseAdapter) GenerateStream(ctx context.Context, prompt string) (string
 
 	messages = append(messages, schema.UserMessage(prompt))
 
-	stream, err := b.ChatModel.Stream(ctx, messages)
+	start := time.Now()
+	stream, err := b.streamWithRetry(ctx, messages)
 	if err != nil {
+		b.logResult(ctx, "generate_stream", start, nil, err)
 		return "", err
 	}
+	if b.logger() != nil {
+		stream = b.logStream(ctx, "generate_stream", start, stream)
+	}
 	defer stream.Close()
 
 	var result string
@@ -108,6 +163,56 @@ func (b *BaseAdapter) GetModelInfo() *types.ModelInfo {
 	return b.ModelInfo
 }
 
+// GetConfig 获取客户端配置
+func (b *BaseAdapter) GetConfig() *types.Config {
+	return b.Config
+}
+
+// bindTools 将配置中的工具（Config.Tools）绑定到ChatModel
+// 优先使用 ToolCallingChatModel.WithTools，避免修改原实例
+func (b *BaseAdapter) bindTools(ctx context.Context) error {
+	if b.Config == nil || len(b.Config.Tools) == 0 {
+		return nil
+	}
+
+	infos, err := ToolInfos(ctx, b.Config.Tools)
+	if err != nil {
+		return err
+	}
+
+	if tcm, ok := b.ChatModel.(model.ToolCallingChatModel); ok {
+		bound, err := tcm.WithTools(infos)
+		if err != nil {
+			return fmt.Errorf("failed to bind tools: %w", err)
+		}
+		if cm, ok := bound.(model.ChatModel); ok {
+			b.ChatModel = cm
+			return nil
+		}
+	}
+
+	if err := b.ChatModel.BindTools(infos); err != nil {
+		return fmt.Errorf("failed to bind tools: %w", err)
+	}
+	return nil
+}
+
+// ToolInfos 获取工具的描述信息（名称、说明、参数schema）
+func ToolInfos(ctx context.Context, tools []tool.BaseTool) ([]*schema.ToolInfo, error) {
+	infos := make([]*schema.ToolInfo, 0, len(tools))
+	for _, t := range tools {
+		if t == nil {
+			continue
+		}
+		info, err := t.Info(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tool info: %w", err)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
 // prependSystemMessage 如果有系统提示词，添加到消息列表开头
 func (b *BaseAdapter) prependSystemMessage(messages []*schema.Message) []*schema.Message {
 	if b.Config == nil || b.Config.SystemPrompt == "" {
@@ -208,8 +313,8 @@ func GetAdapter(provider types.Provider, modelName string, opts ...options.Optio
 
 	// 验证模型
 	if !types.IsValidModel(provider, modelName) {
-		// 如果是Ollama，允许任意模型名
-		if provider != types.ProviderOllama {
+		// 如果是Ollama或Mock，允许任意模型名
+		if provider != types.ProviderOllama && provider != types.ProviderMock {
 			return nil, fmt.Errorf("invalid model %s for provider %s", modelName, provider)
 		}
 	}
@@ -4,13 +4,11 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/cloudwego/eino-ext/components/model/openai"
-
 	"ai-bridge/pkg/options"
 	"ai-bridge/pkg/types"
 )
 
-// ClaudeAdapter Claude适配器（使用OpenAI兼容接口）
+// ClaudeAdapter Claude适配器（使用Anthropic原生 Messages API）
 type ClaudeAdapter struct {
 	BaseAdapter
 }
@@ -24,26 +22,25 @@ func NewClaudeAdapter(provider types.Provider, modelName string, opts ...options
 		return nil, fmt.Errorf("api key is required for Claude")
 	}
 
-	// Claude可以通过多种方式调用，这里使用OpenAI兼容接口
-	// 用户可以通过BaseURL指向Anthropic API或兼容服务
-	baseURL := cfg.BaseURL
-	if baseURL == "" {
-		// 默认使用Anthropic API
-		baseURL = "https://api.anthropic.com/v1"
+	// 创建共享HTTP客户端（代理、额外请求头、TLS、超时）
+	httpClient, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
 	}
 
-	// 创建OpenAI配置（兼容模式）
-	openaiCfg := &openai.ChatModelConfig{
+	// 用户可以通过BaseURL指向Anthropic API或兼容服务（默认 https://api.anthropic.com/v1）
+	claudeCfg := &ClaudeChatModelConfig{
 		APIKey:      cfg.APIKey,
-		BaseURL:     baseURL,
+		BaseURL:     cfg.BaseURL,
 		Model:       modelName,
-		MaxTokens:   &cfg.MaxTokens,
+		MaxTokens:   cfg.MaxTokens,
 		Temperature: &cfg.Temperature,
 		TopP:        &cfg.TopP,
+		HTTPClient:  httpClient,
 	}
 
 	// 创建ChatModel
-	chatModel, err := openai.NewChatModel(context.Background(), openaiCfg)
+	chatModel, err := NewClaudeChatModel(claudeCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create claude chat model: %w", err)
 	}
@@ -58,6 +55,11 @@ func NewClaudeAdapter(provider types.Provider, modelName string, opts ...options
 		},
 	}
 
+	// 绑定MCP工具
+	if err := adapter.bindTools(context.Background()); err != nil {
+		return nil, err
+	}
+
 	return adapter, nil
 }
 
@@ -0,0 +1,806 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// defaultClaudeBaseURL Anthropic API默认地址
+	defaultClaudeBaseURL = "https://api.anthropic.com/v1"
+
+	// defaultClaudeAPIVersion Anthropic API默认版本
+	defaultClaudeAPIVersion = "2023-06-01"
+)
+
+// ClaudeChatModelConfig Claude原生 Messages API 配置
+type ClaudeChatModelConfig struct {
+	// APIKey API密钥（通过 x-api-key 头发送）
+	APIKey string
+
+	// BaseURL API地址，默认 https://api.anthropic.com/v1
+	BaseURL string
+
+	// APIVersion anthropic-version 头，默认 2023-06-01
+	APIVersion string
+
+	// Model 模型名称
+	Model string
+
+	// MaxTokens 最大生成token数（Messages API 必填）
+	MaxTokens int
+
+	// Temperature 温度参数（可选）
+	Temperature *float32
+
+	// TopP 核采样参数（可选）
+	TopP *float32
+
+	// StopSequences 停止序列（可选）
+	StopSequences []string
+
+	// HTTPClient 自定义HTTP客户端（可选）
+	HTTPClient *http.Client
+}
+
+// ClaudeChatModel Claude原生 Messages API 聊天模型
+// 实现 eino 的 model.ChatModel 与 model.ToolCallingChatModel 接口
+type ClaudeChatModel struct {
+	config     ClaudeChatModelConfig
+	client     *http.Client
+	tools      []*schema.ToolInfo
+	toolChoice *schema.ToolChoice
+}
+
+// NewClaudeChatModel 创建Claude原生聊天模型
+func NewClaudeChatModel(config *ClaudeChatModelConfig) (*ClaudeChatModel, error) {
+	if config == nil {
+		return nil, fmt.Errorf("claude chat model config is required")
+	}
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("api key is required for Claude")
+	}
+	if config.Model == "" {
+		return nil, fmt.Errorf("model is required for Claude")
+	}
+
+	cfg := *config
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultClaudeBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = defaultClaudeAPIVersion
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = 1024
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &ClaudeChatModel{
+		config: cfg,
+		client: client,
+	}, nil
+}
+
+// Generate 非流式生成
+func (m *ClaudeChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	req, err := m.buildRequest(input, false, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.doRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out claudeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode claude response: %w", err)
+	}
+
+	return claudeResponseToMessage(&out, resp.Header.Get("request-id")), nil
+}
+
+// Stream 流式生成
+func (m *ClaudeChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	req, err := m.buildRequest(input, true, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.doRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &claudeStreamState{
+		requestID: resp.Header.Get("request-id"),
+		toolIndex: make(map[int]int),
+	}
+	reader := newSSEReader(resp.Body)
+	finished := false
+	recv := func() (*schema.Message, error) {
+		for !finished {
+			event, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read claude stream: %w", err)
+			}
+
+			msg, done, err := state.handle(event)
+			if err != nil {
+				return nil, err
+			}
+			finished = done
+			if msg != nil {
+				return msg, nil
+			}
+		}
+		return nil, io.EOF
+	}
+	return pullStream(recv, func() { resp.Body.Close() }, nil, nil), nil
+}
+
+// BindTools 绑定工具（会修改当前实例）
+func (m *ClaudeChatModel) BindTools(tools []*schema.ToolInfo) error {
+	if len(tools) == 0 {
+		return fmt.Errorf("no tools to bind")
+	}
+	m.tools = tools
+	tc := schema.ToolChoiceAllowed
+	m.toolChoice = &tc
+	return nil
+}
+
+// WithTools 返回绑定了工具的新实例
+func (m *ClaudeChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("no tools to bind")
+	}
+	ncm := *m
+	ncm.tools = tools
+	tc := schema.ToolChoiceAllowed
+	ncm.toolChoice = &tc
+	return &ncm, nil
+}
+
+// GetType 返回模型类型
+func (m *ClaudeChatModel) GetType() string {
+	return "Claude"
+}
+
+// buildRequest 构建 Messages API 请求体
+func (m *ClaudeChatModel) buildRequest(input []*schema.Message, stream bool, opts ...model.Option) (*claudeRequest, error) {
+	options := model.GetCommonOptions(&model.Options{
+		Model:       &m.config.Model,
+		MaxTokens:   &m.config.MaxTokens,
+		Temperature: m.config.Temperature,
+		TopP:        m.config.TopP,
+		Stop:        m.config.StopSequences,
+		Tools:       m.tools,
+		ToolChoice:  m.toolChoice,
+	}, opts...)
+
+	req := &claudeRequest{
+		Model:         *options.Model,
+		MaxTokens:     *options.MaxTokens,
+		Temperature:   options.Temperature,
+		TopP:          options.TopP,
+		StopSequences: options.Stop,
+		Stream:        stream,
+	}
+
+	system, messages, err := toClaudeMessages(input)
+	if err != nil {
+		return nil, err
+	}
+	req.System = system
+	req.Messages = messages
+
+	if len(options.Tools) > 0 {
+		tools, err := toClaudeTools(options.Tools)
+		if err != nil {
+			return nil, err
+		}
+		req.Tools = tools
+		req.ToolChoice = toClaudeToolChoice(options.ToolChoice, options.AllowedToolNames)
+	}
+
+	return req, nil
+}
+
+// doRequest 发送请求并检查HTTP状态码
+func (m *ClaudeChatModel) doRequest(ctx context.Context, body *claudeRequest) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal claude request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.config.BaseURL+"/messages", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create claude request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", m.config.APIKey)
+	req.Header.Set("anthropic-version", m.config.APIVersion)
+	if body.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newClaudeAPIError(resp)
+	}
+
+	return resp, nil
+}
+
+// newClaudeAPIError 从错误响应构建APIError
+func newClaudeAPIError(resp *http.Response) error {
+	apiErr := &APIError{
+		Provider:   "claude",
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("request-id"),
+		RetryAfter: parseRetryAfter(resp.Header),
+	}
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var body claudeErrorBody
+	if err := json.Unmarshal(raw, &body); err == nil && body.Error.Message != "" {
+		apiErr.Type = body.Error.Type
+		apiErr.Message = body.Error.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(raw))
+	}
+
+	return apiErr
+}
+
+// toClaudeMessages 将eino消息转换为Claude消息
+// 系统消息合并为 system 字段，tool 消息转换为 user 角色的 tool_result 块
+func toClaudeMessages(input []*schema.Message) (string, []claudeMessage, error) {
+	var systemParts []string
+	var messages []claudeMessage
+
+	appendBlocks := func(role string, blocks []claudeContentBlock) {
+		if len(blocks) == 0 {
+			return
+		}
+		// Claude要求user/assistant交替出现，连续同角色消息需要合并
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			messages[n-1].Content = append(messages[n-1].Content, blocks...)
+			return
+		}
+		messages = append(messages, claudeMessage{Role: role, Content: blocks})
+	}
+
+	for _, msg := range input {
+		if msg == nil {
+			continue
+		}
+
+		switch msg.Role {
+		case schema.System:
+			if msg.Content != "" {
+				systemParts = append(systemParts, msg.Content)
+			}
+
+		case schema.User:
+			blocks, err := toClaudeUserBlocks(msg)
+			if err != nil {
+				return "", nil, err
+			}
+			appendBlocks("user", blocks)
+
+		case schema.Assistant:
+			var blocks []claudeContentBlock
+			if msg.Content != "" {
+				blocks = append(blocks, claudeContentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				args := strings.TrimSpace(tc.Function.Arguments)
+				if args == "" {
+					args = "{}"
+				}
+				if !json.Valid([]byte(args)) {
+					return "", nil, fmt.Errorf("invalid arguments for tool call %s: %s", tc.Function.Name, args)
+				}
+				blocks = append(blocks, claudeContentBlock{
+					Type:  "tool_use",
+					ID:    tc.ID,
+					Name:  tc.Function.Name,
+					Input: json.RawMessage(args),
+				})
+			}
+			appendBlocks("assistant", blocks)
+
+		case schema.Tool:
+			appendBlocks("user", []claudeContentBlock{{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+			}})
+
+		default:
+			return "", nil, fmt.Errorf("unsupported message role for Claude: %s", msg.Role)
+		}
+	}
+
+	return strings.Join(systemParts, "\n\n"), messages, nil
+}
+
+// toClaudeUserBlocks 转换用户消息内容（支持文本和图片）
+func toClaudeUserBlocks(msg *schema.Message) ([]claudeContentBlock, error) {
+	var blocks []claudeContentBlock
+
+	if len(msg.UserInputMultiContent) > 0 {
+		for _, part := range msg.UserInputMultiContent {
+			switch part.Type {
+			case schema.ChatMessagePartTypeText:
+				if part.Text != "" {
+					blocks = append(blocks, claudeContentBlock{Type: "text", Text: part.Text})
+				}
+			case schema.ChatMessagePartTypeImageURL:
+				if part.Image == nil {
+					continue
+				}
+				source, err := toClaudeImageSource(part.Image.URL, part.Image.Base64Data, part.Image.MIMEType)
+				if err != nil {
+					return nil, err
+				}
+				blocks = append(blocks, claudeContentBlock{Type: "image", Source: source})
+			default:
+				return nil, fmt.Errorf("unsupported content part type for Claude: %s", part.Type)
+			}
+		}
+		return blocks, nil
+	}
+
+	if len(msg.MultiContent) > 0 {
+		for _, part := range msg.MultiContent {
+			switch part.Type {
+			case schema.ChatMessagePartTypeText:
+				if part.Text != "" {
+					blocks = append(blocks, claudeContentBlock{Type: "text", Text: part.Text})
+				}
+			case schema.ChatMessagePartTypeImageURL:
+				if part.ImageURL == nil {
+					continue
+				}
+				url := part.ImageURL.URL
+				source, err := toClaudeImageSource(&url, nil, part.ImageURL.MIMEType)
+				if err != nil {
+					return nil, err
+				}
+				blocks = append(blocks, claudeContentBlock{Type: "image", Source: source})
+			default:
+				return nil, fmt.Errorf("unsupported content part type for Claude: %s", part.Type)
+			}
+		}
+		return blocks, nil
+	}
+
+	if msg.Content != "" {
+		blocks = append(blocks, claudeContentBlock{Type: "text", Text: msg.Content})
+	}
+	return blocks, nil
+}
+
+// toClaudeImageSource 转换图片来源（base64或URL，支持 data: URL）
+func toClaudeImageSource(url, base64Data *string, mimeType string) (*claudeImageSource, error) {
+	if base64Data != nil && *base64Data != "" {
+		if mimeType == "" {
+			return nil, fmt.Errorf("mime type is required for base64 image")
+		}
+		return &claudeImageSource{Type: "base64", MediaType: mimeType, Data: *base64Data}, nil
+	}
+	if url == nil || *url == "" {
+		return nil, fmt.Errorf("image url or base64 data is required")
+	}
+	if mediaType, data, ok := parseDataURL(*url); ok {
+		return &claudeImageSource{Type: "base64", MediaType: mediaType, Data: data}, nil
+	}
+	return &claudeImageSource{Type: "url", URL: *url}, nil
+}
+
+// parseDataURL 解析 data:[<mediatype>];base64,<data> 格式
+func parseDataURL(url string) (mediaType, data string, ok bool) {
+	if !strings.HasPrefix(url, "data:") {
+		return "", "", false
+	}
+	meta, payload, found := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+	if !found || !strings.HasSuffix(meta, ";base64") {
+		return "", "", false
+	}
+	if _, err := base64.StdEncoding.DecodeString(payload); err != nil {
+		return "", "", false
+	}
+	return strings.TrimSuffix(meta, ";base64"), payload, true
+}
+
+// toClaudeTools 转换工具定义
+func toClaudeTools(tools []*schema.ToolInfo) ([]claudeTool, error) {
+	result := make([]claudeTool, 0, len(tools))
+	for _, t := range tools {
+		if t == nil {
+			return nil, fmt.Errorf("tool info cannot be nil")
+		}
+		params, err := toolParametersJSON(t)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, claudeTool{
+			Name:        t.Name,
+			Description: t.Desc,
+			InputSchema: params,
+		})
+	}
+	return result, nil
+}
+
+// toClaudeToolChoice 转换工具选择策略
+func toClaudeToolChoice(tc *schema.ToolChoice, allowedToolNames []string) *claudeToolChoice {
+	if tc == nil {
+		return nil
+	}
+	switch *tc {
+	case schema.ToolChoiceForbidden:
+		return &claudeToolChoice{Type: "none"}
+	case schema.ToolChoiceForced:
+		if len(allowedToolNames) == 1 {
+			return &claudeToolChoice{Type: "tool", Name: allowedToolNames[0]}
+		}
+		return &claudeToolChoice{Type: "any"}
+	default:
+		return &claudeToolChoice{Type: "auto"}
+	}
+}
+
+// toolParametersJSON 将工具参数转换为JSON Schema原始数据
+func toolParametersJSON(t *schema.ToolInfo) (json.RawMessage, error) {
+	empty := json.RawMessage(`{"type":"object","properties":{}}`)
+	if t.ParamsOneOf == nil {
+		return empty, nil
+	}
+	js, err := t.ParamsOneOf.ToJSONSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert parameters of tool %s: %w", t.Name, err)
+	}
+	if js == nil {
+		return empty, nil
+	}
+	data, err := json.Marshal(js)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters of tool %s: %w", t.Name, err)
+	}
+	return data, nil
+}
+
+// claudeStopReason 将Claude的 stop_reason 映射为统一的结束原因
+func claudeStopReason(reason string) string {
+	switch reason {
+	case "end_turn", "stop_sequence", "pause_turn":
+		return FinishReasonStop
+	case "max_tokens":
+		return FinishReasonLength
+	case "tool_use":
+		return FinishReasonToolCalls
+	case "refusal":
+		return FinishReasonContentFilter
+	default:
+		return reason
+	}
+}
+
+// claudeResponseToMessage 转换非流式响应
+func claudeResponseToMessage(resp *claudeResponse, requestID string) *schema.Message {
+	msg := &schema.Message{Role: schema.Assistant}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "thinking":
+			msg.ReasoningContent += block.Thinking
+		case "tool_use":
+			args := string(block.Input)
+			if args == "" {
+				args = "{}"
+			}
+			msg.ToolCalls = append(msg.ToolCalls, schema.ToolCall{
+				ID:   block.ID,
+				Type: "function",
+				Function: schema.FunctionCall{
+					Name:      block.Name,
+					Arguments: args,
+				},
+			})
+		}
+	}
+	msg.Content = text.String()
+
+	msg.ResponseMeta = &schema.ResponseMeta{
+		FinishReason: claudeStopReason(resp.StopReason),
+		Usage:        resp.Usage.toTokenUsage(),
+	}
+
+	if requestID == "" {
+		requestID = resp.ID
+	}
+	if requestID != "" {
+		msg.Extra = map[string]any{ExtraKeyRequestID: requestID}
+	}
+
+	return msg
+}
+
+// claudeStreamState 流式事件处理状态
+type claudeStreamState struct {
+	requestID  string
+	messageID  string
+	usage      claudeUsage
+	toolIndex  map[int]int // content block index -> tool call index
+	nextToolID int
+}
+
+// handle 处理单个SSE事件，返回需要发送的消息块
+func (s *claudeStreamState) handle(event *sseEvent) (*schema.Message, bool, error) {
+	var payload claudeStreamEvent
+	if event.Data != "" {
+		if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+			return nil, false, fmt.Errorf("failed to decode claude stream event: %w", err)
+		}
+	}
+
+	eventType := payload.Type
+	if eventType == "" {
+		eventType = event.Event
+	}
+
+	switch eventType {
+	case "message_start":
+		if payload.Message != nil {
+			s.messageID = payload.Message.ID
+			s.usage = payload.Message.Usage
+		}
+		return nil, false, nil
+
+	case "content_block_start":
+		if payload.ContentBlock == nil || payload.ContentBlock.Type != "tool_use" {
+			if payload.ContentBlock != nil && payload.ContentBlock.Text != "" {
+				return &schema.Message{Role: schema.Assistant, Content: payload.ContentBlock.Text}, false, nil
+			}
+			return nil, false, nil
+		}
+		idx := s.nextToolID
+		s.nextToolID++
+		s.toolIndex[payload.Index] = idx
+		return &schema.Message{
+			Role: schema.Assistant,
+			ToolCalls: []schema.ToolCall{{
+				Index: &idx,
+				ID:    payload.ContentBlock.ID,
+				Type:  "function",
+				Function: schema.FunctionCall{
+					Name: payload.ContentBlock.Name,
+				},
+			}},
+		}, false, nil
+
+	case "content_block_delta":
+		if payload.Delta == nil {
+			return nil, false, nil
+		}
+		switch payload.Delta.Type {
+		case "text_delta":
+			return &schema.Message{Role: schema.Assistant, Content: payload.Delta.Text}, false, nil
+		case "thinking_delta":
+			return &schema.Message{Role: schema.Assistant, ReasoningContent: payload.Delta.Thinking}, false, nil
+		case "input_json_delta":
+			idx, ok := s.toolIndex[payload.Index]
+			if !ok || payload.Delta.PartialJSON == "" {
+				return nil, false, nil
+			}
+			return &schema.Message{
+				Role: schema.Assistant,
+				ToolCalls: []schema.ToolCall{{
+					Index: &idx,
+					Function: schema.FunctionCall{
+						Arguments: payload.Delta.PartialJSON,
+					},
+				}},
+			}, false, nil
+		}
+		return nil, false, nil
+
+	case "message_delta":
+		if payload.Usage != nil {
+			s.usage.OutputTokens = payload.Usage.OutputTokens
+		}
+		var stopReason string
+		if payload.Delta != nil {
+			stopReason = payload.Delta.StopReason
+		}
+		msg := &schema.Message{
+			Role: schema.Assistant,
+			ResponseMeta: &schema.ResponseMeta{
+				FinishReason: claudeStopReason(stopReason),
+				Usage:        s.usage.toTokenUsage(),
+			},
+		}
+		requestID := s.requestID
+		if requestID == "" {
+			requestID = s.messageID
+		}
+		if requestID != "" {
+			msg.Extra = map[string]any{ExtraKeyRequestID: requestID}
+		}
+		return msg, false, nil
+
+	case "message_stop":
+		return nil, true, nil
+
+	case "error":
+		apiErr := &APIError{Provider: "claude", RequestID: s.requestID}
+		if payload.Error != nil {
+			apiErr.Type = payload.Error.Type
+			apiErr.Message = payload.Error.Message
+		}
+		if apiErr.Type == "overloaded_error" {
+			apiErr.StatusCode = 529
+		}
+		return nil, false, apiErr
+
+	default:
+		// ping 及未知事件直接忽略
+		return nil, false, nil
+	}
+}
+
+// claudeRequest Messages API 请求体
+type claudeRequest struct {
+	Model         string            `json:"model"`
+	System        string            `json:"system,omitempty"`
+	Messages      []claudeMessage   `json:"messages"`
+	MaxTokens     int               `json:"max_tokens"`
+	Temperature   *float32          `json:"temperature,omitempty"`
+	TopP          *float32          `json:"top_p,omitempty"`
+	StopSequences []string          `json:"stop_sequences,omitempty"`
+	Stream        bool              `json:"stream,omitempty"`
+	Tools         []claudeTool      `json:"tools,omitempty"`
+	ToolChoice    *claudeToolChoice `json:"tool_choice,omitempty"`
+}
+
+// claudeMessage Messages API 消息
+type claudeMessage struct {
+	Role    string               `json:"role"`
+	Content []claudeContentBlock `json:"content"`
+}
+
+// claudeContentBlock Messages API 内容块
+type claudeContentBlock struct {
+	Type      string             `json:"type"`
+	Text      string             `json:"text,omitempty"`
+	Thinking  string             `json:"thinking,omitempty"`
+	Source    *claudeImageSource `json:"source,omitempty"`
+	ID        string             `json:"id,omitempty"`
+	Name      string             `json:"name,omitempty"`
+	Input     json.RawMessage    `json:"input,omitempty"`
+	ToolUseID string             `json:"tool_use_id,omitempty"`
+	Content   string             `json:"content,omitempty"`
+	IsError   bool               `json:"is_error,omitempty"`
+}
+
+// claudeImageSource 图片来源
+type claudeImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// claudeTool 工具定义
+type claudeTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// claudeToolChoice 工具选择策略
+type claudeToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// claudeResponse Messages API 响应体
+type claudeResponse struct {
+	ID         string               `json:"id"`
+	Type       string               `json:"type"`
+	Role       string               `json:"role"`
+	Model      string               `json:"model"`
+	Content    []claudeContentBlock `json:"content"`
+	StopReason string               `json:"stop_reason"`
+	Usage      claudeUsage          `json:"usage"`
+}
+
+// claudeUsage token使用情况
+type claudeUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// toTokenUsage 转换为eino的TokenUsage
+func (u claudeUsage) toTokenUsage() *schema.TokenUsage {
+	prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return &schema.TokenUsage{
+		PromptTokens: prompt,
+		PromptTokenDetails: schema.PromptTokenDetails{
+			CachedTokens: u.CacheReadInputTokens,
+		},
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      prompt + u.OutputTokens,
+	}
+}
+
+// claudeStreamEvent 流式事件
+type claudeStreamEvent struct {
+	Type         string              `json:"type"`
+	Index        int                 `json:"index"`
+	Message      *claudeResponse     `json:"message,omitempty"`
+	ContentBlock *claudeContentBlock `json:"content_block,omitempty"`
+	Delta        *claudeStreamDelta  `json:"delta,omitempty"`
+	Usage        *claudeUsage        `json:"usage,omitempty"`
+	Error        *claudeErrorDetail  `json:"error,omitempty"`
+}
+
+// claudeStreamDelta 流式增量
+type claudeStreamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+}
+
+// claudeErrorBody 错误响应体
+type claudeErrorBody struct {
+	Type  string            `json:"type"`
+	Error claudeErrorDetail `json:"error"`
+}
+
+// claudeErrorDetail 错误详情
+type claudeErrorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+var (
+	_ model.ChatModel            = (*ClaudeChatModel)(nil)
+	_ model.ToolCallingChatModel = (*ClaudeChatModel)(nil)
+)
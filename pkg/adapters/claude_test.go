@@ -0,0 +1,291 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+// newClaudeTestServer 创建模拟 Messages API 的测试服务
+func newClaudeTestServer(t *testing.T, handler func(w http.ResponseWriter, req map[string]interface{})) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("x-api-key = %q, want test-key", got)
+		}
+		if got := r.Header.Get("anthropic-version"); got != defaultClaudeAPIVersion {
+			t.Errorf("anthropic-version = %q, want %s", got, defaultClaudeAPIVersion)
+		}
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		handler(w, req)
+	}))
+}
+
+func TestClaudeAdapter_Chat(t *testing.T) {
+	server := newClaudeTestServer(t, func(w http.ResponseWriter, req map[string]interface{}) {
+		if req["system"] != "你是助手" {
+			t.Errorf("system = %v, want 你是助手", req["system"])
+		}
+		if req["max_tokens"].(float64) != 256 {
+			t.Errorf("max_tokens = %v, want 256", req["max_tokens"])
+		}
+
+		// user / assistant(tool_use) / user(tool_result)
+		messages := req["messages"].([]interface{})
+		if len(messages) != 3 {
+			t.Errorf("expected 3 messages, got %d", len(messages))
+			return
+		}
+		assistant := messages[1].(map[string]interface{})
+		toolUse := assistant["content"].([]interface{})[0].(map[string]interface{})
+		if toolUse["type"] != "tool_use" || toolUse["id"] != "toolu_1" {
+			t.Errorf("unexpected tool_use block: %v", toolUse)
+		}
+		toolResult := messages[2].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})
+		if toolResult["type"] != "tool_result" || toolResult["tool_use_id"] != "toolu_1" {
+			t.Errorf("unexpected tool_result block: %v", toolResult)
+		}
+
+		w.Header().Set("request-id", "req_123")
+		fmt.Fprint(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant",
+			"content": [{"type": "text", "text": "北京晴"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 20, "output_tokens": 5}
+		}`)
+	})
+	defer server.Close()
+
+	adapter, err := NewClaudeAdapter(types.ProviderClaude, "claude-3-haiku-20240307",
+		options.WithAPIKey("test-key"),
+		options.WithBaseURL(server.URL+"/v1"),
+		options.WithMaxTokens(256),
+	)
+	if err != nil {
+		t.Fatalf("NewClaudeAdapter() error: %v", err)
+	}
+
+	resp, err := adapter.Chat(context.Background(), []*schema.Message{
+		schema.SystemMessage("你是助手"),
+		schema.UserMessage("北京天气"),
+		schema.AssistantMessage("", []schema.ToolCall{{
+			ID:       "toolu_1",
+			Function: schema.FunctionCall{Name: "weather", Arguments: `{"city":"北京"}`},
+		}}),
+		schema.ToolMessage("晴，25°C", "toolu_1"),
+	})
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+
+	if resp.Content != "北京晴" {
+		t.Errorf("Content = %q, want 北京晴", resp.Content)
+	}
+	if resp.ResponseMeta.FinishReason != FinishReasonStop {
+		t.Errorf("FinishReason = %q, want %q", resp.ResponseMeta.FinishReason, FinishReasonStop)
+	}
+	if resp.ResponseMeta.Usage.TotalTokens != 25 {
+		t.Errorf("TotalTokens = %d, want 25", resp.ResponseMeta.Usage.TotalTokens)
+	}
+	if resp.Extra[ExtraKeyRequestID] != "req_123" {
+		t.Errorf("request id = %v, want req_123", resp.Extra[ExtraKeyRequestID])
+	}
+}
+
+func TestClaudeChatModel_ToolUse(t *testing.T) {
+	server := newClaudeTestServer(t, func(w http.ResponseWriter, req map[string]interface{}) {
+		tools, ok := req["tools"].([]interface{})
+		if !ok || len(tools) != 1 {
+			t.Errorf("expected 1 tool, got %v", req["tools"])
+			return
+		}
+		tool := tools[0].(map[string]interface{})
+		if tool["name"] != "weather" {
+			t.Errorf("tool name = %v, want weather", tool["name"])
+		}
+		if _, ok := tool["input_schema"].(map[string]interface{}); !ok {
+			t.Errorf("input_schema missing: %v", tool)
+		}
+		if choice := req["tool_choice"].(map[string]interface{}); choice["type"] != "auto" {
+			t.Errorf("tool_choice = %v, want auto", choice)
+		}
+
+		fmt.Fprint(w, `{
+			"id": "msg_2", "type": "message", "role": "assistant",
+			"content": [
+				{"type": "text", "text": "查询中"},
+				{"type": "tool_use", "id": "toolu_9", "name": "weather", "input": {"city": "上海"}}
+			],
+			"stop_reason": "tool_use",
+			"usage": {"input_tokens": 10, "output_tokens": 8}
+		}`)
+	})
+	defer server.Close()
+
+	cm, err := NewClaudeChatModel(&ClaudeChatModelConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL + "/v1",
+		Model:   "claude-3-haiku-20240307",
+	})
+	if err != nil {
+		t.Fatalf("NewClaudeChatModel() error: %v", err)
+	}
+
+	withTools, err := cm.WithTools([]*schema.ToolInfo{{
+		Name: "weather",
+		Desc: "查询天气",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"city": {Type: schema.String, Desc: "城市", Required: true},
+		}),
+	}})
+	if err != nil {
+		t.Fatalf("WithTools() error: %v", err)
+	}
+
+	resp, err := withTools.Generate(context.Background(), []*schema.Message{schema.UserMessage("上海天气")})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(resp.ToolCalls))
+	}
+	call := resp.ToolCalls[0]
+	if call.ID != "toolu_9" || call.Function.Name != "weather" || call.Function.Arguments != `{"city": "上海"}` {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+	if resp.ResponseMeta.FinishReason != FinishReasonToolCalls {
+		t.Errorf("FinishReason = %q, want %q", resp.ResponseMeta.FinishReason, FinishReasonToolCalls)
+	}
+}
+
+func TestClaudeChatModel_Stream(t *testing.T) {
+	events := []string{
+		`event: message_start
+data: {"type":"message_start","message":{"id":"msg_3","type":"message","role":"assistant","content":[],"usage":{"input_tokens":12,"output_tokens":1}}}`,
+		`event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`event: ping
+data: {"type":"ping"}`,
+		`event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"你好"}}`,
+		`event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"，世界"}}`,
+		`event: content_block_stop
+data: {"type":"content_block_stop","index":0}`,
+		`event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_5","name":"weather","input":{}}}`,
+		`event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+		`event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"北京\"}"}}`,
+		`event: content_block_stop
+data: {"type":"content_block_stop","index":1}`,
+		`event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"max_tokens"},"usage":{"output_tokens":30}}`,
+		`event: message_stop
+data: {"type":"message_stop"}`,
+	}
+
+	server := newClaudeTestServer(t, func(w http.ResponseWriter, req map[string]interface{}) {
+		if req["stream"] != true {
+			t.Errorf("stream = %v, want true", req["stream"])
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, e := range events {
+			fmt.Fprintf(w, "%s\n\n", e)
+		}
+	})
+	defer server.Close()
+
+	cm, err := NewClaudeChatModel(&ClaudeChatModelConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL + "/v1",
+		Model:   "claude-3-haiku-20240307",
+	})
+	if err != nil {
+		t.Fatalf("NewClaudeChatModel() error: %v", err)
+	}
+
+	stream, err := cm.Stream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("Stream() error: %v", err)
+	}
+	defer stream.Close()
+
+	var chunks []*schema.Message
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		chunks = append(chunks, msg)
+	}
+
+	full, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		t.Fatalf("ConcatMessages() error: %v", err)
+	}
+	if full.Content != "你好，世界" {
+		t.Errorf("Content = %q, want 你好，世界", full.Content)
+	}
+	if len(full.ToolCalls) != 1 || full.ToolCalls[0].Function.Arguments != `{"city":"北京"}` {
+		t.Errorf("unexpected tool calls: %+v", full.ToolCalls)
+	}
+	if full.ResponseMeta.FinishReason != FinishReasonLength {
+		t.Errorf("FinishReason = %q, want %q", full.ResponseMeta.FinishReason, FinishReasonLength)
+	}
+	if full.ResponseMeta.Usage.PromptTokens != 12 || full.ResponseMeta.Usage.CompletionTokens != 30 {
+		t.Errorf("unexpected usage: %+v", full.ResponseMeta.Usage)
+	}
+}
+
+func TestClaudeChatModel_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`)
+	}))
+	defer server.Close()
+
+	cm, err := NewClaudeChatModel(&ClaudeChatModelConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+		Model:   "claude-3-haiku-20240307",
+	})
+	if err != nil {
+		t.Fatalf("NewClaudeChatModel() error: %v", err)
+	}
+
+	_, err = cm.Generate(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Type != "rate_limit_error" {
+		t.Errorf("unexpected api error: %+v", apiErr)
+	}
+	if apiErr.RetryAfter.Seconds() != 3 {
+		t.Errorf("RetryAfter = %v, want 3s", apiErr.RetryAfter)
+	}
+}
@@ -0,0 +1,57 @@
+package adapters
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError 厂商接口返回的错误
+type APIError struct {
+	// Provider 厂商名称
+	Provider string
+
+	// StatusCode HTTP状态码
+	StatusCode int
+
+	// Type 厂商定义的错误类型
+	Type string
+
+	// Message 错误信息
+	Message string
+
+	// RequestID 厂商返回的请求ID（可选）
+	RequestID string
+
+	// RetryAfter 厂商建议的重试等待时间（来自 Retry-After 头）
+	RetryAfter time.Duration
+}
+
+// Error 实现error接口
+func (e *APIError) Error() string {
+	if e.Type != "" {
+		return fmt.Sprintf("%s api error, status code: %d, type: %s, message: %s", e.Provider, e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("%s api error, status code: %d, message: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// parseRetryAfter 解析 Retry-After 头（支持秒数和HTTP日期两种格式）
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -4,14 +4,11 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/cloudwego/eino-ext/components/model/openai"
-
 	"ai-bridge/pkg/options"
 	"ai-bridge/pkg/types"
 )
 
-// GeminiAdapter Google Gemini适配器
-// 注意：Gemini原生需要使用Google genai客户端，这里使用OpenAI兼容接口简化
+// GeminiAdapter Google Gemini适配器（使用原生 generateContent API）
 type GeminiAdapter struct {
 	BaseAdapter
 }
@@ -25,24 +22,26 @@ func NewGeminiAdapter(provider types.Provider, modelName string, opts ...options
 		return nil, fmt.Errorf("api key is required for Gemini")
 	}
 
-	// 使用OpenAI兼容接口
-	baseURL := cfg.BaseURL
-	if baseURL == "" {
-		baseURL = "https://generativelanguage.googleapis.com/v1beta"
+	// 创建共享HTTP客户端（代理、额外请求头、TLS、超时）
+	httpClient, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
 	}
 
-	// 创建OpenAI配置（兼容模式）
-	openaiCfg := &openai.ChatModelConfig{
-		APIKey:      cfg.APIKey,
-		BaseURL:     baseURL,
-		Model:       modelName,
-		MaxTokens:   &cfg.MaxTokens,
-		Temperature: &cfg.Temperature,
-		TopP:        &cfg.TopP,
+	// 创建Gemini原生配置（默认 https://generativelanguage.googleapis.com/v1beta）
+	geminiCfg := &GeminiChatModelConfig{
+		APIKey:         cfg.APIKey,
+		BaseURL:        cfg.BaseURL,
+		Model:          modelName,
+		MaxTokens:      &cfg.MaxTokens,
+		Temperature:    &cfg.Temperature,
+		TopP:           &cfg.TopP,
+		SafetySettings: cfg.SafetySettings,
+		HTTPClient:     httpClient,
 	}
 
 	// 创建ChatModel
-	chatModel, err := openai.NewChatModel(context.Background(), openaiCfg)
+	chatModel, err := NewGeminiChatModel(geminiCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gemini chat model: %w", err)
 	}
@@ -57,6 +56,11 @@ func NewGeminiAdapter(provider types.Provider, modelName string, opts ...options
 		},
 	}
 
+	// 绑定MCP工具
+	if err := adapter.bindTools(context.Background()); err != nil {
+		return nil, err
+	}
+
 	return adapter, nil
 }
 
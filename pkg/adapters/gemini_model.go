@@ -0,0 +1,773 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/types"
+)
+
+// defaultGeminiBaseURL Gemini API默认地址
+const defaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// GeminiChatModelConfig Gemini原生 generateContent API 配置
+type GeminiChatModelConfig struct {
+	// APIKey API密钥（通过 x-goog-api-key 头发送）
+	APIKey string
+
+	// BaseURL API地址，默认 https://generativelanguage.googleapis.com/v1beta
+	BaseURL string
+
+	// Model 模型名称
+	Model string
+
+	// MaxTokens 最大生成token数（maxOutputTokens）
+	MaxTokens *int
+
+	// Temperature 温度参数（可选）
+	Temperature *float32
+
+	// TopP 核采样参数（可选）
+	TopP *float32
+
+	// StopSequences 停止序列（可选）
+	StopSequences []string
+
+	// SafetySettings 安全过滤设置（可选）
+	SafetySettings []types.SafetySetting
+
+	// HTTPClient 自定义HTTP客户端（可选）
+	HTTPClient *http.Client
+}
+
+// GeminiChatModel Gemini原生 generateContent API 聊天模型
+// 实现 eino 的 model.ChatModel 与 model.ToolCallingChatModel 接口
+type GeminiChatModel struct {
+	config     GeminiChatModelConfig
+	client     *http.Client
+	tools      []*schema.ToolInfo
+	toolChoice *schema.ToolChoice
+}
+
+// NewGeminiChatModel 创建Gemini原生聊天模型
+func NewGeminiChatModel(config *GeminiChatModelConfig) (*GeminiChatModel, error) {
+	if config == nil {
+		return nil, fmt.Errorf("gemini chat model config is required")
+	}
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("api key is required for Gemini")
+	}
+	if config.Model == "" {
+		return nil, fmt.Errorf("model is required for Gemini")
+	}
+
+	cfg := *config
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultGeminiBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &GeminiChatModel{
+		config: cfg,
+		client: client,
+	}, nil
+}
+
+// Generate 非流式生成
+func (m *GeminiChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	modelName, req, err := m.buildRequest(input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.doRequest(ctx, modelName, "generateContent", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out geminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode gemini response: %w", err)
+	}
+
+	state := &geminiStreamState{}
+	return state.toMessage(&out), nil
+}
+
+// Stream 流式生成（streamGenerateContent + SSE）
+func (m *GeminiChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	modelName, req, err := m.buildRequest(input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.doRequest(ctx, modelName, "streamGenerateContent", req)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &geminiStreamState{}
+	reader := newSSEReader(resp.Body)
+	recv := func() (*schema.Message, error) {
+		for {
+			event, err := reader.Next()
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read gemini stream: %w", err)
+			}
+			if event.Data == "" {
+				continue
+			}
+
+			var chunk geminiResponse
+			if err := json.Unmarshal([]byte(event.Data), &chunk); err != nil {
+				return nil, fmt.Errorf("failed to decode gemini stream chunk: %w", err)
+			}
+			if chunk.Error != nil {
+				return nil, chunk.Error.toAPIError(0)
+			}
+			return state.toMessage(&chunk), nil
+		}
+	}
+	return pullStream(recv, func() { resp.Body.Close() }, nil, nil), nil
+}
+
+// BindTools 绑定工具（会修改当前实例）
+func (m *GeminiChatModel) BindTools(tools []*schema.ToolInfo) error {
+	if len(tools) == 0 {
+		return fmt.Errorf("no tools to bind")
+	}
+	m.tools = tools
+	tc := schema.ToolChoiceAllowed
+	m.toolChoice = &tc
+	return nil
+}
+
+// WithTools 返回绑定了工具的新实例
+func (m *GeminiChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("no tools to bind")
+	}
+	ncm := *m
+	ncm.tools = tools
+	tc := schema.ToolChoiceAllowed
+	ncm.toolChoice = &tc
+	return &ncm, nil
+}
+
+// GetType 返回模型类型
+func (m *GeminiChatModel) GetType() string {
+	return "Gemini"
+}
+
+// buildRequest 构建 generateContent 请求体
+func (m *GeminiChatModel) buildRequest(input []*schema.Message, opts ...model.Option) (string, *geminiRequest, error) {
+	options := model.GetCommonOptions(&model.Options{
+		Model:       &m.config.Model,
+		MaxTokens:   m.config.MaxTokens,
+		Temperature: m.config.Temperature,
+		TopP:        m.config.TopP,
+		Stop:        m.config.StopSequences,
+		Tools:       m.tools,
+		ToolChoice:  m.toolChoice,
+	}, opts...)
+
+	system, contents, err := toGeminiContents(input)
+	if err != nil {
+		return "", nil, err
+	}
+
+	req := &geminiRequest{
+		Contents:          contents,
+		SystemInstruction: system,
+		GenerationConfig: &geminiGenerationConfig{
+			Temperature:     options.Temperature,
+			TopP:            options.TopP,
+			MaxOutputTokens: options.MaxTokens,
+			StopSequences:   options.Stop,
+		},
+	}
+
+	for _, s := range m.config.SafetySettings {
+		req.SafetySettings = append(req.SafetySettings, geminiSafetySetting{
+			Category:  s.Category,
+			Threshold: s.Threshold,
+		})
+	}
+
+	if len(options.Tools) > 0 {
+		decls, err := toGeminiFunctionDeclarations(options.Tools)
+		if err != nil {
+			return "", nil, err
+		}
+		req.Tools = []geminiTool{{FunctionDeclarations: decls}}
+		req.ToolConfig = toGeminiToolConfig(options.ToolChoice, options.AllowedToolNames)
+	}
+
+	return *options.Model, req, nil
+}
+
+// doRequest 发送请求并检查HTTP状态码
+func (m *GeminiChatModel) doRequest(ctx context.Context, modelName, method string, body *geminiRequest) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal gemini request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:%s", m.config.BaseURL, url.PathEscape(strings.TrimPrefix(modelName, "models/")), method)
+	if method == "streamGenerateContent" {
+		endpoint += "?alt=sse"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gemini request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", m.config.APIKey)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newGeminiAPIError(resp)
+	}
+
+	return resp, nil
+}
+
+// newGeminiAPIError 从错误响应构建APIError
+func newGeminiAPIError(resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var body struct {
+		Error *geminiError `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &body); err == nil && body.Error != nil {
+		apiErr := body.Error.toAPIError(resp.StatusCode)
+		apiErr.RetryAfter = parseRetryAfter(resp.Header)
+		return apiErr
+	}
+
+	return &APIError{
+		Provider:   "gemini",
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(raw)),
+		RetryAfter: parseRetryAfter(resp.Header),
+	}
+}
+
+// toGeminiContents 将eino消息转换为Gemini contents
+// 系统消息合并为 systemInstruction，tool 消息转换为 functionResponse
+func toGeminiContents(input []*schema.Message) (*geminiContent, []geminiContent, error) {
+	var systemParts []geminiPart
+	var contents []geminiContent
+
+	// 记录工具调用ID对应的函数名，用于还原 functionResponse 的 name
+	toolNames := make(map[string]string)
+
+	appendParts := func(role string, parts []geminiPart) {
+		if len(parts) == 0 {
+			return
+		}
+		// 连续同角色消息合并（多个 functionResponse 需要放在同一个 content 中）
+		if n := len(contents); n > 0 && contents[n-1].Role == role {
+			contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+			return
+		}
+		contents = append(contents, geminiContent{Role: role, Parts: parts})
+	}
+
+	for _, msg := range input {
+		if msg == nil {
+			continue
+		}
+
+		switch msg.Role {
+		case schema.System:
+			if msg.Content != "" {
+				systemParts = append(systemParts, geminiPart{Text: msg.Content})
+			}
+
+		case schema.User:
+			parts, err := toGeminiUserParts(msg)
+			if err != nil {
+				return nil, nil, err
+			}
+			appendParts("user", parts)
+
+		case schema.Assistant:
+			var parts []geminiPart
+			if msg.Content != "" {
+				parts = append(parts, geminiPart{Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				args := map[string]interface{}{}
+				if s := strings.TrimSpace(tc.Function.Arguments); s != "" {
+					if err := json.Unmarshal([]byte(s), &args); err != nil {
+						return nil, nil, fmt.Errorf("invalid arguments for tool call %s: %w", tc.Function.Name, err)
+					}
+				}
+				toolNames[tc.ID] = tc.Function.Name
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{
+					ID:   geminiCallID(tc.ID),
+					Name: tc.Function.Name,
+					Args: args,
+				}})
+			}
+			appendParts("model", parts)
+
+		case schema.Tool:
+			name := msg.ToolName
+			if name == "" {
+				name = toolNames[msg.ToolCallID]
+			}
+			if name == "" {
+				return nil, nil, fmt.Errorf("cannot resolve function name for tool message %s", msg.ToolCallID)
+			}
+			appendParts("user", []geminiPart{{FunctionResponse: &geminiFunctionResponse{
+				ID:       geminiCallID(msg.ToolCallID),
+				Name:     name,
+				Response: toGeminiFunctionResponse(msg.Content),
+			}}})
+
+		default:
+			return nil, nil, fmt.Errorf("unsupported message role for Gemini: %s", msg.Role)
+		}
+	}
+
+	var system *geminiContent
+	if len(systemParts) > 0 {
+		system = &geminiContent{Parts: systemParts}
+	}
+	return system, contents, nil
+}
+
+// toGeminiUserParts 转换用户消息内容（支持文本和图片）
+func toGeminiUserParts(msg *schema.Message) ([]geminiPart, error) {
+	var parts []geminiPart
+
+	if len(msg.UserInputMultiContent) > 0 {
+		for _, part := range msg.UserInputMultiContent {
+			switch part.Type {
+			case schema.ChatMessagePartTypeText:
+				if part.Text != "" {
+					parts = append(parts, geminiPart{Text: part.Text})
+				}
+			case schema.ChatMessagePartTypeImageURL:
+				if part.Image == nil {
+					continue
+				}
+				p, err := toGeminiMediaPart(part.Image.URL, part.Image.Base64Data, part.Image.MIMEType)
+				if err != nil {
+					return nil, err
+				}
+				parts = append(parts, p)
+			default:
+				return nil, fmt.Errorf("unsupported content part type for Gemini: %s", part.Type)
+			}
+		}
+		return parts, nil
+	}
+
+	if len(msg.MultiContent) > 0 {
+		for _, part := range msg.MultiContent {
+			switch part.Type {
+			case schema.ChatMessagePartTypeText:
+				if part.Text != "" {
+					parts = append(parts, geminiPart{Text: part.Text})
+				}
+			case schema.ChatMessagePartTypeImageURL:
+				if part.ImageURL == nil {
+					continue
+				}
+				u := part.ImageURL.URL
+				p, err := toGeminiMediaPart(&u, nil, part.ImageURL.MIMEType)
+				if err != nil {
+					return nil, err
+				}
+				parts = append(parts, p)
+			default:
+				return nil, fmt.Errorf("unsupported content part type for Gemini: %s", part.Type)
+			}
+		}
+		return parts, nil
+	}
+
+	if msg.Content != "" {
+		parts = append(parts, geminiPart{Text: msg.Content})
+	}
+	return parts, nil
+}
+
+// toGeminiMediaPart 转换图片为 inlineData 或 fileData
+func toGeminiMediaPart(uri, base64Data *string, mimeType string) (geminiPart, error) {
+	if base64Data != nil && *base64Data != "" {
+		if mimeType == "" {
+			return geminiPart{}, fmt.Errorf("mime type is required for base64 image")
+		}
+		return geminiPart{InlineData: &geminiBlob{MimeType: mimeType, Data: *base64Data}}, nil
+	}
+	if uri == nil || *uri == "" {
+		return geminiPart{}, fmt.Errorf("image url or base64 data is required")
+	}
+	if mediaType, data, ok := parseDataURL(*uri); ok {
+		return geminiPart{InlineData: &geminiBlob{MimeType: mediaType, Data: data}}, nil
+	}
+	return geminiPart{FileData: &geminiFileData{MimeType: mimeType, FileURI: *uri}}, nil
+}
+
+// toGeminiFunctionResponse 工具结果为JSON对象时直接使用，否则包装为 {"content": ...}
+func toGeminiFunctionResponse(content string) map[string]interface{} {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &obj); err == nil && obj != nil {
+		return obj
+	}
+	return map[string]interface{}{"content": content}
+}
+
+// geminiCallID 过滤本地生成的调用ID，只回传厂商返回的ID
+func geminiCallID(id string) string {
+	if strings.HasPrefix(id, geminiLocalCallIDPrefix) {
+		return ""
+	}
+	return id
+}
+
+// geminiLocalCallIDPrefix 厂商未返回调用ID时本地生成ID的前缀
+const geminiLocalCallIDPrefix = "gemini-call-"
+
+// toGeminiFunctionDeclarations 转换工具定义
+func toGeminiFunctionDeclarations(tools []*schema.ToolInfo) ([]geminiFunctionDeclaration, error) {
+	result := make([]geminiFunctionDeclaration, 0, len(tools))
+	for _, t := range tools {
+		if t == nil {
+			return nil, fmt.Errorf("tool info cannot be nil")
+		}
+		decl := geminiFunctionDeclaration{
+			Name:        t.Name,
+			Description: t.Desc,
+		}
+		if t.ParamsOneOf != nil {
+			raw, err := toolParametersJSON(t)
+			if err != nil {
+				return nil, err
+			}
+			var params map[string]interface{}
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, fmt.Errorf("failed to parse parameters of tool %s: %w", t.Name, err)
+			}
+			decl.Parameters = sanitizeGeminiSchema(params)
+		}
+		result = append(result, decl)
+	}
+	return result, nil
+}
+
+// geminiUnsupportedSchemaKeys Gemini的OpenAPI Schema子集不支持的字段
+var geminiUnsupportedSchemaKeys = []string{"$schema", "$id", "$defs", "$ref", "definitions", "additionalProperties"}
+
+// sanitizeGeminiSchema 递归移除Gemini不支持的JSON Schema字段
+func sanitizeGeminiSchema(v map[string]interface{}) map[string]interface{} {
+	for _, key := range geminiUnsupportedSchemaKeys {
+		delete(v, key)
+	}
+	for key, value := range v {
+		switch val := value.(type) {
+		case map[string]interface{}:
+			v[key] = sanitizeGeminiSchema(val)
+		case []interface{}:
+			for i, item := range val {
+				if m, ok := item.(map[string]interface{}); ok {
+					val[i] = sanitizeGeminiSchema(m)
+				}
+			}
+		}
+	}
+	return v
+}
+
+// toGeminiToolConfig 转换工具选择策略
+func toGeminiToolConfig(tc *schema.ToolChoice, allowedToolNames []string) *geminiToolConfig {
+	if tc == nil {
+		return nil
+	}
+	cfg := &geminiFunctionCallingConfig{}
+	switch *tc {
+	case schema.ToolChoiceForbidden:
+		cfg.Mode = "NONE"
+	case schema.ToolChoiceForced:
+		cfg.Mode = "ANY"
+		cfg.AllowedFunctionNames = allowedToolNames
+	default:
+		cfg.Mode = "AUTO"
+	}
+	return &geminiToolConfig{FunctionCallingConfig: cfg}
+}
+
+// geminiFinishReason 将Gemini的 finishReason 映射为统一的结束原因
+func geminiFinishReason(reason string, hasToolCalls bool) string {
+	if hasToolCalls && (reason == "" || reason == "STOP") {
+		return FinishReasonToolCalls
+	}
+	switch reason {
+	case "":
+		return ""
+	case "STOP":
+		return FinishReasonStop
+	case "MAX_TOKENS":
+		return FinishReasonLength
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return FinishReasonContentFilter
+	default:
+		return strings.ToLower(reason)
+	}
+}
+
+// geminiStreamState 响应转换状态（流式场景下跨块维护工具调用序号）
+type geminiStreamState struct {
+	toolCount    int
+	requestIDSet bool
+}
+
+// toMessage 转换单个响应（或流式块）为eino消息
+func (s *geminiStreamState) toMessage(resp *geminiResponse) *schema.Message {
+	msg := &schema.Message{Role: schema.Assistant}
+
+	var finishReason string
+	if len(resp.Candidates) > 0 {
+		candidate := resp.Candidates[0]
+		finishReason = candidate.FinishReason
+
+		var text strings.Builder
+		for _, part := range candidate.Content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				idx := s.toolCount
+				s.toolCount++
+				id := part.FunctionCall.ID
+				if id == "" {
+					id = fmt.Sprintf("%s%d", geminiLocalCallIDPrefix, idx)
+				}
+				args, _ := json.Marshal(part.FunctionCall.Args)
+				if part.FunctionCall.Args == nil {
+					args = []byte("{}")
+				}
+				msg.ToolCalls = append(msg.ToolCalls, schema.ToolCall{
+					Index: &idx,
+					ID:    id,
+					Type:  "function",
+					Function: schema.FunctionCall{
+						Name:      part.FunctionCall.Name,
+						Arguments: string(args),
+					},
+				})
+			case part.Thought:
+				msg.ReasoningContent += part.Text
+			default:
+				text.WriteString(part.Text)
+			}
+		}
+		msg.Content = text.String()
+	} else if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		// 提示词被安全策略拦截时没有候选结果
+		finishReason = "SAFETY"
+	}
+
+	meta := &schema.ResponseMeta{
+		FinishReason: geminiFinishReason(finishReason, s.toolCount > 0),
+	}
+	if resp.UsageMetadata != nil {
+		meta.Usage = resp.UsageMetadata.toTokenUsage()
+	}
+	if meta.FinishReason != "" || meta.Usage != nil {
+		msg.ResponseMeta = meta
+	}
+
+	// 流式场景下请求ID只写入一次，避免合并消息时重复拼接
+	if resp.ResponseID != "" && !s.requestIDSet {
+		s.requestIDSet = true
+		msg.Extra = map[string]any{ExtraKeyRequestID: resp.ResponseID}
+	}
+
+	return msg
+}
+
+// geminiRequest generateContent 请求体
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings    []geminiSafetySetting   `json:"safetySettings,omitempty"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	ToolConfig        *geminiToolConfig       `json:"toolConfig,omitempty"`
+}
+
+// geminiContent 消息内容
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+// geminiPart 内容片段
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	InlineData       *geminiBlob             `json:"inlineData,omitempty"`
+	FileData         *geminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// geminiBlob 内联二进制数据
+type geminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// geminiFileData 文件引用
+type geminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+// geminiFunctionCall 函数调用
+type geminiFunctionCall struct {
+	ID   string                 `json:"id,omitempty"`
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// geminiFunctionResponse 函数调用结果
+type geminiFunctionResponse struct {
+	ID       string                 `json:"id,omitempty"`
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
+}
+
+// geminiGenerationConfig 生成参数
+type geminiGenerationConfig struct {
+	Temperature     *float32 `json:"temperature,omitempty"`
+	TopP            *float32 `json:"topP,omitempty"`
+	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+}
+
+// geminiSafetySetting 安全过滤设置
+type geminiSafetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+// geminiTool 工具集合
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+// geminiFunctionDeclaration 函数声明
+type geminiFunctionDeclaration struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// geminiToolConfig 工具调用配置
+type geminiToolConfig struct {
+	FunctionCallingConfig *geminiFunctionCallingConfig `json:"functionCallingConfig,omitempty"`
+}
+
+// geminiFunctionCallingConfig 函数调用模式
+type geminiFunctionCallingConfig struct {
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// geminiResponse generateContent 响应体
+type geminiResponse struct {
+	Candidates     []geminiCandidate     `json:"candidates"`
+	PromptFeedback *geminiPromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  *geminiUsageMetadata  `json:"usageMetadata,omitempty"`
+	ModelVersion   string                `json:"modelVersion,omitempty"`
+	ResponseID     string                `json:"responseId,omitempty"`
+	Error          *geminiError          `json:"error,omitempty"`
+}
+
+// geminiCandidate 候选结果
+type geminiCandidate struct {
+	Content      geminiContent `json:"content"`
+	FinishReason string        `json:"finishReason,omitempty"`
+}
+
+// geminiPromptFeedback 提示词反馈
+type geminiPromptFeedback struct {
+	BlockReason string `json:"blockReason,omitempty"`
+}
+
+// geminiUsageMetadata token使用情况
+type geminiUsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount"`
+}
+
+// toTokenUsage 转换为eino的TokenUsage
+func (u *geminiUsageMetadata) toTokenUsage() *schema.TokenUsage {
+	return &schema.TokenUsage{
+		PromptTokens: u.PromptTokenCount,
+		PromptTokenDetails: schema.PromptTokenDetails{
+			CachedTokens: u.CachedContentTokenCount,
+		},
+		CompletionTokens: u.CandidatesTokenCount + u.ThoughtsTokenCount,
+		TotalTokens:      u.TotalTokenCount,
+		CompletionTokensDetails: schema.CompletionTokensDetails{
+			ReasoningTokens: u.ThoughtsTokenCount,
+		},
+	}
+}
+
+// geminiError 错误详情
+type geminiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// toAPIError 转换为APIError
+func (e *geminiError) toAPIError(statusCode int) *APIError {
+	if statusCode == 0 {
+		statusCode = e.Code
+	}
+	return &APIError{
+		Provider:   "gemini",
+		StatusCode: statusCode,
+		Type:       e.Status,
+		Message:    e.Message,
+	}
+}
+
+var (
+	_ model.ChatModel            = (*GeminiChatModel)(nil)
+	_ model.ToolCallingChatModel = (*GeminiChatModel)(nil)
+)
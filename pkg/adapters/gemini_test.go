@@ -0,0 +1,290 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+// newGeminiTestServer 创建模拟 generateContent API 的测试服务
+func newGeminiTestServer(t *testing.T, wantPath string, handler func(w http.ResponseWriter, req map[string]interface{})) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != wantPath {
+			t.Errorf("path = %s, want %s", r.URL.Path, wantPath)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "test-key" {
+			t.Errorf("x-goog-api-key = %q, want test-key", got)
+		}
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		handler(w, req)
+	}))
+}
+
+func TestGeminiAdapter_Chat(t *testing.T) {
+	server := newGeminiTestServer(t, "/models/gemini-1.5-flash:generateContent", func(w http.ResponseWriter, req map[string]interface{}) {
+		system := req["systemInstruction"].(map[string]interface{})
+		if text := system["parts"].([]interface{})[0].(map[string]interface{})["text"]; text != "你是助手" {
+			t.Errorf("systemInstruction = %v, want 你是助手", text)
+		}
+
+		gen := req["generationConfig"].(map[string]interface{})
+		if gen["maxOutputTokens"].(float64) != 128 {
+			t.Errorf("maxOutputTokens = %v, want 128", gen["maxOutputTokens"])
+		}
+
+		safety := req["safetySettings"].([]interface{})
+		if len(safety) != 1 || safety[0].(map[string]interface{})["threshold"] != "BLOCK_NONE" {
+			t.Errorf("unexpected safetySettings: %v", safety)
+		}
+
+		contents := req["contents"].([]interface{})
+		if len(contents) != 1 {
+			t.Errorf("expected 1 content, got %d", len(contents))
+			return
+		}
+		parts := contents[0].(map[string]interface{})["parts"].([]interface{})
+		if len(parts) != 2 {
+			t.Errorf("expected text and image parts, got %v", parts)
+			return
+		}
+		inline := parts[1].(map[string]interface{})["inlineData"].(map[string]interface{})
+		if inline["mimeType"] != "image/png" || inline["data"] != "aGVsbG8=" {
+			t.Errorf("unexpected inlineData: %v", inline)
+		}
+
+		fmt.Fprint(w, `{
+			"candidates": [{"content": {"role": "model", "parts": [{"text": "一张图片"}]}, "finishReason": "STOP"}],
+			"usageMetadata": {"promptTokenCount": 30, "candidatesTokenCount": 4, "totalTokenCount": 34},
+			"responseId": "resp_1"
+		}`)
+	})
+	defer server.Close()
+
+	adapter, err := NewGeminiAdapter(types.ProviderGemini, "gemini-1.5-flash",
+		options.WithAPIKey("test-key"),
+		options.WithBaseURL(server.URL),
+		options.WithMaxTokens(128),
+		options.WithSafetySettings(types.SafetySetting{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_NONE"}),
+	)
+	if err != nil {
+		t.Fatalf("NewGeminiAdapter() error: %v", err)
+	}
+
+	image := "data:image/png;base64,aGVsbG8="
+	resp, err := adapter.Chat(context.Background(), []*schema.Message{
+		schema.SystemMessage("你是助手"),
+		{
+			Role: schema.User,
+			UserInputMultiContent: []schema.MessageInputPart{
+				{Type: schema.ChatMessagePartTypeText, Text: "描述这张图"},
+				{Type: schema.ChatMessagePartTypeImageURL, Image: &schema.MessageInputImage{
+					MessagePartCommon: schema.MessagePartCommon{URL: &image},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+
+	if resp.Content != "一张图片" {
+		t.Errorf("Content = %q, want 一张图片", resp.Content)
+	}
+	if resp.ResponseMeta.FinishReason != FinishReasonStop {
+		t.Errorf("FinishReason = %q, want %q", resp.ResponseMeta.FinishReason, FinishReasonStop)
+	}
+	if resp.ResponseMeta.Usage.TotalTokens != 34 {
+		t.Errorf("TotalTokens = %d, want 34", resp.ResponseMeta.Usage.TotalTokens)
+	}
+	if resp.Extra[ExtraKeyRequestID] != "resp_1" {
+		t.Errorf("request id = %v, want resp_1", resp.Extra[ExtraKeyRequestID])
+	}
+}
+
+func TestGeminiChatModel_FunctionCalling(t *testing.T) {
+	server := newGeminiTestServer(t, "/models/gemini-1.5-pro:generateContent", func(w http.ResponseWriter, req map[string]interface{}) {
+		tools := req["tools"].([]interface{})
+		decls := tools[0].(map[string]interface{})["functionDeclarations"].([]interface{})
+		decl := decls[0].(map[string]interface{})
+		if decl["name"] != "weather" {
+			t.Errorf("function name = %v, want weather", decl["name"])
+		}
+		params := decl["parameters"].(map[string]interface{})
+		if params["type"] != "object" {
+			t.Errorf("parameters type = %v, want object", params["type"])
+		}
+		mode := req["toolConfig"].(map[string]interface{})["functionCallingConfig"].(map[string]interface{})["mode"]
+		if mode != "AUTO" {
+			t.Errorf("function calling mode = %v, want AUTO", mode)
+		}
+
+		// model(functionCall) + user(functionResponse) 需要按角色转换
+		contents := req["contents"].([]interface{})
+		if len(contents) != 3 {
+			t.Errorf("expected 3 contents, got %d", len(contents))
+			return
+		}
+		call := contents[1].(map[string]interface{})
+		if call["role"] != "model" {
+			t.Errorf("role = %v, want model", call["role"])
+		}
+		fr := contents[2].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})["functionResponse"].(map[string]interface{})
+		if fr["name"] != "weather" {
+			t.Errorf("functionResponse name = %v, want weather", fr["name"])
+		}
+		if fr["response"].(map[string]interface{})["content"] != "晴" {
+			t.Errorf("unexpected functionResponse: %v", fr)
+		}
+
+		fmt.Fprint(w, `{
+			"candidates": [{"content": {"role": "model", "parts": [
+				{"functionCall": {"name": "weather", "args": {"city": "上海"}}}
+			]}, "finishReason": "STOP"}]
+		}`)
+	})
+	defer server.Close()
+
+	cm, err := NewGeminiChatModel(&GeminiChatModelConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+		Model:   "gemini-1.5-pro",
+	})
+	if err != nil {
+		t.Fatalf("NewGeminiChatModel() error: %v", err)
+	}
+	withTools, err := cm.WithTools([]*schema.ToolInfo{{
+		Name: "weather",
+		Desc: "查询天气",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"city": {Type: schema.String, Desc: "城市", Required: true},
+		}),
+	}})
+	if err != nil {
+		t.Fatalf("WithTools() error: %v", err)
+	}
+
+	resp, err := withTools.Generate(context.Background(), []*schema.Message{
+		schema.UserMessage("北京天气"),
+		schema.AssistantMessage("", []schema.ToolCall{{
+			ID:       "gemini-call-0",
+			Function: schema.FunctionCall{Name: "weather", Arguments: `{"city":"北京"}`},
+		}}),
+		schema.ToolMessage("晴", "gemini-call-0"),
+	})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(resp.ToolCalls))
+	}
+	if resp.ToolCalls[0].Function.Name != "weather" || resp.ToolCalls[0].Function.Arguments != `{"city":"上海"}` {
+		t.Errorf("unexpected tool call: %+v", resp.ToolCalls[0])
+	}
+	if resp.ToolCalls[0].ID == "" {
+		t.Error("tool call id should not be empty")
+	}
+	if resp.ResponseMeta.FinishReason != FinishReasonToolCalls {
+		t.Errorf("FinishReason = %q, want %q", resp.ResponseMeta.FinishReason, FinishReasonToolCalls)
+	}
+}
+
+func TestGeminiChatModel_Stream(t *testing.T) {
+	chunks := []string{
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"你好"}]}}],"responseId":"resp_2","usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":1,"totalTokenCount":6}}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"，世界"}]},"finishReason":"MAX_TOKENS"}],"responseId":"resp_2","usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":3,"totalTokenCount":8}}`,
+	}
+
+	server := newGeminiTestServer(t, "/models/gemini-1.5-flash:streamGenerateContent", func(w http.ResponseWriter, req map[string]interface{}) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, c := range chunks {
+			fmt.Fprintf(w, "data: %s\r\n\r\n", c)
+		}
+	})
+	defer server.Close()
+
+	cm, err := NewGeminiChatModel(&GeminiChatModelConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+		Model:   "gemini-1.5-flash",
+	})
+	if err != nil {
+		t.Fatalf("NewGeminiChatModel() error: %v", err)
+	}
+
+	stream, err := cm.Stream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("Stream() error: %v", err)
+	}
+	defer stream.Close()
+
+	var msgs []*schema.Message
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	full, err := schema.ConcatMessages(msgs)
+	if err != nil {
+		t.Fatalf("ConcatMessages() error: %v", err)
+	}
+	if full.Content != "你好，世界" {
+		t.Errorf("Content = %q, want 你好，世界", full.Content)
+	}
+	if full.ResponseMeta.FinishReason != FinishReasonLength {
+		t.Errorf("FinishReason = %q, want %q", full.ResponseMeta.FinishReason, FinishReasonLength)
+	}
+	if full.ResponseMeta.Usage.TotalTokens != 8 {
+		t.Errorf("TotalTokens = %d, want 8", full.ResponseMeta.Usage.TotalTokens)
+	}
+	if full.Extra[ExtraKeyRequestID] != "resp_2" {
+		t.Errorf("request id = %v, want resp_2", full.Extra[ExtraKeyRequestID])
+	}
+}
+
+func TestGeminiChatModel_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"error":{"code":503,"message":"overloaded","status":"UNAVAILABLE"}}`)
+	}))
+	defer server.Close()
+
+	cm, err := NewGeminiChatModel(&GeminiChatModelConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+		Model:   "gemini-1.5-flash",
+	})
+	if err != nil {
+		t.Fatalf("NewGeminiChatModel() error: %v", err)
+	}
+
+	_, err = cm.Generate(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Type != "UNAVAILABLE" {
+		t.Errorf("unexpected api error: %+v", apiErr)
+	}
+}
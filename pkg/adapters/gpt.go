@@ -24,6 +24,20 @@ func NewGPTAdapter(provider types.Provider, modelName string, opts ...options.Op
 		return nil, fmt.Errorf("api key is required for GPT")
 	}
 
+	// 创建共享HTTP客户端（代理、额外请求头、TLS、超时）
+	httpClient, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	// OpenAI组织ID仅发送给OpenAI
+	if cfg.Organization != "" {
+		httpClient.Transport = &headerTransport{
+			base:    httpClient.Transport,
+			headers: map[string]string{HeaderOrganization: cfg.Organization},
+		}
+	}
+
 	// 创建OpenAI配置
 	openaiCfg := &openai.ChatModelConfig{
 		APIKey:      cfg.APIKey,
@@ -31,6 +45,7 @@ func NewGPTAdapter(provider types.Provider, modelName string, opts ...options.Op
 		MaxTokens:   &cfg.MaxTokens,
 		Temperature: &cfg.Temperature,
 		TopP:        &cfg.TopP,
+		HTTPClient:  httpClient,
 	}
 
 	// 设置基础URL（如果使用自定义端点）
@@ -54,6 +69,11 @@ func NewGPTAdapter(provider types.Provider, modelName string, opts ...options.Op
 		},
 	}
 
+	// 绑定MCP工具
+	if err := adapter.bindTools(context.Background()); err != nil {
+		return nil, err
+	}
+
 	return adapter, nil
 }
 
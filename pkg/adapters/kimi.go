@@ -30,6 +30,12 @@ func NewKimiAdapter(provider types.Provider, modelName string, opts ...options.O
 		baseURL = "https://api.moonshot.cn/v1"
 	}
 
+	// 创建共享HTTP客户端（代理、额外请求头、TLS、超时）
+	httpClient, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
 	// 创建OpenAI配置
 	openaiCfg := &openai.ChatModelConfig{
 		APIKey:      cfg.APIKey,
@@ -38,6 +44,7 @@ func NewKimiAdapter(provider types.Provider, modelName string, opts ...options.O
 		MaxTokens:   &cfg.MaxTokens,
 		Temperature: &cfg.Temperature,
 		TopP:        &cfg.TopP,
+		HTTPClient:  httpClient,
 	}
 
 	// 创建ChatModel
@@ -56,6 +63,11 @@ func NewKimiAdapter(provider types.Provider, modelName string, opts ...options.O
 		},
 	}
 
+	// 绑定MCP工具
+	if err := adapter.bindTools(context.Background()); err != nil {
+		return nil, err
+	}
+
 	return adapter, nil
 }
 
@@ -0,0 +1,242 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/types"
+)
+
+// redactedValue 脱敏后的占位符
+const redactedValue = "[REDACTED]"
+
+// maxLoggedBodySize 请求/响应体日志的最大长度
+const maxLoggedBodySize = 64 * 1024
+
+// sensitiveHeaders 需要脱敏的请求头
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "X-Api-Key", "X-Goog-Api-Key", "Api-Key"}
+
+// newLogger 根据配置创建日志记录器，未启用日志时返回 nil
+func newLogger(cfg *types.Config) *slog.Logger {
+	if cfg == nil || !cfg.EnableLog {
+		return nil
+	}
+	handler := cfg.LogHandler
+	if handler == nil {
+		handler = slog.Default().Handler()
+	}
+	return slog.New(handler).With("component", "ai-bridge")
+}
+
+// logger 返回适配器的日志记录器（首次调用时创建），未启用日志时返回 nil
+func (b *BaseAdapter) logger() *slog.Logger {
+	b.loggerOnce.Do(func() {
+		b.log = newLogger(b.Config)
+	})
+	return b.log
+}
+
+// logResult 记录一次调用的结果
+func (b *BaseAdapter) logResult(ctx context.Context, op string, start time.Time, msg *schema.Message, err error) {
+	logger := b.logger()
+	if logger == nil {
+		return
+	}
+
+	attrs := []any{
+		slog.String("provider", string(b.Provider)),
+		slog.String("model", b.ModelName),
+		slog.String("op", op),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		if code := StatusCodeOf(err); code != 0 {
+			attrs = append(attrs, slog.Int("status_code", code))
+		}
+		logger.ErrorContext(ctx, "ai-bridge request failed", attrs...)
+		return
+	}
+
+	if msg != nil {
+		if msg.ResponseMeta != nil {
+			attrs = append(attrs, slog.String("finish_reason", msg.ResponseMeta.FinishReason))
+			if u := msg.ResponseMeta.Usage; u != nil {
+				attrs = append(attrs,
+					slog.Int("prompt_tokens", u.PromptTokens),
+					slog.Int("completion_tokens", u.CompletionTokens),
+					slog.Int("total_tokens", u.TotalTokens),
+				)
+			}
+		}
+		if id := RequestIDOf(msg); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		if len(msg.ToolCalls) > 0 {
+			attrs = append(attrs, slog.Int("tool_calls", len(msg.ToolCalls)))
+		}
+	}
+	logger.InfoContext(ctx, "ai-bridge request completed", attrs...)
+}
+
+// logRetry 记录重试事件
+func (b *BaseAdapter) logRetry(ctx context.Context, event types.RetryEvent) {
+	logger := b.logger()
+	if logger == nil {
+		return
+	}
+	logger.WarnContext(ctx, "ai-bridge request retrying",
+		slog.String("provider", string(event.Provider)),
+		slog.String("model", event.Model),
+		slog.Int("attempt", event.Attempt),
+		slog.Int("max_retries", event.MaxRetries),
+		slog.Duration("delay", event.Delay),
+		slog.String("error", event.Err.Error()),
+	)
+}
+
+// logStream 包装流式响应，在流结束时记录合并后的结果
+func (b *BaseAdapter) logStream(ctx context.Context, op string, start time.Time, stream *schema.StreamReader[*schema.Message]) *schema.StreamReader[*schema.Message] {
+	var chunks []*schema.Message
+	return RelayStream(stream, func(msg *schema.Message) {
+		chunks = append(chunks, msg)
+	}, func(err error) {
+		switch {
+		case err == nil:
+			var full *schema.Message
+			if len(chunks) > 0 {
+				full, _ = schema.ConcatMessages(chunks)
+			}
+			b.logResult(ctx, op, start, full, nil)
+		case !errors.Is(err, ErrStreamClosed):
+			b.logResult(ctx, op, start, nil, err)
+		}
+	})
+}
+
+// payloadTransport 记录完整的HTTP请求与响应内容（敏感信息脱敏）
+type payloadTransport struct {
+	base    http.RoundTripper
+	logger  *slog.Logger
+	secrets []string
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *payloadTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(io.LimitReader(body, maxLoggedBodySize))
+			body.Close()
+		}
+	}
+	t.logger.DebugContext(ctx, "ai-bridge http request",
+		slog.String("method", req.Method),
+		slog.String("url", t.redact(req.URL.String())),
+		slog.Any("headers", redactHeaders(req.Header)),
+		slog.String("body", t.redact(string(reqBody))),
+	)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []any{
+		slog.Int("status_code", resp.StatusCode),
+		slog.Any("headers", redactHeaders(resp.Header)),
+	}
+	if resp.Body == nil {
+		t.logger.DebugContext(ctx, "ai-bridge http response", attrs...)
+		return resp, nil
+	}
+
+	// 流式响应边读边记录，在响应体关闭时输出，避免阻塞或缓存整个流
+	if isStreamContentType(resp.Header.Get("Content-Type")) {
+		resp.Body = &loggedBody{ReadCloser: resp.Body, onClose: func(body []byte) {
+			attrs = append(attrs, slog.String("body", t.redact(string(body))))
+			t.logger.DebugContext(ctx, "ai-bridge http response", attrs...)
+		}}
+		return resp, nil
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if readErr != nil {
+		return nil, readErr
+	}
+	if len(body) > maxLoggedBodySize {
+		body = body[:maxLoggedBodySize]
+	}
+	attrs = append(attrs, slog.String("body", t.redact(string(body))))
+	t.logger.DebugContext(ctx, "ai-bridge http response", attrs...)
+
+	return resp, nil
+}
+
+// isStreamContentType 判断是否为流式响应（SSE 或 Ollama 使用的 NDJSON）
+func isStreamContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/event-stream") ||
+		strings.HasPrefix(contentType, "application/x-ndjson")
+}
+
+// loggedBody 在读取流式响应体的同时保留前 maxLoggedBodySize 字节，关闭时回调一次
+type loggedBody struct {
+	io.ReadCloser
+	buf     bytes.Buffer
+	once    sync.Once
+	onClose func(body []byte)
+}
+
+// Read 实现 io.Reader 接口
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if remain := maxLoggedBodySize - b.buf.Len(); remain > 0 && n > 0 {
+		b.buf.Write(p[:min(n, remain)])
+	}
+	return n, err
+}
+
+// Close 实现 io.Closer 接口
+func (b *loggedBody) Close() error {
+	b.once.Do(func() { b.onClose(b.buf.Bytes()) })
+	return b.ReadCloser.Close()
+}
+
+// redact 将内容中出现的密钥替换为占位符
+func (t *payloadTransport) redact(s string) string {
+	for _, secret := range t.secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redactedValue)
+		}
+	}
+	return s
+}
+
+// redactHeaders 复制请求头并脱敏敏感字段
+func redactHeaders(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for k, v := range header {
+		result[k] = strings.Join(v, ", ")
+	}
+	for _, k := range sensitiveHeaders {
+		for name := range result {
+			if strings.EqualFold(name, k) {
+				result[name] = redactedValue
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,181 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+// parseLogLines 解析JSON格式的日志
+func parseLogLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestBaseAdapter_Logging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o",
+			"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}],
+			"usage":{"prompt_tokens":7,"completion_tokens":2,"total_tokens":9}}`)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	adapter, err := NewGPTAdapter(types.ProviderGPT, "gpt-4o",
+		options.WithAPIKey("sk-secret-key"),
+		options.WithBaseURL(server.URL),
+		options.WithLogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		options.WithLogPayload(true),
+	)
+	if err != nil {
+		t.Fatalf("NewGPTAdapter() error: %v", err)
+	}
+
+	if _, err := adapter.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}); err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "sk-secret-key") {
+		t.Errorf("api key leaked into logs: %s", buf.String())
+	}
+
+	lines := parseLogLines(t, &buf)
+	if len(lines) != 3 {
+		t.Fatalf("expected request, response and result logs, got %d: %s", len(lines), buf.String())
+	}
+
+	request := lines[0]
+	headers := request["headers"].(map[string]interface{})
+	if headers["Authorization"] != redactedValue {
+		t.Errorf("Authorization header = %v, want redacted", headers["Authorization"])
+	}
+	if !strings.Contains(request["body"].(string), `"hi"`) {
+		t.Errorf("request body not logged: %v", request["body"])
+	}
+
+	result := lines[2]
+	if result["level"] != "INFO" || result["provider"] != "gpt" || result["model"] != "gpt-4o" {
+		t.Errorf("unexpected result log: %v", result)
+	}
+	if result["finish_reason"] != "stop" || result["total_tokens"].(float64) != 9 {
+		t.Errorf("missing usage or finish reason: %v", result)
+	}
+	if _, ok := result["latency"]; !ok {
+		t.Errorf("missing latency: %v", result)
+	}
+}
+
+func TestBaseAdapter_LoggingStreamAndError(t *testing.T) {
+	var buf bytes.Buffer
+	b := &BaseAdapter{
+		Provider:  types.ProviderGPT,
+		ModelName: "gpt-4o",
+		Config: &types.Config{
+			EnableLog:  true,
+			LogHandler: slog.NewJSONHandler(&buf, nil),
+		},
+		ChatModel: &scriptedStreamModel{stream: func() *schema.StreamReader[*schema.Message] {
+			return schema.StreamReaderFromArray([]*schema.Message{
+				schema.AssistantMessage("你", nil),
+				{Role: schema.Assistant, Content: "好", ResponseMeta: &schema.ResponseMeta{FinishReason: "length"}},
+			})
+		}},
+	}
+
+	stream, err := b.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+	}
+	stream.Close()
+
+	// scriptedStreamModel.Generate 总是失败
+	if _, err := b.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	lines := parseLogLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf.String())
+	}
+	if lines[0]["op"] != "chat_stream" || lines[0]["finish_reason"] != "length" {
+		t.Errorf("unexpected stream log: %v", lines[0])
+	}
+	if lines[1]["level"] != "ERROR" || lines[1]["error"] != "not implemented" {
+		t.Errorf("unexpected error log: %v", lines[1])
+	}
+}
+
+func TestBaseAdapter_LoggingDisabled(t *testing.T) {
+	if newLogger(&types.Config{LogHandler: slog.NewJSONHandler(io.Discard, nil)}) != nil {
+		t.Error("logger should be nil when EnableLog is false")
+	}
+}
+
+func TestPayloadTransport_StreamBody(t *testing.T) {
+	// NDJSON 流式响应（Ollama）不应被一次性读取，响应体在关闭时记录
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"done":false}`)
+		w.(http.Flusher).Flush()
+		<-release
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var buf bytes.Buffer
+	rt := &payloadTransport{
+		base:   http.DefaultTransport,
+		logger: slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error: %v", err)
+	}
+	line := make([]byte, len(`{"done":false}`)+1)
+	if _, err := io.ReadFull(resp.Body, line); err != nil {
+		t.Fatalf("read first line: %v", err)
+	}
+	resp.Body.Close()
+
+	lines := parseLogLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf.String())
+	}
+	if lines[1]["body"] != string(line) {
+		t.Errorf("body = %q, want %q", lines[1]["body"], line)
+	}
+}
@@ -0,0 +1,383 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"gopkg.in/yaml.v3"
+
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+// ErrMockScriptExhausted 脚本中的响应已用完
+var ErrMockScriptExhausted = errors.New("mock script exhausted")
+
+// MockStep 模拟厂商的一次响应
+type MockStep struct {
+	Content      string         `yaml:"content"`       // 响应内容
+	ToolCalls    []MockToolCall `yaml:"tool_calls"`    // 工具调用
+	Chunks       []string       `yaml:"chunks"`        // 流式块序列（为空时 Content 作为单个块；Content 为空时非流式返回拼接结果）
+	FinishReason string         `yaml:"finish_reason"` // 结束原因（默认 stop，有工具调用时为 tool_calls）
+	Usage        *MockUsage     `yaml:"usage"`         // token用量（可选）
+
+	Latency    time.Duration `yaml:"latency"`     // 响应前的等待时间（流式为首个块之前）
+	ChunkDelay time.Duration `yaml:"chunk_delay"` // 流式块之间的等待时间
+
+	Error       string `yaml:"error"`        // 调用错误
+	StatusCode  int    `yaml:"status_code"`  // 设置时错误为 *APIError（如 429、503，可触发重试）
+	StreamError string `yaml:"stream_error"` // 发送完所有块后返回的流错误
+
+	Expect string `yaml:"expect"` // 断言：最后一条用户消息须包含该文本，否则返回错误
+}
+
+// MockToolCall 模拟的工具调用
+type MockToolCall struct {
+	ID        string `yaml:"id"`
+	Name      string `yaml:"name"`
+	Arguments string `yaml:"arguments"` // JSON 字符串
+}
+
+// MockUsage 模拟的token用量
+type MockUsage struct {
+	PromptTokens     int `yaml:"prompt_tokens"`
+	CompletionTokens int `yaml:"completion_tokens"`
+}
+
+// MockScript 模拟厂商的响应脚本（并发安全）
+// 每次调用按顺序取出一个 MockStep，并记录收到的消息用于断言。
+type MockScript struct {
+	Steps  []MockStep `yaml:"steps"`
+	Repeat bool       `yaml:"repeat"` // 用完后从头开始（默认返回 ErrMockScriptExhausted）
+
+	mu       sync.Mutex
+	next     int
+	received [][]*schema.Message
+}
+
+// NewMockScript 创建响应脚本
+func NewMockScript(steps ...MockStep) *MockScript {
+	return &MockScript{Steps: steps}
+}
+
+// ParseMockScript 解析YAML格式的响应脚本
+func ParseMockScript(data []byte) (*MockScript, error) {
+	var script MockScript
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse mock script: %w", err)
+	}
+	return &script, nil
+}
+
+// LoadMockScript 从YAML文件加载响应脚本
+func LoadMockScript(path string) (*MockScript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock script: %w", err)
+	}
+	return ParseMockScript(data)
+}
+
+// take 记录收到的消息并取出下一个响应
+func (s *MockScript) take(messages []*schema.Message) (MockStep, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.received = append(s.received, append([]*schema.Message(nil), messages...))
+	if s.next >= len(s.Steps) {
+		if !s.Repeat || len(s.Steps) == 0 {
+			return MockStep{}, ErrMockScriptExhausted
+		}
+		s.next = 0
+	}
+	step := s.Steps[s.next]
+	s.next++
+	return step, nil
+}
+
+// Calls 返回已收到的调用次数
+func (s *MockScript) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.received)
+}
+
+// Received 返回第 i 次调用（从0开始）收到的消息（含适配器添加的系统提示词），不存在时返回 nil
+func (s *MockScript) Received(i int) []*schema.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i < 0 || i >= len(s.received) {
+		return nil
+	}
+	return s.received[i]
+}
+
+// MockT 断言所需的测试接口（*testing.T 满足）
+type MockT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// AssertCalls 断言调用次数
+func (s *MockScript) AssertCalls(t MockT, want int) bool {
+	t.Helper()
+	if got := s.Calls(); got != want {
+		t.Errorf("mock calls = %d, want %d", got, want)
+		return false
+	}
+	return true
+}
+
+// AssertReceived 断言第 call 次调用中存在角色为 role 且内容包含 contains 的消息
+func (s *MockScript) AssertReceived(t MockT, call int, role schema.RoleType, contains string) bool {
+	t.Helper()
+	messages := s.Received(call)
+	if messages == nil {
+		t.Errorf("mock call %d not received (%d calls)", call, s.Calls())
+		return false
+	}
+	for _, msg := range messages {
+		if msg != nil && msg.Role == role && strings.Contains(msg.Content, contains) {
+			return true
+		}
+	}
+	t.Errorf("mock call %d has no %s message containing %q", call, role, contains)
+	return false
+}
+
+// mockScripts 按模型名注册的脚本
+var (
+	mockScriptsMu sync.RWMutex
+	mockScripts   = make(map[string]*MockScript)
+)
+
+// RegisterMockScript 为模型名注册脚本，之后 NewAIClient(types.ProviderMock, modelName) 使用该脚本
+// 未注册脚本的模型名将原样返回最后一条用户消息（echo）
+func RegisterMockScript(modelName string, script *MockScript) {
+	mockScriptsMu.Lock()
+	defer mockScriptsMu.Unlock()
+	mockScripts[modelName] = script
+}
+
+// UnregisterMockScript 移除模型名对应的脚本
+func UnregisterMockScript(modelName string) {
+	mockScriptsMu.Lock()
+	defer mockScriptsMu.Unlock()
+	delete(mockScripts, modelName)
+}
+
+// MockAdapter 模拟厂商适配器
+// 基于 BaseAdapter，系统提示词、重试、日志等行为与真实厂商一致
+type MockAdapter struct {
+	BaseAdapter
+	Script *MockScript // 响应脚本（nil 表示 echo）
+}
+
+// NewMockAdapter 创建模拟厂商适配器，使用 RegisterMockScript 为该模型名注册的脚本
+func NewMockAdapter(provider types.Provider, modelName string, opts ...options.Option) (types.AIBridge, error) {
+	mockScriptsMu.RLock()
+	script := mockScripts[modelName]
+	mockScriptsMu.RUnlock()
+
+	adapter, err := NewMockAdapterWithScript(modelName, script, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return adapter, nil
+}
+
+// NewMockAdapterWithScript 使用指定脚本创建模拟厂商适配器
+// 脚本中的可重试错误（如 status_code: 503）会按 MaxRetries 重试，测试时可配合 options.WithMaxRetries(0)
+func NewMockAdapterWithScript(modelName string, script *MockScript, opts ...options.Option) (*MockAdapter, error) {
+	cfg := options.ApplyOptions(opts...)
+
+	adapter := &MockAdapter{
+		BaseAdapter: BaseAdapter{
+			Provider:  types.ProviderMock,
+			ModelName: modelName,
+			Config:    cfg,
+			ModelInfo: &types.ModelInfo{
+				Name:        modelName,
+				Provider:    types.ProviderMock,
+				MaxTokens:   cfg.MaxTokens,
+				Description: "Mock模型: " + modelName,
+			},
+			ChatModel: &mockChatModel{script: script},
+		},
+		Script: script,
+	}
+
+	// 绑定MCP工具
+	if err := adapter.bindTools(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return adapter, nil
+}
+
+// mockChatModel 按脚本返回响应的 ChatModel
+type mockChatModel struct {
+	script *MockScript
+	tools  []*schema.ToolInfo
+}
+
+// Generate 返回脚本中的下一个响应
+func (m *mockChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	step, err := m.step(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	content := step.Content
+	if content == "" {
+		content = strings.Join(step.Chunks, "")
+	}
+	msg := schema.AssistantMessage(content, step.toolCalls())
+	msg.ResponseMeta = step.responseMeta()
+	return msg, nil
+}
+
+// Stream 按脚本中的块序列返回流式响应
+func (m *mockChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	step, err := m.step(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := step.Chunks
+	if len(chunks) == 0 {
+		chunks = []string{step.Content}
+	}
+
+	sr, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sw.Close()
+		for i, content := range chunks {
+			if i > 0 && !sleepContext(ctx, step.ChunkDelay) {
+				sw.Send(nil, ctx.Err())
+				return
+			}
+			msg := schema.AssistantMessage(content, nil)
+			if i == len(chunks)-1 {
+				msg.ToolCalls = step.toolCalls()
+				msg.ResponseMeta = step.responseMeta()
+			}
+			if closed := sw.Send(msg, nil); closed {
+				return
+			}
+		}
+		if step.StreamError != "" {
+			sw.Send(nil, errors.New(step.StreamError))
+		}
+	}()
+	return sr, nil
+}
+
+// BindTools 绑定工具（模拟厂商不校验工具）
+func (m *mockChatModel) BindTools(tools []*schema.ToolInfo) error {
+	m.tools = tools
+	return nil
+}
+
+// WithTools 返回绑定了工具的副本
+func (m *mockChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return &mockChatModel{script: m.script, tools: tools}, nil
+}
+
+// step 取出下一个响应，等待 Latency 并检查 Expect；未设置脚本时 echo 最后一条用户消息
+func (m *mockChatModel) step(ctx context.Context, input []*schema.Message) (MockStep, error) {
+	lastUser := ""
+	for i := len(input) - 1; i >= 0; i-- {
+		if input[i] != nil && input[i].Role == schema.User {
+			lastUser = input[i].Content
+			break
+		}
+	}
+	if m.script == nil {
+		return MockStep{Content: lastUser}, nil
+	}
+
+	step, err := m.script.take(input)
+	if err != nil {
+		return MockStep{}, err
+	}
+	if !sleepContext(ctx, step.Latency) {
+		return MockStep{}, ctx.Err()
+	}
+	if step.Expect != "" && !strings.Contains(lastUser, step.Expect) {
+		return MockStep{}, fmt.Errorf("mock: expected last user message to contain %q, got %q", step.Expect, lastUser)
+	}
+	if step.StatusCode != 0 {
+		return MockStep{}, &APIError{Provider: string(types.ProviderMock), StatusCode: step.StatusCode, Message: step.Error}
+	}
+	if step.Error != "" {
+		return MockStep{}, errors.New(step.Error)
+	}
+	return step, nil
+}
+
+// toolCalls 转换为 schema.ToolCall
+func (s MockStep) toolCalls() []schema.ToolCall {
+	if len(s.ToolCalls) == 0 {
+		return nil
+	}
+	calls := make([]schema.ToolCall, len(s.ToolCalls))
+	for i, c := range s.ToolCalls {
+		index := i
+		id := c.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d", i+1)
+		}
+		calls[i] = schema.ToolCall{
+			Index:    &index,
+			ID:       id,
+			Type:     "function",
+			Function: schema.FunctionCall{Name: c.Name, Arguments: c.Arguments},
+		}
+	}
+	return calls
+}
+
+// responseMeta 结束原因和token用量
+func (s MockStep) responseMeta() *schema.ResponseMeta {
+	meta := &schema.ResponseMeta{FinishReason: s.FinishReason}
+	if meta.FinishReason == "" {
+		meta.FinishReason = "stop"
+		if len(s.ToolCalls) > 0 {
+			meta.FinishReason = "tool_calls"
+		}
+	}
+	if s.Usage != nil {
+		meta.Usage = &schema.TokenUsage{
+			PromptTokens:     s.Usage.PromptTokens,
+			CompletionTokens: s.Usage.CompletionTokens,
+			TotalTokens:      s.Usage.PromptTokens + s.Usage.CompletionTokens,
+		}
+	}
+	return meta
+}
+
+// sleepContext 等待 d，ctx 结束时返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func init() {
+	RegisterAdapter(types.ProviderMock, NewMockAdapter)
+}
@@ -0,0 +1,197 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+func TestMockAdapter_ScriptedChat(t *testing.T) {
+	script := NewMockScript(
+		MockStep{Content: "hello", Usage: &MockUsage{PromptTokens: 3, CompletionTokens: 1}},
+		MockStep{ToolCalls: []MockToolCall{{Name: "weather", Arguments: `{"city":"Paris"}`}}},
+	)
+	RegisterMockScript("scripted", script)
+	defer UnregisterMockScript("scripted")
+
+	client, err := GetAdapter(types.ProviderMock, "scripted", options.WithSystemPrompt("be brief"))
+	if err != nil {
+		t.Fatalf("GetAdapter failed: %v", err)
+	}
+
+	resp, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if resp.Content != "hello" || resp.ResponseMeta.FinishReason != "stop" || resp.ResponseMeta.Usage.TotalTokens != 4 {
+		t.Errorf("unexpected response: %+v meta=%+v", resp, resp.ResponseMeta)
+	}
+
+	resp, err = client.Chat(context.Background(), []*schema.Message{schema.UserMessage("weather?")})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Name != "weather" || resp.ToolCalls[0].ID != "call_1" {
+		t.Errorf("unexpected tool calls: %+v", resp.ToolCalls)
+	}
+	if resp.ResponseMeta.FinishReason != "tool_calls" {
+		t.Errorf("finish reason = %q, want tool_calls", resp.ResponseMeta.FinishReason)
+	}
+
+	script.AssertCalls(t, 2)
+	script.AssertReceived(t, 0, schema.System, "be brief")
+	script.AssertReceived(t, 1, schema.User, "weather?")
+
+	if _, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("again")}); !errors.Is(err, ErrMockScriptExhausted) {
+		t.Errorf("expected ErrMockScriptExhausted, got %v", err)
+	}
+}
+
+func TestMockAdapter_Echo(t *testing.T) {
+	client, err := GetAdapter(types.ProviderMock, "echo")
+	if err != nil {
+		t.Fatalf("GetAdapter failed: %v", err)
+	}
+	result, err := client.Generate(context.Background(), "ping")
+	if err != nil || result != "ping" {
+		t.Errorf("Generate = %q, %v; want ping", result, err)
+	}
+}
+
+func TestMockAdapter_Stream(t *testing.T) {
+	script := NewMockScript(MockStep{
+		Chunks:       []string{"a", "b", "c"},
+		ChunkDelay:   time.Millisecond,
+		FinishReason: "length",
+		StreamError:  "connection dropped",
+	})
+	client, err := NewMockAdapterWithScript("stream", script)
+	if err != nil {
+		t.Fatalf("NewMockAdapterWithScript failed: %v", err)
+	}
+
+	stream, err := client.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("go")})
+	if err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	var (
+		content   strings.Builder
+		finish    string
+		streamErr error
+	)
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			streamErr = err
+			break
+		}
+		content.WriteString(msg.Content)
+		if msg.ResponseMeta != nil {
+			finish = msg.ResponseMeta.FinishReason
+		}
+	}
+	if content.String() != "abc" || finish != "length" {
+		t.Errorf("content = %q, finish = %q", content.String(), finish)
+	}
+	if streamErr == nil || streamErr.Error() != "connection dropped" {
+		t.Errorf("stream error = %v, want connection dropped", streamErr)
+	}
+}
+
+func TestMockAdapter_Errors(t *testing.T) {
+	script := NewMockScript(
+		MockStep{StatusCode: 503, Error: "overloaded"},
+		MockStep{Content: "recovered"},
+		MockStep{Error: "boom"},
+		MockStep{Expect: "weather", Content: "sunny"},
+	)
+	client, err := NewMockAdapterWithScript("errors", script, options.WithRetryBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewMockAdapterWithScript failed: %v", err)
+	}
+	ctx := context.Background()
+
+	// 503 可重试，第二步成功
+	if result, err := client.Generate(ctx, "q"); err != nil || result != "recovered" {
+		t.Errorf("Generate = %q, %v; want recovered", result, err)
+	}
+	if _, err := client.Generate(ctx, "q"); err == nil || err.Error() != "boom" {
+		t.Errorf("expected boom, got %v", err)
+	}
+	if _, err := client.Generate(ctx, "what time is it"); err == nil || !strings.Contains(err.Error(), `"weather"`) {
+		t.Errorf("expected expectation failure, got %v", err)
+	}
+	script.AssertCalls(t, 4)
+}
+
+func TestMockAdapter_LatencyHonorsContext(t *testing.T) {
+	script := NewMockScript(MockStep{Content: "slow", Latency: time.Second})
+	client, err := NewMockAdapterWithScript("slow", script, options.WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("NewMockAdapterWithScript failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.Generate(ctx, "q"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestParseMockScript(t *testing.T) {
+	script, err := ParseMockScript([]byte(`
+repeat: true
+steps:
+  - content: hi
+    latency: 1ms
+  - chunks: [a, b]
+    tool_calls:
+      - id: t1
+        name: search
+        arguments: '{"q":"go"}'
+  - status_code: 429
+    error: slow down
+`))
+	if err != nil {
+		t.Fatalf("ParseMockScript failed: %v", err)
+	}
+	if len(script.Steps) != 3 || !script.Repeat {
+		t.Fatalf("unexpected script: %+v", script)
+	}
+	if script.Steps[0].Latency != time.Millisecond || script.Steps[1].ToolCalls[0].ID != "t1" || script.Steps[2].StatusCode != 429 {
+		t.Errorf("unexpected steps: %+v", script.Steps)
+	}
+
+	client, err := NewMockAdapterWithScript("yaml", script, options.WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("NewMockAdapterWithScript failed: %v", err)
+	}
+	ctx := context.Background()
+	for i, want := range []string{"hi", "ab", "429"} {
+		result, err := client.Generate(ctx, fmt.Sprint(i))
+		got := result
+		if err != nil {
+			got = fmt.Sprint(StatusCodeOf(err))
+		}
+		if got != want {
+			t.Errorf("call %d = %q, want %q", i, got, want)
+		}
+	}
+	// repeat: 从头开始
+	if result, err := client.Generate(ctx, "again"); err != nil || result != "hi" {
+		t.Errorf("Generate = %q, %v; want hi", result, err)
+	}
+}
@@ -27,9 +27,16 @@ func NewOllamaAdapter(provider types.Provider, modelName string, opts ...options
 		Description: "Ollama本地模型: " + modelName,
 	}
 
+	// 创建共享HTTP客户端（代理、额外请求头、TLS、超时）
+	httpClient, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
 	// 创建Ollama配置
 	ollamaCfg := &ollama.ChatModelConfig{
-		Model: modelName,
+		Model:      modelName,
+		HTTPClient: httpClient,
 	}
 
 	// 设置基础URL
@@ -39,11 +46,6 @@ func NewOllamaAdapter(provider types.Provider, modelName string, opts ...options
 	}
 	ollamaCfg.BaseURL = baseURL
 
-	// 设置超时
-	if cfg.Timeout > 0 {
-		ollamaCfg.Timeout = cfg.Timeout
-	}
-
 	// 创建ChatModel
 	chatModel, err := ollama.NewChatModel(context.Background(), ollamaCfg)
 	if err != nil {
@@ -60,6 +62,11 @@ func NewOllamaAdapter(provider types.Provider, modelName string, opts ...options
 		},
 	}
 
+	// 绑定MCP工具
+	if err := adapter.bindTools(context.Background()); err != nil {
+		return nil, err
+	}
+
 	return adapter, nil
 }
 
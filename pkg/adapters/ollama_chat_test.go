@@ -0,0 +1,113 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+// newOllamaTestServer 创建模拟 Ollama /api/chat 的测试服务器，非流式返回 JSON，流式返回 NDJSON
+func newOllamaTestServer(t *testing.T, reply func(req map[string]interface{}) string, chunks []string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %s, want /api/chat", r.URL.Path)
+		}
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req["model"] != "qwen3-coder:30b" {
+			t.Errorf("model = %v, want qwen3-coder:30b", req["model"])
+		}
+		if stream, _ := req["stream"].(bool); stream {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			for _, chunk := range chunks {
+				fmt.Fprintf(w, `{"model":"qwen3-coder:30b","created_at":"2024-06-10T08:00:00Z","message":{"role":"assistant","content":%q},"done":false}`+"\n", chunk)
+			}
+			fmt.Fprint(w, `{"model":"qwen3-coder:30b","created_at":"2024-06-10T08:00:00Z","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":16,"eval_count":24}`+"\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, `{"model":"qwen3-coder:30b","created_at":"2024-06-10T08:00:00Z","message":{"role":"assistant","content":%q},"done":true,"done_reason":"stop","prompt_eval_count":16,"eval_count":24}`, reply(req))
+	}))
+}
+
+func TestOllamaAdapter_Chat(t *testing.T) {
+	server := newOllamaTestServer(t, func(req map[string]interface{}) string {
+		messages, _ := req["messages"].([]interface{})
+		if len(messages) == 3 {
+			// 多轮对话按顺序携带历史消息
+			assistant, _ := messages[1].(map[string]interface{})
+			if assistant["role"] != "assistant" || assistant["content"] != "Go是Google开发的开源编程语言。" {
+				t.Errorf("history message = %v", assistant)
+			}
+			return "语法简洁、编译快速，原生支持并发。"
+		}
+		return "Go是Google开发的开源编程语言。"
+	}, nil)
+	defer server.Close()
+
+	adapter, err := NewOllamaAdapter(types.ProviderOllama, "qwen3-coder:30b", options.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewOllamaAdapter() error: %v", err)
+	}
+
+	messages := []*schema.Message{schema.UserMessage("什么是Go语言？")}
+	resp, err := adapter.Chat(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if resp.Content != "Go是Google开发的开源编程语言。" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+
+	messages = append(messages, resp, schema.UserMessage("它有什么特点？"))
+	resp, err = adapter.Chat(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("multi-turn Chat() error: %v", err)
+	}
+	if resp.Content != "语法简洁、编译快速，原生支持并发。" {
+		t.Errorf("multi-turn Content = %q", resp.Content)
+	}
+}
+
+func TestOllamaAdapter_Stream(t *testing.T) {
+	server := newOllamaTestServer(t, nil, []string{"Hello", "! How can I", " help you today?"})
+	defer server.Close()
+
+	adapter, err := NewOllamaAdapter(types.ProviderOllama, "qwen3-coder:30b", options.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewOllamaAdapter() error: %v", err)
+	}
+
+	stream, err := adapter.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("Hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	defer stream.Close()
+
+	var content string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		content += msg.Content
+	}
+	if content != "Hello! How can I help you today?" {
+		t.Errorf("content = %q", content)
+	}
+}
@@ -0,0 +1,107 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cloudwego/eino/components/embedding"
+
+	"ai-bridge/pkg/options"
+)
+
+// OllamaEmbedder Ollama本地向量模型（实现 embedding.Embedder）
+// 调用 /api/embed 接口，可离线用于语义缓存等场景
+type OllamaEmbedder struct {
+	model   string
+	baseURL string
+	client  *http.Client
+}
+
+// NewOllamaEmbedder 创建Ollama向量模型，如 nomic-embed-text、bge-m3
+// 支持 options.WithBaseURL（默认 http://localhost:11434）、WithTimeout、WithProxy 等选项
+func NewOllamaEmbedder(model string, opts ...options.Option) (*OllamaEmbedder, error) {
+	cfg := options.ApplyOptions(opts...)
+
+	httpClient, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+
+	return &OllamaEmbedder{
+		model:   model,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  httpClient,
+	}, nil
+}
+
+// ollamaEmbedRequest /api/embed 请求体
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaEmbedResponse /api/embed 响应体
+type ollamaEmbedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// EmbedStrings 将文本转换为向量，返回顺序与输入一致
+func (e *OllamaEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	model := e.model
+	if o := embedding.GetCommonOptions(nil, opts...); o.Model != nil && *o.Model != "" {
+		model = *o.Model
+	}
+
+	data, err := json.Marshal(ollamaEmbedRequest{Model: model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ollama embed request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/api/embed", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ollama embed request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ollama embed response: %w", err)
+	}
+
+	var body ollamaEmbedResponse
+	jsonErr := json.Unmarshal(raw, &body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message := body.Error
+		if jsonErr != nil || message == "" {
+			message = strings.TrimSpace(string(raw))
+		}
+		return nil, &APIError{Provider: "ollama", StatusCode: resp.StatusCode, Message: message}
+	}
+	if jsonErr != nil {
+		return nil, fmt.Errorf("failed to decode ollama embed response: %w", jsonErr)
+	}
+	if len(body.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d inputs", len(body.Embeddings), len(texts))
+	}
+	return body.Embeddings, nil
+}
@@ -0,0 +1,56 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudwego/eino/components/embedding"
+
+	"ai-bridge/pkg/options"
+)
+
+func TestOllamaEmbedder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("path = %s, want /api/embed", r.URL.Path)
+		}
+		var req ollamaEmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Model != "bge-m3" {
+			http.Error(w, `{"error":"model \"`+req.Model+`\" not found"}`, http.StatusNotFound)
+			return
+		}
+		resp := ollamaEmbedResponse{}
+		for i := range req.Input {
+			resp.Embeddings = append(resp.Embeddings, []float64{float64(i), 1})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	e, err := NewOllamaEmbedder("bge-m3", options.WithBaseURL(srv.URL+"/"))
+	if err != nil {
+		t.Fatalf("NewOllamaEmbedder() error: %v", err)
+	}
+
+	vectors, err := e.EmbedStrings(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedStrings() error: %v", err)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 1 {
+		t.Errorf("EmbedStrings() = %v", vectors)
+	}
+
+	// 按次覆盖模型
+	_, err = e.EmbedStrings(context.Background(), []string{"a"}, embedding.WithModel("missing"))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != `model "missing" not found` {
+		t.Errorf("EmbedStrings() error = %v, want *APIError 404", err)
+	}
+}
@@ -24,6 +24,12 @@ func NewQWenAdapter(provider types.Provider, modelName string, opts ...options.O
 		return nil, fmt.Errorf("api key is required for QWen")
 	}
 
+	// 创建共享HTTP客户端（代理、额外请求头、TLS、超时）
+	httpClient, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
 	// 创建QWen ChatModel配置
 	qwenCfg := &qwen.ChatModelConfig{
 		APIKey:      cfg.APIKey,
@@ -31,6 +37,7 @@ func NewQWenAdapter(provider types.Provider, modelName string, opts ...options.O
 		MaxTokens:   &cfg.MaxTokens,
 		Temperature: &cfg.Temperature,
 		TopP:        &cfg.TopP,
+		HTTPClient:  httpClient,
 	}
 
 	// 设置基础URL（如果使用自定义端点）
@@ -56,6 +63,11 @@ func NewQWenAdapter(provider types.Provider, modelName string, opts ...options.O
 		},
 	}
 
+	// 绑定MCP工具
+	if err := adapter.bindTools(context.Background()); err != nil {
+		return nil, err
+	}
+
 	return adapter, nil
 }
 
@@ -0,0 +1,197 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/types"
+)
+
+// 默认退避参数
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// statusCodePattern 从错误信息中提取HTTP状态码
+// 兼容 go-openai（"status code: 429"）与 ollama（"429 Too Many Requests"）的错误格式
+var statusCodePattern = regexp.MustCompile(`(?:status code: |^)(\d{3})\b`)
+
+// IsRetryableError 判断错误是否可以重试
+// 可重试：429、5xx、连接重置/中断、网络超时；上下文取消不重试
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if code := StatusCodeOf(err); code != 0 {
+		return code == 429 || code >= 500
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset") || strings.Contains(msg, "broken pipe") ||
+		strings.Contains(msg, "unexpected EOF")
+}
+
+// StatusCodeOf 获取错误对应的HTTP状态码，无法识别时返回0
+func StatusCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	// 逐层检查包装的错误信息，避免外层前缀干扰匹配
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if m := statusCodePattern.FindStringSubmatch(e.Error()); m != nil {
+			code, _ := strconv.Atoi(m[1])
+			if code >= 400 && code < 600 {
+				return code
+			}
+		}
+	}
+	return 0
+}
+
+// retryAfterOf 获取厂商建议的重试等待时间
+func retryAfterOf(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// backoffDelay 计算第 attempt 次重试前的等待时间（指数退避 + 抖动）
+// 优先使用 Retry-After，但不超过最大等待时间
+func backoffDelay(attempt int, base, max time.Duration, err error) time.Duration {
+	if retryAfter := retryAfterOf(err); retryAfter > 0 {
+		if retryAfter > max {
+			return max
+		}
+		return retryAfter
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	// 抖动：在 [delay/2, delay) 区间内随机，避免多个客户端同时重试
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// retryPolicy 从配置中获取重试参数
+func (b *BaseAdapter) retryPolicy() (maxRetries int, base, max time.Duration) {
+	base, max = defaultRetryBaseDelay, defaultRetryMaxDelay
+	if b.Config == nil {
+		return 0, base, max
+	}
+	if b.Config.RetryBaseDelay > 0 {
+		base = b.Config.RetryBaseDelay
+	}
+	if b.Config.RetryMaxDelay > 0 {
+		max = b.Config.RetryMaxDelay
+	}
+	return b.Config.MaxRetries, base, max
+}
+
+// waitRetry 判断是否需要重试，需要时通知钩子并等待退避时间
+// 返回 false 表示不再重试
+func (b *BaseAdapter) waitRetry(ctx context.Context, attempt int, err error) bool {
+	maxRetries, base, max := b.retryPolicy()
+	retryable := IsRetryableError
+	if b.Config != nil && b.Config.RetryIf != nil {
+		retryable = b.Config.RetryIf
+	}
+	if attempt > maxRetries || !retryable(err) {
+		return false
+	}
+
+	event := types.RetryEvent{
+		Provider:   b.Provider,
+		Model:      b.ModelName,
+		Attempt:    attempt,
+		MaxRetries: maxRetries,
+		Delay:      backoffDelay(attempt, base, max, err),
+		Err:        err,
+	}
+	b.logRetry(ctx, event)
+	if b.Config.OnRetry != nil {
+		b.Config.OnRetry(ctx, event)
+	}
+
+	timer := time.NewTimer(event.Delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// generateWithRetry 调用 ChatModel.Generate，可重试的失败按退避策略重试
+func (b *BaseAdapter) generateWithRetry(ctx context.Context, messages []*schema.Message) (*schema.Message, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := b.ChatModel.Generate(ctx, messages, types.ModelOptionsFromContext(ctx)...)
+		if err == nil {
+			return resp, nil
+		}
+		if !b.waitRetry(ctx, attempt, err) {
+			return nil, err
+		}
+	}
+}
+
+// streamWithRetry 调用 ChatModel.Stream，仅在收到第一个流式块之前重试
+// 一旦有内容返回给调用方，后续错误直接透传，避免重复输出
+func (b *BaseAdapter) streamWithRetry(ctx context.Context, messages []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+	for attempt := 1; ; attempt++ {
+		stream, err := b.ChatModel.Stream(ctx, messages, types.ModelOptionsFromContext(ctx)...)
+		if err == nil {
+			var first *schema.Message
+			first, err = stream.Recv()
+			if err == nil {
+				return PrependChunk(first, stream), nil
+			}
+			stream.Close()
+			if err == io.EOF {
+				// 空流：返回一个立即结束的流
+				return schema.StreamReaderFromArray([]*schema.Message{}), nil
+			}
+		}
+		if !b.waitRetry(ctx, attempt, err) {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"rate limit", &APIError{StatusCode: 429}, true},
+		{"server error", fmt.Errorf("failed to create chat completion: %w", errors.New("error, status code: 503, status: 503 Service Unavailable, message: busy")), true},
+		{"ollama status", errors.New("502 Bad Gateway: upstream"), true},
+		{"bad request", &APIError{StatusCode: 400}, false},
+		{"unauthorized", errors.New("error, status code: 401, status: 401 Unauthorized, message: invalid key"), false},
+		{"connection reset", fmt.Errorf("post: %w", syscall.ECONNRESET), true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"canceled", context.Canceled, false},
+		{"other", errors.New("invalid model"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	for attempt := 1; attempt <= 5; attempt++ {
+		want := 100 * time.Millisecond << (attempt - 1)
+		if want > time.Second {
+			want = time.Second
+		}
+		got := backoffDelay(attempt, 100*time.Millisecond, time.Second, errors.New("x"))
+		if got < want/2 || got >= want {
+			t.Errorf("attempt %d: delay %v not in [%v, %v)", attempt, got, want/2, want)
+		}
+	}
+
+	// Retry-After 优先，但不超过最大等待时间
+	if got := backoffDelay(1, 100*time.Millisecond, time.Second, &APIError{RetryAfter: 300 * time.Millisecond}); got != 300*time.Millisecond {
+		t.Errorf("delay = %v, want Retry-After 300ms", got)
+	}
+	if got := backoffDelay(1, 100*time.Millisecond, time.Second, &APIError{RetryAfter: time.Minute}); got != time.Second {
+		t.Errorf("delay = %v, want capped 1s", got)
+	}
+}
+
+// newFlakyOpenAIServer 创建前 failures 次请求返回 status 的OpenAI兼容测试服务
+func newFlakyOpenAIServer(failures int32, status int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if n <= failures {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"error":{"message":"try again","type":"server_error"}}`)
+			return
+		}
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"model\":\"gpt-4o\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"ok\"}}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	return server, &requests
+}
+
+func TestBaseAdapter_Retry(t *testing.T) {
+	server, requests := newFlakyOpenAIServer(2, http.StatusTooManyRequests)
+	defer server.Close()
+
+	var events []types.RetryEvent
+	adapter, err := NewGPTAdapter(types.ProviderGPT, "gpt-4o",
+		options.WithAPIKey("test-key"),
+		options.WithBaseURL(server.URL),
+		options.WithMaxRetries(3),
+		options.WithRetryBackoff(time.Millisecond, 10*time.Millisecond),
+		options.WithOnRetry(func(ctx context.Context, event types.RetryEvent) {
+			events = append(events, event)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewGPTAdapter() error: %v", err)
+	}
+
+	resp, err := adapter.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if resp.Content != "ok" {
+		t.Errorf("Content = %q, want ok", resp.Content)
+	}
+	if *requests != 3 {
+		t.Errorf("requests = %d, want 3", *requests)
+	}
+	if len(events) != 2 || events[1].Attempt != 2 || events[1].MaxRetries != 3 {
+		t.Errorf("unexpected retry events: %+v", events)
+	}
+}
+
+func TestBaseAdapter_RetryExhausted(t *testing.T) {
+	server, requests := newFlakyOpenAIServer(10, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	adapter, err := NewGPTAdapter(types.ProviderGPT, "gpt-4o",
+		options.WithAPIKey("test-key"),
+		options.WithBaseURL(server.URL),
+		options.WithMaxRetries(2),
+		options.WithRetryBackoff(time.Millisecond, 10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewGPTAdapter() error: %v", err)
+	}
+
+	if _, err := adapter.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}); err == nil {
+		t.Fatal("expected error after retries exhausted")
+	}
+	if *requests != 3 {
+		t.Errorf("requests = %d, want 3 (1 + 2 retries)", *requests)
+	}
+}
+
+func TestBaseAdapter_NoRetryOnClientError(t *testing.T) {
+	server, requests := newFlakyOpenAIServer(10, http.StatusBadRequest)
+	defer server.Close()
+
+	adapter, err := NewGPTAdapter(types.ProviderGPT, "gpt-4o",
+		options.WithAPIKey("test-key"),
+		options.WithBaseURL(server.URL),
+		options.WithRetryBackoff(time.Millisecond, 10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewGPTAdapter() error: %v", err)
+	}
+
+	if _, err := adapter.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}); err == nil {
+		t.Fatal("expected error")
+	}
+	if *requests != 1 {
+		t.Errorf("requests = %d, want 1", *requests)
+	}
+}
+
+func TestBaseAdapter_RetryIf(t *testing.T) {
+	server, requests := newFlakyOpenAIServer(10, http.StatusTooManyRequests)
+	defer server.Close()
+
+	adapter, err := NewGPTAdapter(types.ProviderGPT, "gpt-4o",
+		options.WithAPIKey("test-key"),
+		options.WithBaseURL(server.URL),
+		options.WithMaxRetries(3),
+		options.WithRetryBackoff(time.Millisecond, 10*time.Millisecond),
+		options.WithRetryIf(func(err error) bool { return false }),
+	)
+	if err != nil {
+		t.Fatalf("NewGPTAdapter() error: %v", err)
+	}
+
+	if _, err := adapter.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}); err == nil {
+		t.Fatal("expected error")
+	}
+	if *requests != 1 {
+		t.Errorf("requests = %d, want 1", *requests)
+	}
+}
+
+func TestBaseAdapter_StreamRetry(t *testing.T) {
+	server, requests := newFlakyOpenAIServer(1, http.StatusBadGateway)
+	defer server.Close()
+
+	adapter, err := NewGPTAdapter(types.ProviderGPT, "gpt-4o",
+		options.WithAPIKey("test-key"),
+		options.WithBaseURL(server.URL),
+		options.WithRetryBackoff(time.Millisecond, 10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewGPTAdapter() error: %v", err)
+	}
+
+	stream, err := adapter.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	defer stream.Close()
+
+	var content string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		content += msg.Content
+	}
+	if content != "ok" {
+		t.Errorf("content = %q, want ok", content)
+	}
+	if *requests != 2 {
+		t.Errorf("requests = %d, want 2", *requests)
+	}
+}
+
+func TestStreamWithRetry_NoRetryAfterFirstChunk(t *testing.T) {
+	var calls int32
+	b := &BaseAdapter{
+		Config: &types.Config{MaxRetries: 3, RetryBaseDelay: time.Millisecond},
+		ChatModel: &scriptedStreamModel{stream: func() *schema.StreamReader[*schema.Message] {
+			atomic.AddInt32(&calls, 1)
+			sr, sw := schema.Pipe[*schema.Message](2)
+			sw.Send(schema.AssistantMessage("部分", nil), nil)
+			sw.Send(nil, &APIError{StatusCode: 503})
+			sw.Close()
+			return sr
+		}},
+	}
+
+	stream, err := b.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	defer stream.Close()
+
+	if msg, err := stream.Recv(); err != nil || msg.Content != "部分" {
+		t.Fatalf("first Recv() = %v, %v", msg, err)
+	}
+	var apiErr *APIError
+	if _, err := stream.Recv(); !errors.As(err, &apiErr) {
+		t.Errorf("expected mid-stream error to pass through, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("stream calls = %d, want 1", calls)
+	}
+}
+
+func TestStreamWithRetry_ForwardsModelOptions(t *testing.T) {
+	m := &scriptedStreamModel{stream: func() *schema.StreamReader[*schema.Message] {
+		return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("ok", nil)})
+	}}
+	b := &BaseAdapter{Config: &types.Config{}, ChatModel: m}
+
+	ctx := types.WithModelOptions(context.Background(), model.WithTemperature(0), model.WithMaxTokens(8))
+	stream, err := b.ChatStream(ctx, []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	stream.Close()
+
+	got := model.GetCommonOptions(nil, m.opts...)
+	if got.Temperature == nil || *got.Temperature != 0 || got.MaxTokens == nil || *got.MaxTokens != 8 {
+		t.Errorf("model options = %+v, want per-call options from context", got)
+	}
+}
+
+// scriptedStreamModel 测试用ChatModel，Stream 返回脚本化的流
+type scriptedStreamModel struct {
+	stream func() *schema.StreamReader[*schema.Message]
+	opts   []model.Option // 最近一次 Stream 收到的选项
+}
+
+func (m *scriptedStreamModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *scriptedStreamModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	m.opts = opts
+	return m.stream(), nil
+}
+
+func (m *scriptedStreamModel) BindTools(tools []*schema.ToolInfo) error {
+	return nil
+}
@@ -0,0 +1,75 @@
+package adapters
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// sseEvent Server-Sent Events 事件
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+// sseReader Server-Sent Events 读取器
+type sseReader struct {
+	scanner *bufio.Scanner
+}
+
+// newSSEReader 创建SSE读取器
+func newSSEReader(r io.Reader) *sseReader {
+	scanner := bufio.NewScanner(r)
+	// 单个事件可能包含较大的JSON，放宽行长度限制
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+	return &sseReader{scanner: scanner}
+}
+
+// Next 读取下一个事件，流结束时返回 io.EOF
+func (r *sseReader) Next() (*sseEvent, error) {
+	var event sseEvent
+	var data []string
+	hasField := false
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+
+		// 空行表示一个事件结束
+		if line == "" {
+			if hasField {
+				event.Data = strings.Join(data, "\n")
+				return &event, nil
+			}
+			continue
+		}
+
+		// 注释行
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.Event = value
+			hasField = true
+		case "data":
+			data = append(data, value)
+			hasField = true
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// 流结束时可能没有结尾空行
+	if hasField {
+		event.Data = strings.Join(data, "\n")
+		return &event, nil
+	}
+
+	return nil, io.EOF
+}
@@ -0,0 +1,80 @@
+package adapters
+
+import (
+	"errors"
+	"io"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// ErrStreamClosed 调用方在流结束前关闭了流（RelayStream 的 onEnd 收到此错误）
+var ErrStreamClosed = errors.New("stream closed by receiver")
+
+// relayToken 转发协程等待调用方读取时发送的占位块，不会返回给调用方
+var relayToken = &schema.Message{}
+
+// RelayStream 包装流式响应：调用方每次 Recv 时才从 src 读取下一个块，调用方关闭返回的流时立即关闭 src
+// 转发协程空闲时阻塞在向调用方的发送上而不是上游读取上，因此不会因上游空闲而持有连接和协程。
+// onChunk 在每个块返回给调用方之前调用；onEnd 在结束时调用一次（先于调用方收到 io.EOF 或错误）：
+// 正常结束为 nil，上游出错为该错误，调用方提前关闭为 ErrStreamClosed。两者均可为 nil。
+func RelayStream(src *schema.StreamReader[*schema.Message], onChunk func(*schema.Message), onEnd func(error)) *schema.StreamReader[*schema.Message] {
+	return pullStream(src.Recv, src.Close, onChunk, onEnd)
+}
+
+// PrependChunk 将已读取的第一个块重新拼接到流的开头，返回的流关闭时会关闭 rest
+func PrependChunk(first *schema.Message, rest *schema.StreamReader[*schema.Message]) *schema.StreamReader[*schema.Message] {
+	pending := true
+	return pullStream(func() (*schema.Message, error) {
+		if pending {
+			pending = false
+			return first, nil
+		}
+		return rest.Recv()
+	}, rest.Close, nil, nil)
+}
+
+// pullStream 由读取函数创建流：仅在调用方 Recv 时调用 recv，结束或调用方关闭后调用 closeFn
+func pullStream(recv func() (*schema.Message, error), closeFn func(), onChunk func(*schema.Message), onEnd func(error)) *schema.StreamReader[*schema.Message] {
+	sr, sw := schema.Pipe[*schema.Message](0)
+	go func() {
+		defer sw.Close()
+		defer closeFn()
+
+		end := func(err error) {
+			if onEnd != nil {
+				onEnd(err)
+			}
+		}
+		for {
+			// 等待调用方读取；调用方关闭后立即返回
+			if closed := sw.Send(relayToken, nil); closed {
+				end(ErrStreamClosed)
+				return
+			}
+			msg, err := recv()
+			if err == io.EOF {
+				end(nil)
+				return
+			}
+			if err != nil {
+				end(err)
+				sw.Send(nil, err)
+				return
+			}
+			if onChunk != nil {
+				onChunk(msg)
+			}
+			if closed := sw.Send(msg, nil); closed {
+				end(ErrStreamClosed)
+				return
+			}
+		}
+	}()
+
+	return schema.StreamReaderWithConvert(sr, func(msg *schema.Message) (*schema.Message, error) {
+		if msg == relayToken {
+			return nil, schema.ErrNoValue
+		}
+		return msg, nil
+	})
+}
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"testing"
@@ -197,3 +198,96 @@ func TestStreamConfigOptions(t *testing.T) {
 
 	fmt.Printf("✓ 流式配置选项测试通过\n")
 }
+
+func TestRelayStream(t *testing.T) {
+	src := schema.StreamReaderFromArray([]*schema.Message{
+		schema.AssistantMessage("a", nil),
+		schema.AssistantMessage("b", nil),
+	})
+	var chunks []string
+	var endErr error
+	ended := 0
+	sr := RelayStream(src, func(msg *schema.Message) {
+		chunks = append(chunks, msg.Content)
+	}, func(err error) {
+		ended++
+		endErr = err
+	})
+	defer sr.Close()
+
+	var got []string
+	for {
+		msg, err := sr.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		got = append(got, msg.Content)
+	}
+	if fmt.Sprint(got) != "[a b]" || fmt.Sprint(chunks) != "[a b]" {
+		t.Errorf("got %v, onChunk %v", got, chunks)
+	}
+	// onEnd 在调用方收到 io.EOF 之前调用
+	if ended != 1 || endErr != nil {
+		t.Errorf("onEnd called %d times with %v", ended, endErr)
+	}
+}
+
+func TestRelayStream_Error(t *testing.T) {
+	upstream, sw := schema.Pipe[*schema.Message](2)
+	boom := errors.New("boom")
+	sw.Send(schema.AssistantMessage("a", nil), nil)
+	sw.Send(nil, boom)
+	sw.Close()
+
+	var endErr error
+	sr := RelayStream(upstream, nil, func(err error) { endErr = err })
+	defer sr.Close()
+	if msg, err := sr.Recv(); err != nil || msg.Content != "a" {
+		t.Fatalf("Recv() = %v, %v", msg, err)
+	}
+	if _, err := sr.Recv(); !errors.Is(err, boom) {
+		t.Errorf("Recv() error = %v, want boom", err)
+	}
+	if !errors.Is(endErr, boom) {
+		t.Errorf("onEnd error = %v, want boom", endErr)
+	}
+}
+
+func TestRelayStream_CloseWhileUpstreamIdle(t *testing.T) {
+	// 上游发送一个块后保持空闲
+	upstream, sw := schema.Pipe[*schema.Message](0)
+	upstreamClosed := make(chan struct{})
+	go func() {
+		defer sw.Close()
+		sw.Send(schema.AssistantMessage("a", nil), nil)
+		for !sw.Send(schema.AssistantMessage("late", nil), nil) {
+		}
+		close(upstreamClosed)
+	}()
+
+	ended := make(chan error, 1)
+	sr := PrependChunk(schema.AssistantMessage("first", nil), RelayStream(upstream, nil, func(err error) { ended <- err }))
+	for _, want := range []string{"first", "a"} {
+		if msg, err := sr.Recv(); err != nil || msg.Content != want {
+			t.Fatalf("Recv() = %v, %v, want %s", msg, err, want)
+		}
+	}
+	sr.Close()
+
+	select {
+	case err := <-ended:
+		if !errors.Is(err, ErrStreamClosed) {
+			t.Errorf("onEnd error = %v, want ErrStreamClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("onEnd not called after close")
+	}
+	select {
+	case <-upstreamClosed:
+	case <-time.After(time.Second):
+		t.Fatal("upstream not closed after receiver closed")
+	}
+}
@@ -0,0 +1,116 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+// newWeatherTool 创建测试用的天气工具
+func newWeatherTool() tool.BaseTool {
+	return utils.NewTool(&schema.ToolInfo{
+		Name: "weather",
+		Desc: "查询天气",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"city": {Type: schema.String, Desc: "城市", Required: true},
+		}),
+	}, func(ctx context.Context, input *schema.ToolCall) (string, error) {
+		return "晴", nil
+	})
+}
+
+func TestToolInfos(t *testing.T) {
+	infos, err := ToolInfos(context.Background(), []tool.BaseTool{newWeatherTool(), nil})
+	if err != nil {
+		t.Fatalf("ToolInfos() error: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != "weather" {
+		t.Errorf("unexpected tool infos: %+v", infos)
+	}
+}
+
+func TestGPTAdapter_ToolCalling(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+
+		tools, ok := req["tools"].([]interface{})
+		if !ok || len(tools) != 1 {
+			t.Errorf("expected 1 tool in request, got %v", req["tools"])
+			return
+		}
+		fn := tools[0].(map[string]interface{})["function"].(map[string]interface{})
+		if fn["name"] != "weather" {
+			t.Errorf("tool name = %v, want weather", fn["name"])
+		}
+
+		// 工具结果需要以 tool 角色回传
+		messages := req["messages"].([]interface{})
+		last := messages[len(messages)-1].(map[string]interface{})
+		if len(messages) == 3 && (last["role"] != "tool" || last["tool_call_id"] != "call_1") {
+			t.Errorf("unexpected tool message: %v", last)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(messages) == 1 {
+			fmt.Fprint(w, `{
+				"id": "chatcmpl-1", "object": "chat.completion", "model": "gpt-4o",
+				"choices": [{"index": 0, "finish_reason": "tool_calls", "message": {
+					"role": "assistant", "content": "",
+					"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "weather", "arguments": "{\"city\":\"北京\"}"}}]
+				}}],
+				"usage": {"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15}
+			}`)
+			return
+		}
+		fmt.Fprint(w, `{
+			"id": "chatcmpl-2", "object": "chat.completion", "model": "gpt-4o",
+			"choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "北京晴"}}],
+			"usage": {"prompt_tokens": 20, "completion_tokens": 3, "total_tokens": 23}
+		}`)
+	}))
+	defer server.Close()
+
+	adapter, err := NewGPTAdapter(types.ProviderGPT, "gpt-4o",
+		options.WithAPIKey("test-key"),
+		options.WithBaseURL(server.URL),
+		options.WithTools(newWeatherTool()),
+	)
+	if err != nil {
+		t.Fatalf("NewGPTAdapter() error: %v", err)
+	}
+
+	messages := []*schema.Message{schema.UserMessage("北京天气")}
+	resp, err := adapter.Chat(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID != "call_1" {
+		t.Fatalf("expected tool call call_1, got %+v", resp.ToolCalls)
+	}
+	if id := RequestIDOf(resp); id != "chatcmpl-1" {
+		t.Errorf("RequestIDOf() = %q, want chatcmpl-1", id)
+	}
+
+	messages = append(messages, resp, schema.ToolMessage("晴", resp.ToolCalls[0].ID))
+	final, err := adapter.Chat(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Chat() with tool result error: %v", err)
+	}
+	if final.Content != "北京晴" {
+		t.Errorf("Content = %q, want 北京晴", final.Content)
+	}
+}
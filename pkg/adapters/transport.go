@@ -0,0 +1,179 @@
+package adapters
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"ai-bridge/pkg/types"
+)
+
+// HeaderOrganization OpenAI组织ID请求头
+const HeaderOrganization = "OpenAI-Organization"
+
+// NewHTTPClient 根据配置创建所有适配器共用的HTTP客户端
+// 应用以下配置：
+//   - Proxy: HTTP/HTTPS/SOCKS5代理（未设置时使用 HTTP_PROXY/HTTPS_PROXY 环境变量）
+//   - ExtraHeaders: 附加到每个请求的请求头
+//   - CAFile / CertFile / KeyFile / InsecureSkipVerify: 自定义CA与mTLS
+//   - TransportWrapper: 包装底层传输（如录制/回放）
+//   - Timeout: 建连、TLS握手和等待响应头的超时时间（不限制响应体读取，
+//     流式响应可持续输出；整体时长由请求 ctx 的 deadline 控制）
+//   - EnableLog + LogPayload: 以Debug级别记录完整请求/响应（密钥脱敏）
+//
+// 请求上下文中的链路信息通过全局 OpenTelemetry propagator 写入请求头（如 traceparent）。
+func NewHTTPClient(cfg *types.Config) (*http.Client, error) {
+	if cfg == nil {
+		cfg = types.DefaultConfig()
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// 设置超时（不使用 http.Client.Timeout，避免截断长时间的流式响应）
+	if cfg.Timeout > 0 {
+		dialer := &net.Dialer{Timeout: cfg.Timeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = cfg.Timeout
+		transport.ResponseHeaderTimeout = cfg.Timeout
+	}
+
+	// 设置代理
+	if cfg.Proxy != "" {
+		proxyURL, err := parseProxyURL(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	// 设置TLS
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	var rt http.RoundTripper = transport
+	if cfg.TransportWrapper != nil {
+		rt = cfg.TransportWrapper(rt)
+	}
+	rt = &traceTransport{base: rt}
+	if logger := newLogger(cfg); logger != nil && cfg.LogPayload {
+		rt = &payloadTransport{base: rt, logger: logger, secrets: []string{cfg.APIKey}}
+	}
+	if len(cfg.ExtraHeaders) > 0 {
+		rt = &headerTransport{base: rt, headers: cfg.ExtraHeaders}
+	}
+
+	return &http.Client{Transport: rt}, nil
+}
+
+// parseProxyURL 解析代理地址，未指定协议时默认为 http
+func parseProxyURL(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %s", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", proxy)
+	}
+	return proxyURL, nil
+}
+
+// newTLSConfig 根据配置创建TLS配置，未设置任何TLS选项时返回 nil
+func newTLSConfig(cfg *types.Config) (*tls.Config, error) {
+	if cfg.CAFile == "" && cfg.CertFile == "" && cfg.KeyFile == "" && !cfg.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	// 自定义CA（在系统证书的基础上追加）
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in ca file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// mTLS客户端证书
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, fmt.Errorf("both cert file and key file are required for mTLS")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// headerTransport 为每个请求附加额外请求头
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper 不应修改原请求
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// traceTransport 将请求上下文中的链路信息写入请求头，传递给上游厂商
+type traceTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	carrier := propagation.HeaderCarrier(http.Header{})
+	otel.GetTextMapPropagator().Inject(req.Context(), carrier)
+	if len(carrier) == 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	// RoundTripper 不应修改原请求
+	req = req.Clone(req.Context())
+	for k, v := range carrier {
+		req.Header[k] = v
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,246 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/mcp"
+	"ai-bridge/pkg/telemetry"
+	"ai-bridge/pkg/types"
+)
+
+// RunStopReason Agent循环结束原因
+type RunStopReason string
+
+const (
+	RunStopFinalAnswer   RunStopReason = "final_answer"   // 模型给出最终答案
+	RunStopMaxIterations RunStopReason = "max_iterations" // 达到最大轮数
+	RunStopTokenBudget   RunStopReason = "token_budget"   // 超出token预算
+)
+
+// RunOption Agent循环选项
+type RunOption func(*RunConfig)
+
+// RunConfig Agent循环配置
+type RunConfig struct {
+	MaxIterations     int            // 最大模型调用轮数（默认10）
+	TokenBudget       int            // 累计token预算，0表示不限制
+	ParallelToolCalls bool           // 同一轮的多个工具调用是否并行执行（默认true）
+	ClientOptions     []ClientOption // 每轮调用Chat时使用的选项
+}
+
+// DefaultRunConfig 返回默认Agent循环配置
+func DefaultRunConfig() *RunConfig {
+	return &RunConfig{
+		MaxIterations:     10,
+		ParallelToolCalls: true,
+	}
+}
+
+// WithMaxIterations 设置最大模型调用轮数
+func WithMaxIterations(n int) RunOption {
+	return func(c *RunConfig) {
+		c.MaxIterations = n
+	}
+}
+
+// WithTokenBudget 设置累计token预算
+func WithTokenBudget(tokens int) RunOption {
+	return func(c *RunConfig) {
+		c.TokenBudget = tokens
+	}
+}
+
+// WithParallelToolCalls 设置是否并行执行同一轮的工具调用
+func WithParallelToolCalls(parallel bool) RunOption {
+	return func(c *RunConfig) {
+		c.ParallelToolCalls = parallel
+	}
+}
+
+// WithRunClientOptions 设置每轮调用Chat时使用的选项
+func WithRunClientOptions(opts ...ClientOption) RunOption {
+	return func(c *RunConfig) {
+		c.ClientOptions = append(c.ClientOptions, opts...)
+	}
+}
+
+// ToolExecution 单次工具执行记录
+type ToolExecution struct {
+	Call     schema.ToolCall // 模型请求的工具调用
+	Result   string          // 工具返回结果（回传给模型的内容）
+	Error    string          // 执行错误（如有）
+	Duration time.Duration   // 执行耗时
+}
+
+// RunStep Agent循环中的单轮记录
+type RunStep struct {
+	Iteration      int             // 轮次（从1开始）
+	Response       *schema.Message // 模型响应
+	ToolExecutions []ToolExecution // 本轮执行的工具
+	Usage          types.Usage     // 本轮token使用情况
+	Duration       time.Duration   // 本轮耗时（含工具执行）
+}
+
+// RunResult Agent循环结果
+type RunResult struct {
+	Content    string            // 最终答案内容
+	Messages   []*schema.Message // 完整对话记录（含工具调用与结果）
+	Steps      []RunStep         // 每轮执行轨迹
+	Usage      types.Usage       // 累计token使用情况
+	StopReason RunStopReason     // 结束原因
+}
+
+// Run 执行自动工具调用循环
+// 调用模型 → 执行请求的工具 → 追加工具结果 → 再次调用，直到模型给出最终答案、
+// 达到最大轮数或超出token预算。
+// 注意：客户端需要已绑定相同的工具（如通过 SDK.CreateSDKClientWithTools(registry.ToEinoTools())）
+// 支持选项：
+//   - WithMaxIterations(n): 最大模型调用轮数（默认10）
+//   - WithTokenBudget(tokens): 累计token预算
+//   - WithParallelToolCalls(bool): 是否并行执行工具（默认true）
+//   - WithRunClientOptions(opts...): 每轮调用Chat的选项
+func (c *SDKClient) Run(ctx context.Context, messages []*schema.Message, registry *mcp.ToolRegistry, opts ...RunOption) (*RunResult, error) {
+	cfg := DefaultRunConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if registry == nil {
+		return nil, fmt.Errorf("tool registry is required")
+	}
+	if cfg.MaxIterations <= 0 {
+		return nil, fmt.Errorf("max iterations must be positive")
+	}
+
+	result := &RunResult{
+		Messages:   append([]*schema.Message{}, messages...),
+		StopReason: RunStopMaxIterations,
+	}
+
+	for i := 1; i <= cfg.MaxIterations; i++ {
+		start := time.Now()
+
+		chatResult, err := c.Chat(ctx, result.Messages, cfg.ClientOptions...)
+		if err != nil {
+			return result, fmt.Errorf("iteration %d: %w", i, err)
+		}
+
+		resp := chatResult.Message
+		if resp == nil {
+			resp = schema.AssistantMessage(chatResult.Content, chatResult.ToolCalls)
+		}
+		result.Messages = append(result.Messages, resp)
+
+		step := RunStep{
+			Iteration: i,
+			Response:  resp,
+			Usage:     usageFromMessage(resp),
+		}
+		addUsage(&result.Usage, step.Usage)
+
+		// 没有工具调用即为最终答案
+		if len(resp.ToolCalls) == 0 {
+			step.Duration = time.Since(start)
+			result.Steps = append(result.Steps, step)
+			result.Content = resp.Content
+			result.StopReason = RunStopFinalAnswer
+			return result, nil
+		}
+
+		// 超出预算时不再执行本轮请求的工具（避免产生副作用）
+		if cfg.TokenBudget > 0 && result.Usage.TotalTokens >= cfg.TokenBudget {
+			step.Duration = time.Since(start)
+			result.Steps = append(result.Steps, step)
+			result.StopReason = RunStopTokenBudget
+			return result, nil
+		}
+
+		step.ToolExecutions = executeToolCalls(ctx, registry, resp.ToolCalls, cfg.ParallelToolCalls)
+		for _, exec := range step.ToolExecutions {
+			result.Messages = append(result.Messages, schema.ToolMessage(exec.Result, exec.Call.ID, schema.WithToolName(exec.Call.Function.Name)))
+		}
+		step.Duration = time.Since(start)
+		result.Steps = append(result.Steps, step)
+	}
+
+	return result, nil
+}
+
+// executeToolCalls 执行一轮中的所有工具调用，结果顺序与调用顺序一致
+func executeToolCalls(ctx context.Context, registry *mcp.ToolRegistry, calls []schema.ToolCall, parallel bool) []ToolExecution {
+	executions := make([]ToolExecution, len(calls))
+
+	if !parallel || len(calls) == 1 {
+		for i, call := range calls {
+			executions[i] = executeToolCall(ctx, registry, call)
+		}
+		return executions
+	}
+
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call schema.ToolCall) {
+			defer wg.Done()
+			executions[i] = executeToolCall(ctx, registry, call)
+		}(i, call)
+	}
+	wg.Wait()
+
+	return executions
+}
+
+// executeToolCall 执行单个工具调用，并记录 execute_tool span
+// 工具不存在或执行失败时，错误信息作为工具结果回传给模型，便于模型自行修正
+func executeToolCall(ctx context.Context, registry *mcp.ToolRegistry, call schema.ToolCall) ToolExecution {
+	start := time.Now()
+	exec := ToolExecution{Call: call}
+
+	ctx, end := telemetry.StartToolSpan(ctx, call.Function.Name, call.ID)
+	result, err := invokeTool(ctx, registry, call)
+	end(err)
+	if err != nil {
+		exec.Error = err.Error()
+		exec.Result = "Error: " + err.Error()
+	} else {
+		exec.Result = result
+	}
+	exec.Duration = time.Since(start)
+
+	return exec
+}
+
+// invokeTool 从注册表查找并调用工具
+func invokeTool(ctx context.Context, registry *mcp.ToolRegistry, call schema.ToolCall) (result string, err error) {
+	t, ok := registry.Get(call.Function.Name)
+	if !ok {
+		return "", fmt.Errorf("tool %s not found", call.Function.Name)
+	}
+	if t.Handler == nil {
+		return "", fmt.Errorf("tool %s has no handler", call.Function.Name)
+	}
+
+	arguments := call.Function.Arguments
+	if arguments == "" {
+		arguments = "{}"
+	}
+	params, err := mcp.ParseToolArguments(arguments)
+	if err != nil {
+		return "", err
+	}
+
+	// 参数校验失败和工具实现的panic都作为工具结果返回给模型，不中断整个循环
+	return t.Call(ctx, params)
+}
+
+// addUsage 累加token使用情况
+func addUsage(dst *types.Usage, u types.Usage) {
+	dst.PromptTokens += u.PromptTokens
+	dst.CompletionTokens += u.CompletionTokens
+	dst.TotalTokens += u.TotalTokens
+	dst.CachedTokens += u.CachedTokens
+}
@@ -0,0 +1,206 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/mcp"
+)
+
+// toolCallMessage 构造带工具调用的助手消息
+func toolCallMessage(totalTokens int, calls ...schema.ToolCall) *schema.Message {
+	msg := schema.AssistantMessage("", calls)
+	msg.ResponseMeta = &schema.ResponseMeta{Usage: &schema.TokenUsage{TotalTokens: totalTokens}}
+	return msg
+}
+
+// newCall 构造工具调用
+func newCall(id, name, args string) schema.ToolCall {
+	return schema.ToolCall{ID: id, Type: "function", Function: schema.FunctionCall{Name: name, Arguments: args}}
+}
+
+// newTestRegistry 创建测试用工具注册表
+func newTestRegistry(calls *int32) *mcp.ToolRegistry {
+	registry := mcp.NewToolRegistry()
+	registry.Register(mcp.NewTool("weather", "查询天气", nil, func(ctx context.Context, params map[string]interface{}) (string, error) {
+		atomic.AddInt32(calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return fmt.Sprintf("%v晴", params["city"]), nil
+	}))
+	registry.Register(mcp.NewTool("fail", "总是失败", nil, func(ctx context.Context, params map[string]interface{}) (string, error) {
+		return "", errors.New("boom")
+	}))
+	registry.Register(mcp.NewTool("forecast", "天气预报",
+		mcp.CreateParameterSchema(map[string]interface{}{"city": mcp.CreateStringProperty("城市")}, []string{"city"}),
+		func(ctx context.Context, params map[string]interface{}) (string, error) {
+			return "", errors.New("should not be called")
+		}))
+	return registry
+}
+
+func TestSDKClientRun(t *testing.T) {
+	var calls int32
+	fake := newFakeBridge(
+		fakeResponse{msg: toolCallMessage(10,
+			newCall("call_1", "weather", `{"city":"北京"}`),
+			newCall("call_2", "weather", `{"city":"上海"}`),
+			newCall("call_3", "weather", `{"city":"广州"}`),
+		)},
+		fakeResponse{msg: &schema.Message{
+			Role:         schema.Assistant,
+			Content:      "都是晴天",
+			ResponseMeta: &schema.ResponseMeta{Usage: &schema.TokenUsage{PromptTokens: 15, CompletionTokens: 5}},
+		}},
+	)
+	client := NewSDKClient(fake)
+
+	start := time.Now()
+	result, err := client.Run(context.Background(), []*schema.Message{schema.UserMessage("三个城市天气")}, newTestRegistry(&calls))
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if result.StopReason != RunStopFinalAnswer || result.Content != "都是晴天" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if calls != 3 {
+		t.Errorf("tool calls = %d, want 3", calls)
+	}
+	// 三个工具并行执行，耗时应明显小于串行
+	if elapsed := time.Since(start); elapsed > 140*time.Millisecond {
+		t.Errorf("tools should run in parallel, took %v", elapsed)
+	}
+
+	// user + assistant + 3*tool + assistant
+	if len(result.Messages) != 6 {
+		t.Fatalf("transcript length = %d, want 6", len(result.Messages))
+	}
+	for i, want := range []string{"北京晴", "上海晴", "广州晴"} {
+		msg := result.Messages[2+i]
+		if msg.Role != schema.Tool || msg.Content != want || msg.ToolCallID != fmt.Sprintf("call_%d", i+1) {
+			t.Errorf("tool message %d = %+v, want %s", i, msg, want)
+		}
+	}
+
+	// 第二轮请求应包含工具结果
+	if got := len(fake.calls[1]); got != 5 {
+		t.Errorf("second request messages = %d, want 5", got)
+	}
+
+	if len(result.Steps) != 2 || len(result.Steps[0].ToolExecutions) != 3 {
+		t.Fatalf("unexpected steps: %+v", result.Steps)
+	}
+	if result.Usage.TotalTokens != 30 {
+		t.Errorf("TotalTokens = %d, want 30", result.Usage.TotalTokens)
+	}
+}
+
+func TestSDKClientRun_ToolError(t *testing.T) {
+	var calls int32
+	fake := newFakeBridge(
+		fakeResponse{msg: toolCallMessage(0,
+			newCall("call_1", "fail", `{}`),
+			newCall("call_2", "missing", `{}`),
+			newCall("call_3", "forecast", `{"days":3}`),
+		)},
+		fakeResponse{msg: schema.AssistantMessage("无法完成", nil)},
+	)
+	client := NewSDKClient(fake)
+
+	result, err := client.Run(context.Background(), []*schema.Message{schema.UserMessage("hi")}, newTestRegistry(&calls),
+		WithParallelToolCalls(false))
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	execs := result.Steps[0].ToolExecutions
+	if execs[0].Error != "boom" {
+		t.Errorf("execution error = %q, want boom", execs[0].Error)
+	}
+	if !strings.Contains(execs[1].Error, "not found") {
+		t.Errorf("execution error = %q, want not found", execs[1].Error)
+	}
+	// 参数校验失败时不调用工具
+	if !strings.Contains(execs[2].Error, "city: required") {
+		t.Errorf("execution error = %q, want validation error", execs[2].Error)
+	}
+	// 错误信息作为工具结果回传给模型
+	if !strings.Contains(result.Messages[2].Content, "boom") {
+		t.Errorf("tool message = %q, want error text", result.Messages[2].Content)
+	}
+}
+
+func TestSDKClientRun_Limits(t *testing.T) {
+	loop := func() []fakeResponse {
+		var responses []fakeResponse
+		for i := 0; i < 5; i++ {
+			responses = append(responses, fakeResponse{msg: toolCallMessage(100, newCall(fmt.Sprintf("call_%d", i), "weather", `{"city":"北京"}`))})
+		}
+		return responses
+	}
+
+	t.Run("max iterations", func(t *testing.T) {
+		var calls int32
+		fake := newFakeBridge(loop()...)
+		result, err := NewSDKClient(fake).Run(context.Background(), []*schema.Message{schema.UserMessage("hi")}, newTestRegistry(&calls),
+			WithMaxIterations(2))
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if result.StopReason != RunStopMaxIterations || fake.callCount() != 2 || len(result.Steps) != 2 {
+			t.Errorf("stop reason = %s, calls = %d, steps = %d", result.StopReason, fake.callCount(), len(result.Steps))
+		}
+	})
+
+	t.Run("token budget", func(t *testing.T) {
+		var calls int32
+		fake := newFakeBridge(loop()...)
+		result, err := NewSDKClient(fake).Run(context.Background(), []*schema.Message{schema.UserMessage("hi")}, newTestRegistry(&calls),
+			WithTokenBudget(250))
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if result.StopReason != RunStopTokenBudget || fake.callCount() != 3 {
+			t.Errorf("stop reason = %s, calls = %d", result.StopReason, fake.callCount())
+		}
+		// 第三轮超出预算，其请求的工具不执行
+		if n := atomic.LoadInt32(&calls); n != 2 {
+			t.Errorf("tool calls = %d, want 2", n)
+		}
+	})
+
+	t.Run("token budget exceeded before tools", func(t *testing.T) {
+		var calls int32
+		fake := newFakeBridge(loop()...)
+		result, err := NewSDKClient(fake).Run(context.Background(), []*schema.Message{schema.UserMessage("hi")}, newTestRegistry(&calls),
+			WithTokenBudget(50))
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if result.StopReason != RunStopTokenBudget || fake.callCount() != 1 || len(result.Steps) != 1 {
+			t.Errorf("stop reason = %s, calls = %d, steps = %d", result.StopReason, fake.callCount(), len(result.Steps))
+		}
+		if n := atomic.LoadInt32(&calls); n != 0 || len(result.Steps[0].ToolExecutions) != 0 {
+			t.Errorf("tool handler called %d times after budget was exceeded", n)
+		}
+	})
+
+	t.Run("model error", func(t *testing.T) {
+		var calls int32
+		fake := newFakeBridge(loop()[0], fakeResponse{err: errors.New("upstream down")})
+		result, err := NewSDKClient(fake).Run(context.Background(), []*schema.Message{schema.UserMessage("hi")}, newTestRegistry(&calls))
+		if err == nil || !strings.Contains(err.Error(), "upstream down") {
+			t.Fatalf("expected upstream error, got %v", err)
+		}
+		if result == nil || len(result.Steps) != 1 {
+			t.Errorf("partial result should keep completed steps: %+v", result)
+		}
+	})
+}
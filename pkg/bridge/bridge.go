@@ -9,9 +9,16 @@ import (
 // NewAIClient 创建AI客户端
 // provider: AI厂商类型
 // modelName: 模型名称
-// opts: 配置选项
+// opts: 配置选项（通过 options.WithMiddleware 添加的中间件包装在适配器外层）
 func NewAIClient(provider types.Provider, modelName string, opts ...options.Option) (types.AIBridge, error) {
-	return adapters.GetAdapter(provider, modelName, opts...)
+	client, err := adapters.GetAdapter(provider, modelName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if cfg := options.ApplyOptions(opts...); len(cfg.Middlewares) > 0 {
+		return NewMiddlewareClient(client, cfg.Middlewares...), nil
+	}
+	return client, nil
 }
 
 // MustNewAIClient 创建AI客户端（出错时panic）
@@ -0,0 +1,252 @@
+package bridge
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/cache"
+	"ai-bridge/pkg/types"
+)
+
+// ExtraKeyCacheHit schema.Message.Extra 中标记响应来自缓存的键
+const ExtraKeyCacheHit = "cache_hit"
+
+// CachedClient 响应缓存客户端
+// 缓存键由厂商、模型、归一化后的消息、采样参数和工具组成；
+// 流式调用命中缓存时以单个块的 StreamReader 回放。
+// 可通过 WithCacheBypass 或 cache.WithBypass 按次跳过缓存。
+type CachedClient struct {
+	inner  types.AIBridge
+	store  cache.Cache
+	ttl    time.Duration
+	params cache.Params
+}
+
+// configurer 可获取客户端配置的适配器
+type configurer interface {
+	GetConfig() *types.Config
+}
+
+// NewCachedClient 创建响应缓存客户端，ttl <= 0 表示永不过期
+// params 为影响响应的调用参数；inner 为适配器时可传 nil，自动从适配器配置中读取
+func NewCachedClient(inner types.AIBridge, store cache.Cache, ttl time.Duration, params *cache.Params) (*CachedClient, error) {
+	c := &CachedClient{inner: inner, store: store, ttl: ttl}
+	if params != nil {
+		c.params = *params
+	} else if cfg, ok := inner.(configurer); ok {
+		p, err := cache.ParamsFromConfig(context.Background(), cfg.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+		c.params = p
+	}
+	return c, nil
+}
+
+// Chat 执行对话（非流式），命中缓存时不调用厂商
+func (c *CachedClient) Chat(ctx context.Context, messages []*schema.Message) (*schema.Message, error) {
+	key, ok := c.lookupKey(ctx, "chat", messages)
+	if ok {
+		if msg := c.get(ctx, key); msg != nil {
+			return msg, nil
+		}
+	}
+
+	resp, err := c.inner.Chat(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		c.set(ctx, key, resp)
+	}
+	return resp, nil
+}
+
+// ChatStream 执行对话（流式）
+// 命中缓存时回放缓存的完整消息；未命中时在流正常结束后写入缓存
+func (c *CachedClient) ChatStream(ctx context.Context, messages []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+	key, ok := c.lookupKey(ctx, "chat", messages)
+	if !ok {
+		return c.inner.ChatStream(ctx, messages)
+	}
+	if msg := c.get(ctx, key); msg != nil {
+		return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+	}
+
+	stream, err := c.inner.ChatStream(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []*schema.Message
+	return adapters.RelayStream(stream, func(msg *schema.Message) {
+		chunks = append(chunks, msg)
+	}, func(err error) {
+		// 出错或调用方提前关闭时响应不完整，不写入缓存
+		if err != nil || len(chunks) == 0 {
+			return
+		}
+		if full, err := schema.ConcatMessages(chunks); err == nil {
+			c.set(ctx, key, full)
+		}
+	}), nil
+}
+
+// Generate 生成文本（简化接口）
+func (c *CachedClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return c.generate(ctx, prompt, c.inner.Generate)
+}
+
+// GenerateStream 生成文本（流式）
+func (c *CachedClient) GenerateStream(ctx context.Context, prompt string) (string, error) {
+	return c.generate(ctx, prompt, c.inner.GenerateStream)
+}
+
+// GetModelInfo 获取模型信息
+func (c *CachedClient) GetModelInfo() *types.ModelInfo {
+	return c.inner.GetModelInfo()
+}
+
+// generate Generate/GenerateStream 的缓存逻辑（两者结果相同，共享缓存）
+func (c *CachedClient) generate(ctx context.Context, prompt string, call func(context.Context, string) (string, error)) (string, error) {
+	key, ok := c.lookupKey(ctx, "generate", []*schema.Message{schema.UserMessage(prompt)})
+	if ok {
+		if msg := c.get(ctx, key); msg != nil {
+			return msg.Content, nil
+		}
+	}
+
+	result, err := call(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		c.set(ctx, key, schema.AssistantMessage(result, nil))
+	}
+	return result, nil
+}
+
+// lookupKey 计算缓存键，跳过缓存或无法计算时返回 false
+// 带有按次模型选项的调用不使用缓存（选项无法计入缓存键）
+func (c *CachedClient) lookupKey(ctx context.Context, op string, messages []*schema.Message) (string, bool) {
+	if cache.IsBypassed(ctx) || len(types.ModelOptionsFromContext(ctx)) > 0 {
+		return "", false
+	}
+	in := cache.KeyInput{Op: op, Messages: messages, Params: c.params}
+	if info := c.inner.GetModelInfo(); info != nil {
+		in.Provider, in.Model = info.Provider, info.Name
+	}
+	key, err := cache.Key(in)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// get 读取缓存，命中时返回带缓存标记的消息副本；读取失败视为未命中
+func (c *CachedClient) get(ctx context.Context, key string) *schema.Message {
+	entry, ok, err := c.store.Get(ctx, key)
+	if err != nil || !ok || entry.Message == nil {
+		return nil
+	}
+	return cacheHitMessage(entry.Message)
+}
+
+// cacheHitMessage 复制缓存的消息并添加缓存标记
+// 命中缓存不产生token消耗，副本不携带原始的使用情况，避免被指标、链路和成本统计重复计算。
+func cacheHitMessage(cached *schema.Message) *schema.Message {
+	msg := cloneMessage(cached)
+	if msg.ResponseMeta != nil {
+		msg.ResponseMeta.Usage = nil
+	}
+	if msg.Extra == nil {
+		msg.Extra = make(map[string]any, 1)
+	}
+	msg.Extra[ExtraKeyCacheHit] = true
+	return msg
+}
+
+// cloneMessage 深拷贝消息，调用方修改工具调用、多模态内容等不会影响缓存中的条目
+func cloneMessage(src *schema.Message) *schema.Message {
+	msg := *src
+	msg.Extra = maps.Clone(src.Extra)
+	if src.ResponseMeta != nil {
+		meta := *src.ResponseMeta
+		meta.Usage = clonePtr(meta.Usage)
+		if meta.LogProbs != nil {
+			meta.LogProbs = &schema.LogProbs{Content: slices.Clone(meta.LogProbs.Content)}
+		}
+		msg.ResponseMeta = &meta
+	}
+	if src.ToolCalls != nil {
+		msg.ToolCalls = make([]schema.ToolCall, len(src.ToolCalls))
+		for i, call := range src.ToolCalls {
+			call.Index = clonePtr(call.Index)
+			call.Extra = maps.Clone(call.Extra)
+			msg.ToolCalls[i] = call
+		}
+	}
+	if src.MultiContent != nil {
+		msg.MultiContent = make([]schema.ChatMessagePart, len(src.MultiContent))
+		for i, part := range src.MultiContent {
+			part.ImageURL = clonePtr(part.ImageURL)
+			part.AudioURL = clonePtr(part.AudioURL)
+			part.VideoURL = clonePtr(part.VideoURL)
+			part.FileURL = clonePtr(part.FileURL)
+			msg.MultiContent[i] = part
+		}
+	}
+	if src.UserInputMultiContent != nil {
+		msg.UserInputMultiContent = make([]schema.MessageInputPart, len(src.UserInputMultiContent))
+		for i, part := range src.UserInputMultiContent {
+			part.Image = clonePtr(part.Image)
+			part.Audio = clonePtr(part.Audio)
+			part.Video = clonePtr(part.Video)
+			part.File = clonePtr(part.File)
+			part.Extra = maps.Clone(part.Extra)
+			msg.UserInputMultiContent[i] = part
+		}
+	}
+	if src.AssistantGenMultiContent != nil {
+		msg.AssistantGenMultiContent = make([]schema.MessageOutputPart, len(src.AssistantGenMultiContent))
+		for i, part := range src.AssistantGenMultiContent {
+			part.Image = clonePtr(part.Image)
+			part.Audio = clonePtr(part.Audio)
+			part.Video = clonePtr(part.Video)
+			part.Extra = maps.Clone(part.Extra)
+			msg.AssistantGenMultiContent[i] = part
+		}
+	}
+	return &msg
+}
+
+// clonePtr 复制指针指向的值，nil 原样返回
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// set 写入缓存，被内容过滤拦截的响应不缓存；写入失败时忽略
+func (c *CachedClient) set(ctx context.Context, key string, msg *schema.Message) {
+	if msg == nil || isContentFiltered(msg) {
+		return
+	}
+	_ = c.store.Set(ctx, key, &cache.Entry{Message: cloneMessage(msg), CreatedAt: time.Now()}, c.ttl)
+}
+
+// CacheHit 判断响应是否来自缓存
+func CacheHit(msg *schema.Message) bool {
+	if msg == nil {
+		return false
+	}
+	hit, _ := msg.Extra[ExtraKeyCacheHit].(bool)
+	return hit
+}
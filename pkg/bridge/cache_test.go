@@ -0,0 +1,170 @@
+package bridge
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/cache"
+	"ai-bridge/pkg/types"
+)
+
+// readStream 读取流式响应直到结束，返回拼接后的内容
+func readStream(t *testing.T, stream *StreamReader) string {
+	t.Helper()
+	defer stream.Close()
+
+	var content string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return content
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		content += msg.Content
+	}
+}
+
+func TestCachedClientChat(t *testing.T) {
+	firstMsg := schema.AssistantMessage("first", nil)
+	firstMsg.ResponseMeta = &schema.ResponseMeta{
+		FinishReason: adapters.FinishReasonStop,
+		Usage:        &schema.TokenUsage{PromptTokens: 5, CompletionTokens: 3, TotalTokens: 8},
+	}
+	fake := newNamedFakeBridge(types.ProviderGPT, "gpt-4o",
+		fakeResponse{msg: firstMsg},
+		fakeResponse{msg: schema.AssistantMessage("second", nil)},
+	)
+	client, err := NewCachedClient(fake, cache.NewMemoryCache(10), 0, &cache.Params{Temperature: 0})
+	if err != nil {
+		t.Fatalf("NewCachedClient() error: %v", err)
+	}
+	sdk := NewSDKClient(client)
+	messages := []*schema.Message{schema.UserMessage("hi")}
+
+	first, err := sdk.Chat(context.Background(), messages, WithStream(false))
+	if err != nil || first.Cached {
+		t.Fatalf("first Chat() = %+v, %v", first, err)
+	}
+	second, err := sdk.Chat(context.Background(), messages, WithStream(false))
+	if err != nil || second.Content != "first" || !second.Cached {
+		t.Fatalf("second Chat() = %+v, %v, want cached first", second, err)
+	}
+	// 命中缓存不计token消耗，结束原因保留
+	if second.Usage != (types.Usage{}) || second.FinishReason != adapters.FinishReasonStop {
+		t.Errorf("cached Chat() Usage = %+v, FinishReason = %q, want zero usage and stop", second.Usage, second.FinishReason)
+	}
+	if first.Usage.TotalTokens != 8 {
+		t.Errorf("first Chat() Usage = %+v, want 8 total tokens", first.Usage)
+	}
+
+	// 按次跳过缓存
+	bypassed, err := sdk.Chat(context.Background(), messages, WithStream(false), WithCacheBypass(true))
+	if err != nil || bypassed.Content != "second" || bypassed.Cached {
+		t.Fatalf("bypassed Chat() = %+v, %v", bypassed, err)
+	}
+	if fake.callCount() != 2 {
+		t.Errorf("inner calls = %d, want 2", fake.callCount())
+	}
+}
+
+func TestCachedClientStream(t *testing.T) {
+	fake := newNamedFakeBridge(types.ProviderGPT, "gpt-4o",
+		fakeResponse{chunks: []*schema.Message{schema.AssistantMessage("你好", nil), schema.AssistantMessage("，世界", nil)}},
+	)
+	store := cache.NewMemoryCache(10)
+	client, _ := NewCachedClient(fake, store, 0, &cache.Params{})
+	sdk := NewSDKClient(client)
+	messages := []*schema.Message{schema.UserMessage("hi")}
+
+	for i, wantCached := range []bool{false, true} {
+		stream, err := sdk.ChatStream(context.Background(), messages)
+		if err != nil {
+			t.Fatalf("ChatStream(%d) error: %v", i, err)
+		}
+		content := readStream(t, stream)
+		if content != "你好，世界" {
+			t.Fatalf("stream %d content = %q", i, content)
+		}
+		if result := stream.Result(); result == nil || result.Cached != wantCached {
+			t.Errorf("stream %d cached = %v, want %v", i, result, wantCached)
+		}
+	}
+	if fake.callCount() != 1 {
+		t.Errorf("inner calls = %d, want 1", fake.callCount())
+	}
+
+	// 非流式调用共享流式写入的缓存
+	resp, err := client.Chat(context.Background(), messages)
+	if err != nil || !CacheHit(resp) || resp.Content != "你好，世界" {
+		t.Errorf("Chat() = %v, %v, want cache hit", resp, err)
+	}
+}
+
+func TestCachedClientReturnsCopies(t *testing.T) {
+	original := schema.AssistantMessage("", []schema.ToolCall{{ID: "call_1", Function: schema.FunctionCall{Name: "search", Arguments: `{"q":"go"}`}}})
+	original.MultiContent = []schema.ChatMessagePart{{Type: schema.ChatMessagePartTypeImageURL, ImageURL: &schema.ChatMessageImageURL{URL: "https://example.com/a.png"}}}
+	fake := newNamedFakeBridge(types.ProviderGPT, "gpt-4o", fakeResponse{msg: original})
+	client, _ := NewCachedClient(fake, cache.NewMemoryCache(10), 0, &cache.Params{Temperature: 0})
+	messages := []*schema.Message{schema.UserMessage("hi")}
+
+	// 修改未命中时返回的响应和命中时返回的副本，都不影响缓存条目
+	first, _ := client.Chat(context.Background(), messages)
+	first.ToolCalls[0].Function.Arguments = "first"
+	hit, err := client.Chat(context.Background(), messages)
+	if err != nil || !CacheHit(hit) {
+		t.Fatalf("second Chat() = %+v, %v, want cache hit", hit, err)
+	}
+	hit.ToolCalls[0].Function.Arguments = "hit"
+	hit.MultiContent[0].ImageURL.URL = "hit"
+
+	again, _ := client.Chat(context.Background(), messages)
+	if got := again.ToolCalls[0].Function.Arguments; got != `{"q":"go"}` {
+		t.Errorf("cached Arguments = %q, want unchanged", got)
+	}
+	if got := again.MultiContent[0].ImageURL.URL; got != "https://example.com/a.png" {
+		t.Errorf("cached ImageURL = %q, want unchanged", got)
+	}
+	if fake.callCount() != 1 {
+		t.Errorf("inner calls = %d, want 1", fake.callCount())
+	}
+}
+
+func TestCachedClientSkipsFilteredResponses(t *testing.T) {
+	blocked := &schema.Message{
+		Role:         schema.Assistant,
+		ResponseMeta: &schema.ResponseMeta{FinishReason: adapters.FinishReasonContentFilter},
+	}
+	fake := newNamedFakeBridge(types.ProviderGPT, "gpt-4o",
+		fakeResponse{msg: blocked},
+		fakeResponse{msg: schema.AssistantMessage("ok", nil)},
+	)
+	client, _ := NewCachedClient(fake, cache.NewMemoryCache(10), 0, &cache.Params{})
+
+	client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	resp, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil || resp.Content != "ok" {
+		t.Errorf("Chat() = %v, %v, filtered response should not be cached", resp, err)
+	}
+}
+
+func TestSDKCreateClientWithCache(t *testing.T) {
+	sdk := NewSDK(&SDKConfig{GPT: ProviderConfig{APIKey: "sk-test", Temperature: 0.2, Cache: cache.NewMemoryCache(10)}})
+
+	client, err := sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	if err != nil {
+		t.Fatalf("CreateClient() error: %v", err)
+	}
+	cached, ok := client.(*CachedClient)
+	if !ok {
+		t.Fatalf("CreateClient() = %T, want *CachedClient", client)
+	}
+	if cached.params.Temperature != 0.2 {
+		t.Errorf("params = %+v, want temperature from provider config", cached.params)
+	}
+}
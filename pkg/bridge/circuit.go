@@ -0,0 +1,120 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/circuit"
+	"ai-bridge/pkg/ratelimit"
+	"ai-bridge/pkg/types"
+)
+
+// CircuitBreakerClient 熔断客户端
+// 熔断器打开时直接返回包装了 circuit.ErrOpen 的错误（可配合 FallbackClient 降级）。
+// 只有厂商故障（可重试错误、超时）计入错误率，请求参数错误、密钥相关错误（401/403/429）等不计入。
+type CircuitBreakerClient struct {
+	inner   types.AIBridge
+	breaker *circuit.Breaker
+}
+
+// NewCircuitBreakerClient 创建熔断客户端
+func NewCircuitBreakerClient(inner types.AIBridge, breaker *circuit.Breaker) *CircuitBreakerClient {
+	return &CircuitBreakerClient{inner: inner, breaker: breaker}
+}
+
+// Breaker 获取熔断器
+func (c *CircuitBreakerClient) Breaker() *circuit.Breaker {
+	return c.breaker
+}
+
+// Chat 执行对话（非流式）
+func (c *CircuitBreakerClient) Chat(ctx context.Context, messages []*schema.Message) (*schema.Message, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := c.inner.Chat(ctx, messages)
+	done(providerFailure(err), time.Since(start))
+	return resp, err
+}
+
+// ChatStream 执行对话（流式）
+// 耗时按首个流式块的到达时间计算，流中途的错误同样计入错误率
+func (c *CircuitBreakerClient) ChatStream(ctx context.Context, messages []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	stream, err := c.inner.ChatStream(ctx, messages)
+	if err != nil {
+		done(providerFailure(err), time.Since(start))
+		return nil, err
+	}
+
+	var firstChunk time.Duration
+	return adapters.RelayStream(stream, func(msg *schema.Message) {
+		if firstChunk == 0 {
+			firstChunk = time.Since(start)
+		}
+	}, func(err error) {
+		if firstChunk == 0 {
+			firstChunk = time.Since(start)
+		}
+		if errors.Is(err, adapters.ErrStreamClosed) {
+			err = nil
+		}
+		done(providerFailure(err), firstChunk)
+	}), nil
+}
+
+// Generate 生成文本（简化接口）
+func (c *CircuitBreakerClient) Generate(ctx context.Context, prompt string) (string, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	result, err := c.inner.Generate(ctx, prompt)
+	done(providerFailure(err), time.Since(start))
+	return result, err
+}
+
+// GenerateStream 生成文本（流式）
+func (c *CircuitBreakerClient) GenerateStream(ctx context.Context, prompt string) (string, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	result, err := c.inner.GenerateStream(ctx, prompt)
+	done(providerFailure(err), time.Since(start))
+	return result, err
+}
+
+// GetModelInfo 获取模型信息
+func (c *CircuitBreakerClient) GetModelInfo() *types.ModelInfo {
+	return c.inner.GetModelInfo()
+}
+
+// providerFailure 过滤出代表厂商故障的错误，其他错误返回 nil（不计入熔断错误率）
+// 调用方取消、客户端侧限流和单个密钥的错误（无效或被限流）不是厂商故障，
+// 否则一个密钥的 429 就会打开整个厂商/模型共用的熔断器
+func providerFailure(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ratelimit.ErrRateLimited) || isKeyError(err) {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || adapters.IsRetryableError(err) {
+		return err
+	}
+	return nil
+}
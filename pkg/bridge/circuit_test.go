@@ -0,0 +1,118 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/circuit"
+	"ai-bridge/pkg/types"
+)
+
+func TestCircuitBreakerClient(t *testing.T) {
+	gpt := newNamedFakeBridge(types.ProviderGPT, "gpt-4o",
+		fakeResponse{err: &adapters.APIError{StatusCode: 400}}, // 请求错误不计为失败
+		fakeResponse{err: &adapters.APIError{StatusCode: 503}},
+		fakeResponse{err: &adapters.APIError{StatusCode: 502}},
+	)
+	deepseek := newNamedFakeBridge(types.ProviderDeepseek, "deepseek-chat",
+		fakeResponse{msg: schema.AssistantMessage("ok", nil)},
+	)
+	breaker := circuit.NewBreaker("gpt/gpt-4o", circuit.Settings{MinRequests: 3, FailureRate: 0.6, OpenTimeout: time.Hour})
+	client := NewCircuitBreakerClient(gpt, breaker)
+	messages := []*schema.Message{schema.UserMessage("hi")}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Chat(context.Background(), messages); err == nil {
+			t.Fatalf("Chat(%d) expected error", i)
+		}
+	}
+	if s := breaker.Status(); s.State != circuit.StateOpen || s.Failures != 2 {
+		t.Fatalf("Status() = %+v, want open after 2 provider failures", s)
+	}
+
+	// 熔断后不再调用下游，FallbackClient 降级到下一个厂商
+	resp, err := NewFallbackClient(client, deepseek).Chat(context.Background(), messages)
+	if err != nil || resp.Content != "ok" {
+		t.Fatalf("fallback Chat() = %v, %v", resp, err)
+	}
+	if gpt.callCount() != 3 {
+		t.Errorf("gpt calls = %d, want 3", gpt.callCount())
+	}
+	if _, err := client.ChatStream(context.Background(), messages); !errors.Is(err, circuit.ErrOpen) {
+		t.Errorf("ChatStream() error = %v, want ErrOpen", err)
+	}
+}
+
+func TestCircuitBreakerClientIgnoresKeyErrors(t *testing.T) {
+	var responses []fakeResponse
+	for i := 0; i < 10; i++ {
+		responses = append(responses, fakeResponse{err: &adapters.APIError{StatusCode: 429}})
+	}
+	responses = append(responses, fakeResponse{err: &adapters.APIError{StatusCode: 401}})
+	gpt := newNamedFakeBridge(types.ProviderGPT, "gpt-4o", responses...)
+	breaker := circuit.NewBreaker("gpt/gpt-4o", circuit.Settings{MinRequests: 3, FailureRate: 0.5, OpenTimeout: time.Hour})
+	client := NewCircuitBreakerClient(gpt, breaker)
+
+	for i := 0; i < len(responses); i++ {
+		if _, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}); errors.Is(err, circuit.ErrOpen) {
+			t.Fatalf("Chat(%d) rejected by open breaker", i)
+		}
+	}
+	if s := breaker.Status(); s.State != circuit.StateClosed || s.Failures != 0 {
+		t.Errorf("Status() = %+v, want closed without failures", s)
+	}
+}
+
+func TestHealthProber(t *testing.T) {
+	gpt := newNamedFakeBridge(types.ProviderGPT, "gpt-4o",
+		fakeResponse{err: &adapters.APIError{StatusCode: 503}},
+		fakeResponse{msg: schema.AssistantMessage("pong", nil)},
+	)
+	breaker := circuit.NewBreaker("gpt/gpt-4o", circuit.Settings{MinRequests: 1, OpenTimeout: time.Hour})
+	prober := NewHealthProber(time.Hour, time.Second)
+	prober.Add("gpt/gpt-4o", NewCircuitBreakerClient(gpt, breaker))
+
+	prober.ProbeOnce(context.Background())
+	result := prober.Results()["gpt/gpt-4o"]
+	if result.Healthy || result.Error == "" {
+		t.Errorf("first probe = %+v, want unhealthy", result)
+	}
+	if breaker.State() != circuit.StateOpen {
+		t.Fatalf("State() = %s, want open after failed probe", breaker.State())
+	}
+
+	// 熔断期间探测绕过熔断器，成功后进入半开
+	prober.Start(context.Background())
+	prober.Stop()
+	if result := prober.Results()["gpt/gpt-4o"]; !result.Healthy {
+		t.Errorf("second probe = %+v, want healthy", result)
+	}
+	if breaker.State() != circuit.StateHalfOpen {
+		t.Errorf("State() = %s, want half_open after successful probe", breaker.State())
+	}
+}
+
+func TestSDKCircuitBreaker(t *testing.T) {
+	sdk := NewSDK(&SDKConfig{GPT: ProviderConfig{APIKey: "sk-test", CircuitBreaker: &circuit.Settings{}}})
+
+	a, err := sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	if err != nil {
+		t.Fatalf("CreateClient() error: %v", err)
+	}
+	b, _ := sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	cbA, ok := a.(*CircuitBreakerClient)
+	if !ok {
+		t.Fatalf("CreateClient() = %T, want *CircuitBreakerClient", a)
+	}
+	if cbA.Breaker() != b.(*CircuitBreakerClient).Breaker() {
+		t.Error("clients for the same model should share a breaker")
+	}
+	if statuses := sdk.CircuitStatus(); len(statuses) != 1 || statuses[0].Name != "gpt/gpt-4o" {
+		t.Errorf("CircuitStatus() = %+v", statuses)
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"io"
 	"time"
 
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/cache"
+	"ai-bridge/pkg/ratelimit"
 	"ai-bridge/pkg/types"
 )
 
@@ -19,6 +23,29 @@ type ClientConfig struct {
 	Stream       bool              // 是否启用流式返回（默认true）
 	Timeout      time.Duration     // 超时时间（默认60s）
 	SystemPrompt string            // 系统提示词（可选，覆盖适配器配置）
+
+	RateLimitMode ratelimit.Mode // 限流模式（可选，覆盖 RateLimitedClient 的默认模式）
+	CacheBypass   bool           // 是否跳过响应缓存（不读取也不写入）
+	CacheTags     []string       // 本次调用写入语义缓存的条目标签（可选）
+
+	ModelOptions []model.Option // 本次调用的模型选项（可选，如 model.WithTemperature，覆盖适配器配置）
+}
+
+// callContext 将按次生效的调用选项（限流模式、缓存控制、模型选项）写入上下文，供下游包装客户端读取
+func (c *ClientConfig) callContext(ctx context.Context) context.Context {
+	if c.RateLimitMode != "" {
+		ctx = ratelimit.WithMode(ctx, c.RateLimitMode)
+	}
+	if c.CacheBypass {
+		ctx = cache.WithBypass(ctx)
+	}
+	if len(c.CacheTags) > 0 {
+		ctx = cache.WithTags(ctx, c.CacheTags...)
+	}
+	if len(c.ModelOptions) > 0 {
+		ctx = types.WithModelOptions(ctx, c.ModelOptions...)
+	}
+	return ctx
 }
 
 // DefaultClientConfig 返回默认客户端配置
@@ -58,6 +85,36 @@ func WithSystemPrompt(prompt string) ClientOption {
 	}
 }
 
+// WithRateLimitMode 设置本次调用的限流模式
+// ratelimit.ModeWait 阻塞等待额度，ratelimit.ModeFailFast 额度不足时立即返回 *ratelimit.LimitError
+func WithRateLimitMode(mode ratelimit.Mode) ClientOption {
+	return func(c *ClientConfig) {
+		c.RateLimitMode = mode
+	}
+}
+
+// WithCacheBypass 设置本次调用是否跳过响应缓存（不读取也不写入）
+func WithCacheBypass(bypass bool) ClientOption {
+	return func(c *ClientConfig) {
+		c.CacheBypass = bypass
+	}
+}
+
+// WithCacheTags 为本次调用写入语义缓存的条目附加标签，之后可通过 SemanticCache.InvalidateTag 失效
+func WithCacheTags(tags ...string) ClientOption {
+	return func(c *ClientConfig) {
+		c.CacheTags = tags
+	}
+}
+
+// WithModelOptions 设置本次调用的模型选项（如 model.WithTemperature、model.WithMaxTokens）
+// 设置后本次调用不读取也不写入响应缓存和语义缓存
+func WithModelOptions(opts ...model.Option) ClientOption {
+	return func(c *ClientConfig) {
+		c.ModelOptions = append(c.ModelOptions, opts...)
+	}
+}
+
 // SDKClient SDK客户端包装器
 type SDKClient struct {
 	inner types.AIBridge
@@ -74,6 +131,10 @@ func NewSDKClient(inner types.AIBridge) *SDKClient {
 //   - WithStream(bool): 是否启用流式（默认true）
 //   - WithTimeout(duration): 设置超时时间（默认60s）
 //   - WithSystemPrompt(prompt): 设置系统提示词（可选，支持{{question}}宏）
+//   - WithRateLimitMode(mode): 设置限流模式（可选）
+//   - WithCacheBypass(bool): 跳过响应缓存（可选）
+//   - WithCacheTags(tags...): 语义缓存条目标签（可选）
+//   - WithModelOptions(opts...): 本次调用的模型选项（可选）
 func (c *SDKClient) Generate(ctx context.Context, prompt string, opts ...ClientOption) (string, error) {
 	cfg := DefaultClientConfig()
 	for _, opt := range opts {
@@ -86,6 +147,7 @@ func (c *SDKClient) Generate(ctx context.Context, prompt string, opts ...ClientO
 		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
 		defer cancel()
 	}
+	ctx = cfg.callContext(ctx)
 
 	// 构建消息列表
 	messages := make([]*schema.Message, 0)
@@ -136,6 +198,10 @@ func (c *SDKClient) Generate(ctx context.Context, prompt string, opts ...ClientO
 //   - WithHistory(history): 设置对话历史
 //   - WithTimeout(duration): 设置超时时间（默认60s）
 //   - WithSystemPrompt(prompt): 设置系统提示词（可选，支持{{question}}宏）
+//   - WithRateLimitMode(mode): 设置限流模式（可选）
+//   - WithCacheBypass(bool): 跳过响应缓存（可选）
+//   - WithCacheTags(tags...): 语义缓存条目标签（可选）
+//   - WithModelOptions(opts...): 本次调用的模型选项（可选）
 func (c *SDKClient) GenerateStream(ctx context.Context, prompt string, opts ...ClientOption) (*StreamReader, error) {
 	cfg := DefaultClientConfig()
 	for _, opt := range opts {
@@ -148,6 +214,7 @@ func (c *SDKClient) GenerateStream(ctx context.Context, prompt string, opts ...C
 		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
 		_ = cancel // 流式读取完成后自动取消
 	}
+	ctx = cfg.callContext(ctx)
 
 	// 构建消息列表
 	messages := make([]*schema.Message, 0)
@@ -163,12 +230,13 @@ func (c *SDKClient) GenerateStream(ctx context.Context, prompt string, opts ...C
 	}
 	messages = append(messages, schema.UserMessage(prompt))
 
+	start := time.Now()
 	stream, err := c.inner.ChatStream(ctx, messages)
 	if err != nil {
 		return nil, err
 	}
 
-	return &StreamReader{inner: stream}, nil
+	return newStreamReader(stream, c.inner.GetModelInfo(), start), nil
 }
 
 // Chat 对话（支持Option）
@@ -176,18 +244,25 @@ func (c *SDKClient) GenerateStream(ctx context.Context, prompt string, opts ...C
 //   - WithStream(bool): 是否启用流式（默认true）
 //   - WithTimeout(duration): 设置超时时间（默认60s）
 //   - WithSystemPrompt(prompt): 设置系统提示词（可选）
+//   - WithRateLimitMode(mode): 设置限流模式（可选）
+//   - WithCacheBypass(bool): 跳过响应缓存（可选）
+//   - WithCacheTags(tags...): 语义缓存条目标签（可选）
+//   - WithModelOptions(opts...): 本次调用的模型选项（可选）
 func (c *SDKClient) Chat(ctx context.Context, messages []*schema.Message, opts ...ClientOption) (*ChatResult, error) {
 	cfg := DefaultClientConfig()
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
+	start := time.Now()
+
 	// 设置超时
 	if cfg.Timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
 		defer cancel()
 	}
+	ctx = cfg.callContext(ctx)
 
 	// 添加系统提示词（如果指定）
 	if cfg.SystemPrompt != "" {
@@ -212,7 +287,7 @@ func (c *SDKClient) Chat(ctx context.Context, messages []*schema.Message, opts .
 		}
 
 		// 收集流式响应
-		var fullContent string
+		var chunks []*schema.Message
 		for {
 			msg, err := stream.Recv()
 			if err == io.EOF {
@@ -222,14 +297,20 @@ func (c *SDKClient) Chat(ctx context.Context, messages []*schema.Message, opts .
 				stream.Close()
 				return nil, err
 			}
-			fullContent += msg.Content
+			chunks = append(chunks, msg)
 		}
 		stream.Close()
 
-		return &ChatResult{
-			Content: fullContent,
-			Stream:  true,
-		}, nil
+		// 合并流式块（包括分片返回的工具调用参数）
+		full := &schema.Message{Role: schema.Assistant}
+		if len(chunks) > 0 {
+			full, err = schema.ConcatMessages(chunks)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return newChatResult(full, true, start, c.inner.GetModelInfo()), nil
 	}
 
 	// 非流式调用
@@ -238,16 +319,17 @@ func (c *SDKClient) Chat(ctx context.Context, messages []*schema.Message, opts .
 		return nil, err
 	}
 
-	return &ChatResult{
-		Content: resp.Content,
-		Stream:  false,
-	}, nil
+	return newChatResult(resp, false, start, c.inner.GetModelInfo()), nil
 }
 
 // ChatStream 对话流式（支持Option）
 // 支持选项：
 //   - WithTimeout(duration): 设置超时时间（默认60s）
 //   - WithSystemPrompt(prompt): 设置系统提示词（可选）
+//   - WithRateLimitMode(mode): 设置限流模式（可选）
+//   - WithCacheBypass(bool): 跳过响应缓存（可选）
+//   - WithCacheTags(tags...): 语义缓存条目标签（可选）
+//   - WithModelOptions(opts...): 本次调用的模型选项（可选）
 func (c *SDKClient) ChatStream(ctx context.Context, messages []*schema.Message, opts ...ClientOption) (*StreamReader, error) {
 	cfg := DefaultClientConfig()
 	for _, opt := range opts {
@@ -260,6 +342,7 @@ func (c *SDKClient) ChatStream(ctx context.Context, messages []*schema.Message,
 		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
 		_ = cancel // 流式读取完成后自动取消
 	}
+	ctx = cfg.callContext(ctx)
 
 	// 添加系统提示词（如果指定）
 	if cfg.SystemPrompt != "" {
@@ -276,12 +359,13 @@ func (c *SDKClient) ChatStream(ctx context.Context, messages []*schema.Message,
 		}
 	}
 
+	start := time.Now()
 	stream, err := c.inner.ChatStream(ctx, messages)
 	if err != nil {
 		return nil, err
 	}
 
-	return &StreamReader{inner: stream}, nil
+	return newStreamReader(stream, c.inner.GetModelInfo(), start), nil
 }
 
 // GetModelInfo 获取模型信息
@@ -348,18 +432,102 @@ func replaceAll(s, old, new string) string {
 
 // ChatResult 对话结果
 type ChatResult struct {
-	Content string // 完整响应内容
-	Stream  bool   // 是否来自流式响应
+	Content      string            // 完整响应内容
+	Stream       bool              // 是否来自流式响应
+	ToolCalls    []schema.ToolCall // 模型请求的工具调用（可选）
+	Message      *schema.Message   // 完整的助手消息，可直接追加到对话历史
+	Usage        types.Usage       // token使用情况（厂商未返回时为零值）
+	FinishReason string            // 结束原因，如 stop、length、tool_calls
+	RequestID    string            // 厂商请求ID（可选）
+	Latency      time.Duration     // 请求耗时
+	Provider     types.Provider    // 实际提供服务的厂商（使用 FallbackClient 时可能不是首选厂商）
+	Model        string            // 实际提供服务的模型
+	Cached       bool              // 是否来自响应缓存
+}
+
+// Truncated 是否因达到 max_tokens 而被截断
+func (r *ChatResult) Truncated() bool {
+	return r.FinishReason == adapters.FinishReasonLength
+}
+
+// newChatResult 从助手消息构建对话结果
+// info 为客户端的模型信息，消息中未记录实际提供服务的厂商时使用
+func newChatResult(msg *schema.Message, stream bool, start time.Time, info *types.ModelInfo) *ChatResult {
+	result := &ChatResult{
+		Content:   msg.Content,
+		Stream:    stream,
+		ToolCalls: msg.ToolCalls,
+		Message:   msg,
+		Usage:     usageFromMessage(msg),
+		RequestID: adapters.RequestIDOf(msg),
+		Latency:   time.Since(start),
+		Cached:    CacheHit(msg),
+	}
+	if msg.ResponseMeta != nil {
+		result.FinishReason = msg.ResponseMeta.FinishReason
+	}
+	result.Provider, result.Model = ServedBy(msg)
+	if result.Provider == "" && info != nil {
+		result.Provider, result.Model = info.Provider, info.Name
+	}
+	return result
+}
+
+// usageFromMessage 从响应元数据中提取token使用情况
+func usageFromMessage(msg *schema.Message) types.Usage {
+	if msg == nil || msg.ResponseMeta == nil || msg.ResponseMeta.Usage == nil {
+		return types.Usage{}
+	}
+	u := msg.ResponseMeta.Usage
+	total := u.TotalTokens
+	if total == 0 {
+		total = u.PromptTokens + u.CompletionTokens
+	}
+	return types.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      total,
+		CachedTokens:     u.PromptTokenDetails.CachedTokens,
+	}
 }
 
 // StreamReader 流式读取器包装器
+// 读取到流结束后可通过 Result 获取合并后的结果（含token使用情况和结束原因）
 type StreamReader struct {
-	inner *schema.StreamReader[*schema.Message]
+	inner  *schema.StreamReader[*schema.Message]
+	info   *types.ModelInfo
+	start  time.Time
+	chunks []*schema.Message
+	result *ChatResult
+}
+
+// newStreamReader 创建流式读取器包装器，start 为发起请求的时间（延迟包含首包等待）
+func newStreamReader(inner *schema.StreamReader[*schema.Message], info *types.ModelInfo, start time.Time) *StreamReader {
+	return &StreamReader{inner: inner, info: info, start: start}
 }
 
 // Recv 接收流式数据
 func (s *StreamReader) Recv() (*schema.Message, error) {
-	return s.inner.Recv()
+	msg, err := s.inner.Recv()
+	if err == io.EOF && s.result == nil {
+		full := &schema.Message{Role: schema.Assistant}
+		if len(s.chunks) > 0 {
+			if merged, mergeErr := schema.ConcatMessages(s.chunks); mergeErr == nil {
+				full = merged
+			}
+		}
+		s.result = newChatResult(full, true, s.start, s.info)
+		s.chunks = nil
+	}
+	if err == nil && msg != nil {
+		s.chunks = append(s.chunks, msg)
+	}
+	return msg, err
+}
+
+// Result 获取合并后的对话结果，流未读取完毕时返回 nil
+func (s *StreamReader) Result() *ChatResult {
+	return s.result
 }
 
 // Close 关闭流
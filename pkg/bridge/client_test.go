@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"ai-bridge/pkg/adapters"
 	"ai-bridge/pkg/types"
 
 	"github.com/cloudwego/eino/schema"
@@ -258,3 +262,145 @@ func TestClientOptions(t *testing.T) {
 
 	fmt.Println("✓ ClientOption功能测试通过")
 }
+
+// TestSDKClientChatToolCalls 测试流式/非流式对话返回工具调用
+func TestSDKClientChatToolCalls(t *testing.T) {
+	idx := 0
+	chunks := []*schema.Message{
+		{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{Index: &idx, ID: "call_1", Type: "function", Function: schema.FunctionCall{Name: "weather"}}}},
+		{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{Index: &idx, Function: schema.FunctionCall{Arguments: `{"city":`}}}},
+		{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{Index: &idx, Function: schema.FunctionCall{Arguments: `"北京"}`}}}},
+	}
+	full, _ := schema.ConcatMessages(chunks)
+
+	client := NewSDKClient(newFakeBridge(
+		fakeResponse{chunks: chunks},
+		fakeResponse{msg: full},
+	))
+
+	for _, stream := range []bool{true, false} {
+		result, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("北京天气")}, WithStream(stream))
+		if err != nil {
+			t.Fatalf("Chat(stream=%v) error: %v", stream, err)
+		}
+		if len(result.ToolCalls) != 1 {
+			t.Fatalf("Chat(stream=%v) expected 1 tool call, got %d", stream, len(result.ToolCalls))
+		}
+		call := result.ToolCalls[0]
+		if call.ID != "call_1" || call.Function.Arguments != `{"city":"北京"}` {
+			t.Errorf("Chat(stream=%v) unexpected tool call: %+v", stream, call)
+		}
+		if result.Message == nil || len(result.Message.ToolCalls) != 1 {
+			t.Errorf("Chat(stream=%v) Message should carry tool calls", stream)
+		}
+	}
+}
+
+func TestSDKClientChatUsage(t *testing.T) {
+	chunks := []*schema.Message{
+		{Role: schema.Assistant, Content: "你好", Extra: map[string]any{adapters.ExtraKeyRequestID: "req_1"}},
+		{Role: schema.Assistant, Content: "，世界", ResponseMeta: &schema.ResponseMeta{
+			FinishReason: adapters.FinishReasonLength,
+			Usage:        &schema.TokenUsage{PromptTokens: 5, CompletionTokens: 3},
+		}},
+	}
+	full, _ := schema.ConcatMessages(chunks)
+
+	client := NewSDKClient(newFakeBridge(
+		fakeResponse{chunks: chunks},
+		fakeResponse{msg: full},
+		fakeResponse{chunks: chunks},
+	))
+
+	for _, stream := range []bool{true, false} {
+		result, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}, WithStream(stream))
+		if err != nil {
+			t.Fatalf("Chat(stream=%v) error: %v", stream, err)
+		}
+		if result.Content != "你好，世界" || result.FinishReason != adapters.FinishReasonLength || !result.Truncated() {
+			t.Errorf("Chat(stream=%v) unexpected result: %+v", stream, result)
+		}
+		if result.Usage != (types.Usage{PromptTokens: 5, CompletionTokens: 3, TotalTokens: 8}) {
+			t.Errorf("Chat(stream=%v) Usage = %+v", stream, result.Usage)
+		}
+		if result.RequestID != "req_1" {
+			t.Errorf("Chat(stream=%v) RequestID = %q, want req_1", stream, result.RequestID)
+		}
+		if result.Latency <= 0 {
+			t.Errorf("Chat(stream=%v) Latency should be positive", stream)
+		}
+	}
+
+	// ChatStream 读取完毕后可获取合并结果
+	reader, err := client.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	defer reader.Close()
+	for {
+		if _, err := reader.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+	}
+	result := reader.Result()
+	if result == nil || result.Usage.TotalTokens != 8 || result.FinishReason != adapters.FinishReasonLength {
+		t.Errorf("unexpected stream result: %+v", result)
+	}
+}
+
+func TestSDKClientChatStreamLatency(t *testing.T) {
+	// 延迟从发起请求开始计算，包含等待首包的时间
+	delay := 30 * time.Millisecond
+	client := NewSDKClient(newFakeBridge(fakeResponse{msg: schema.AssistantMessage("ok", nil), delay: delay}))
+
+	reader, err := client.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	defer reader.Close()
+	for {
+		if _, err := reader.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+	}
+	if result := reader.Result(); result == nil || result.Latency < delay {
+		t.Errorf("Latency should include time to first chunk (>= %v), got %+v", delay, result)
+	}
+}
+
+func TestSDKProviderConfigOnRetry(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"message":"overloaded","type":"server_error"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	defer server.Close()
+
+	var events []types.RetryEvent
+	sdk := NewSDK(&SDKConfig{GPT: ProviderConfig{
+		APIKey:  "sk-test",
+		BaseURL: server.URL,
+		OnRetry: func(ctx context.Context, event types.RetryEvent) {
+			events = append(events, event)
+		},
+	}})
+	client, err := sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	if err != nil {
+		t.Fatalf("CreateClient() error: %v", err)
+	}
+	if _, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}); err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if len(events) != 1 || events[0].Attempt != 1 || adapters.StatusCodeOf(events[0].Err) != http.StatusServiceUnavailable {
+		t.Errorf("retry events = %+v, want one 503 retry", events)
+	}
+}
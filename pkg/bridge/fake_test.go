@@ -0,0 +1,115 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/types"
+)
+
+// fakeResponse 脚本化的响应
+type fakeResponse struct {
+	msg       *schema.Message   // 非流式响应（流式时作为单个块返回）
+	chunks    []*schema.Message // 流式响应块（可选）
+	err       error             // 调用错误
+	streamErr error             // 发送完 chunks 后返回的流错误（可选）
+	delay     time.Duration     // 返回响应前的等待时间（模拟首包延迟，可选）
+}
+
+// fakeBridge 测试用的 types.AIBridge 实现，按顺序返回脚本化响应
+type fakeBridge struct {
+	mu        sync.Mutex
+	info      *types.ModelInfo
+	responses []fakeResponse
+	calls     [][]*schema.Message
+}
+
+// newFakeBridge 创建测试用的AIBridge
+func newFakeBridge(responses ...fakeResponse) *fakeBridge {
+	return &fakeBridge{
+		info:      &types.ModelInfo{Name: "fake-model", Provider: "fake"},
+		responses: responses,
+	}
+}
+
+// next 取出下一个脚本化响应
+func (f *fakeBridge) next(messages []*schema.Message) (fakeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, messages)
+	if len(f.responses) == 0 {
+		return fakeResponse{}, fmt.Errorf("fake bridge: no more responses")
+	}
+	resp := f.responses[0]
+	f.responses = f.responses[1:]
+	return resp, nil
+}
+
+// callCount 返回调用次数
+func (f *fakeBridge) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func (f *fakeBridge) Chat(ctx context.Context, messages []*schema.Message) (*schema.Message, error) {
+	resp, err := f.next(messages)
+	if err != nil {
+		return nil, err
+	}
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	if resp.msg == nil && len(resp.chunks) > 0 {
+		return schema.ConcatMessages(resp.chunks)
+	}
+	return resp.msg, nil
+}
+
+func (f *fakeBridge) ChatStream(ctx context.Context, messages []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+	resp, err := f.next(messages)
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(resp.delay)
+	if resp.err != nil {
+		return nil, resp.err
+	}
+
+	chunks := resp.chunks
+	if len(chunks) == 0 && resp.msg != nil {
+		chunks = []*schema.Message{resp.msg}
+	}
+
+	sr, sw := schema.Pipe[*schema.Message](len(chunks) + 1)
+	go func() {
+		defer sw.Close()
+		for _, c := range chunks {
+			sw.Send(c, nil)
+		}
+		if resp.streamErr != nil {
+			sw.Send(nil, resp.streamErr)
+		}
+	}()
+	return sr, nil
+}
+
+func (f *fakeBridge) Generate(ctx context.Context, prompt string) (string, error) {
+	resp, err := f.Chat(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+func (f *fakeBridge) GenerateStream(ctx context.Context, prompt string) (string, error) {
+	return f.Generate(ctx, prompt)
+}
+
+func (f *fakeBridge) GetModelInfo() *types.ModelInfo {
+	return f.info
+}
@@ -0,0 +1,190 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/circuit"
+	"ai-bridge/pkg/types"
+)
+
+// schema.Message.Extra 中记录实际响应厂商与模型的键
+const (
+	ExtraKeyProvider = "provider"
+	ExtraKeyModel    = "model"
+)
+
+// ErrContentFiltered 响应被厂商内容过滤拦截
+var ErrContentFiltered = errors.New("response blocked by content filter")
+
+// FallbackClient 降级客户端
+// 按顺序尝试多个AI客户端（如 GPT → Deepseek → 本地Ollama），
+// 遇到可重试错误、超时、熔断或内容过滤拦截时切换到下一个。
+// 响应的 Extra 中记录实际提供服务的厂商和模型（见 ServedBy）。
+type FallbackClient struct {
+	clients []types.AIBridge
+}
+
+// NewFallbackClient 创建降级客户端，clients 按优先级排列
+func NewFallbackClient(clients ...types.AIBridge) *FallbackClient {
+	return &FallbackClient{clients: clients}
+}
+
+// Chat 执行对话（非流式），失败时按顺序降级
+func (f *FallbackClient) Chat(ctx context.Context, messages []*schema.Message) (*schema.Message, error) {
+	var errs []error
+	for _, client := range f.clients {
+		resp, err := client.Chat(ctx, messages)
+		if err == nil && isContentFiltered(resp) {
+			err = ErrContentFiltered
+		}
+		if err == nil {
+			return markServedBy(resp, client), nil
+		}
+
+		errs = append(errs, providerError(client, err))
+		if !shouldFallback(ctx, err) {
+			break
+		}
+	}
+	return nil, fallbackError(errs)
+}
+
+// ChatStream 执行对话（流式）
+// 仅在收到第一个流式块之前降级，之后的错误直接透传
+func (f *FallbackClient) ChatStream(ctx context.Context, messages []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+	var errs []error
+	for _, client := range f.clients {
+		stream, err := client.ChatStream(ctx, messages)
+		if err == nil {
+			var first *schema.Message
+			first, err = stream.Recv()
+			switch {
+			case err == io.EOF:
+				stream.Close()
+				return schema.StreamReaderFromArray([]*schema.Message{}), nil
+			case err == nil && isContentFiltered(first):
+				stream.Close()
+				err = ErrContentFiltered
+			case err == nil:
+				return adapters.PrependChunk(markServedBy(first, client), stream), nil
+			default:
+				stream.Close()
+			}
+		}
+
+		errs = append(errs, providerError(client, err))
+		if !shouldFallback(ctx, err) {
+			break
+		}
+	}
+	return nil, fallbackError(errs)
+}
+
+// Generate 生成文本（简化接口），失败时按顺序降级
+func (f *FallbackClient) Generate(ctx context.Context, prompt string) (string, error) {
+	var errs []error
+	for _, client := range f.clients {
+		result, err := client.Generate(ctx, prompt)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, providerError(client, err))
+		if !shouldFallback(ctx, err) {
+			break
+		}
+	}
+	return "", fallbackError(errs)
+}
+
+// GenerateStream 生成文本（流式），失败时按顺序降级
+func (f *FallbackClient) GenerateStream(ctx context.Context, prompt string) (string, error) {
+	var errs []error
+	for _, client := range f.clients {
+		result, err := client.GenerateStream(ctx, prompt)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, providerError(client, err))
+		if !shouldFallback(ctx, err) {
+			break
+		}
+	}
+	return "", fallbackError(errs)
+}
+
+// GetModelInfo 获取首选客户端的模型信息
+func (f *FallbackClient) GetModelInfo() *types.ModelInfo {
+	if len(f.clients) == 0 {
+		return nil
+	}
+	return f.clients[0].GetModelInfo()
+}
+
+// ServedBy 获取实际提供服务的厂商和模型（由 FallbackClient 记录）
+func ServedBy(msg *schema.Message) (types.Provider, string) {
+	if msg == nil {
+		return "", ""
+	}
+	provider, _ := msg.Extra[ExtraKeyProvider].(string)
+	model, _ := msg.Extra[ExtraKeyModel].(string)
+	return types.Provider(provider), model
+}
+
+// shouldFallback 判断错误是否需要降级到下一个客户端
+// 调用方的上下文已取消或超时时不再降级
+func shouldFallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, ErrContentFiltered) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, circuit.ErrOpen) {
+		return true
+	}
+	return adapters.IsRetryableError(err)
+}
+
+// isContentFiltered 判断响应是否被内容过滤拦截
+func isContentFiltered(msg *schema.Message) bool {
+	return msg != nil && msg.ResponseMeta != nil && msg.ResponseMeta.FinishReason == adapters.FinishReasonContentFilter
+}
+
+// markServedBy 在消息中记录提供服务的厂商和模型（复制消息，不修改原对象）
+func markServedBy(msg *schema.Message, client types.AIBridge) *schema.Message {
+	info := client.GetModelInfo()
+	if msg == nil || info == nil {
+		return msg
+	}
+
+	marked := *msg
+	marked.Extra = make(map[string]any, len(msg.Extra)+2)
+	for k, v := range msg.Extra {
+		marked.Extra[k] = v
+	}
+	marked.Extra[ExtraKeyProvider] = string(info.Provider)
+	marked.Extra[ExtraKeyModel] = info.Name
+	return &marked
+}
+
+// providerError 为错误附加厂商信息
+func providerError(client types.AIBridge, err error) error {
+	if info := client.GetModelInfo(); info != nil {
+		return fmt.Errorf("%s/%s: %w", info.Provider, info.Name, err)
+	}
+	return err
+}
+
+// fallbackError 合并所有客户端的错误
+func fallbackError(errs []error) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("no clients configured")
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return fmt.Errorf("fallback failed after %d providers: %w", len(errs), errors.Join(errs...))
+}
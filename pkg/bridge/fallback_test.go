@@ -0,0 +1,135 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/types"
+)
+
+// newNamedFakeBridge 创建指定厂商和模型的测试AIBridge
+func newNamedFakeBridge(provider types.Provider, model string, responses ...fakeResponse) *fakeBridge {
+	f := newFakeBridge(responses...)
+	f.info = &types.ModelInfo{Name: model, Provider: provider}
+	return f
+}
+
+func TestFallbackClientChat(t *testing.T) {
+	gpt := newNamedFakeBridge(types.ProviderGPT, "gpt-4o", fakeResponse{err: &adapters.APIError{StatusCode: 503}})
+	deepseek := newNamedFakeBridge(types.ProviderDeepseek, "deepseek-chat", fakeResponse{msg: &schema.Message{
+		Role:         schema.Assistant,
+		Content:      "blocked",
+		ResponseMeta: &schema.ResponseMeta{FinishReason: adapters.FinishReasonContentFilter},
+	}})
+	ollama := newNamedFakeBridge(types.ProviderOllama, "qwen3", fakeResponse{msg: schema.AssistantMessage("ok", nil)})
+
+	client := NewFallbackClient(gpt, deepseek, ollama)
+	resp, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if resp.Content != "ok" {
+		t.Errorf("Content = %q, want ok", resp.Content)
+	}
+	if provider, model := ServedBy(resp); provider != types.ProviderOllama || model != "qwen3" {
+		t.Errorf("ServedBy() = %s/%s, want ollama/qwen3", provider, model)
+	}
+
+	// SDKClient 的结果记录实际提供服务的厂商
+	ollama.responses = []fakeResponse{{msg: schema.AssistantMessage("ok", nil)}}
+	gpt.responses = []fakeResponse{{err: errors.New("error, status code: 429, status: 429, message: slow down")}}
+	deepseek.responses = []fakeResponse{{err: errors.New("error, status code: 502, status: 502, message: bad gateway")}}
+	result, err := NewSDKClient(client).Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}, WithStream(false))
+	if err != nil {
+		t.Fatalf("SDKClient.Chat() error: %v", err)
+	}
+	if result.Provider != types.ProviderOllama || result.Model != "qwen3" {
+		t.Errorf("result served by %s/%s, want ollama/qwen3", result.Provider, result.Model)
+	}
+}
+
+func TestFallbackClientNoFallbackOnClientError(t *testing.T) {
+	gpt := newNamedFakeBridge(types.ProviderGPT, "gpt-4o", fakeResponse{err: &adapters.APIError{StatusCode: 400, Message: "bad request"}})
+	ollama := newNamedFakeBridge(types.ProviderOllama, "qwen3", fakeResponse{msg: schema.AssistantMessage("ok", nil)})
+
+	_, err := NewFallbackClient(gpt, ollama).Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err == nil || !strings.Contains(err.Error(), "gpt/gpt-4o") {
+		t.Fatalf("expected gpt error, got %v", err)
+	}
+	if ollama.callCount() != 0 {
+		t.Error("should not fall back on 400")
+	}
+}
+
+func TestFallbackClientAllFailed(t *testing.T) {
+	gpt := newNamedFakeBridge(types.ProviderGPT, "gpt-4o", fakeResponse{err: &adapters.APIError{StatusCode: 500}})
+	ollama := newNamedFakeBridge(types.ProviderOllama, "qwen3", fakeResponse{err: context.DeadlineExceeded})
+
+	_, err := NewFallbackClient(gpt, ollama).Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	var apiErr *adapters.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected joined errors, got %v", err)
+	}
+}
+
+func TestFallbackClientStream(t *testing.T) {
+	gpt := newNamedFakeBridge(types.ProviderGPT, "gpt-4o",
+		// 第一个块之前失败：降级
+		fakeResponse{streamErr: &adapters.APIError{StatusCode: 529}},
+		// 第一个块之后失败：透传错误
+		fakeResponse{chunks: []*schema.Message{schema.AssistantMessage("部分", nil)}, streamErr: &adapters.APIError{StatusCode: 503}},
+	)
+	deepseek := newNamedFakeBridge(types.ProviderDeepseek, "deepseek-chat",
+		fakeResponse{chunks: []*schema.Message{schema.AssistantMessage("你好", nil), schema.AssistantMessage("，世界", nil)}},
+	)
+	client := NewFallbackClient(gpt, deepseek)
+
+	stream, err := client.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	var chunks []*schema.Message
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		chunks = append(chunks, msg)
+	}
+	stream.Close()
+
+	full, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		t.Fatalf("ConcatMessages() error: %v", err)
+	}
+	if full.Content != "你好，世界" {
+		t.Errorf("Content = %q, want 你好，世界", full.Content)
+	}
+	if provider, _ := ServedBy(full); provider != types.ProviderDeepseek {
+		t.Errorf("ServedBy() = %s, want deepseek", provider)
+	}
+
+	stream, err = client.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	defer stream.Close()
+	if msg, err := stream.Recv(); err != nil || msg.Content != "部分" {
+		t.Fatalf("first Recv() = %v, %v", msg, err)
+	}
+	if _, err := stream.Recv(); err == nil || err == io.EOF {
+		t.Errorf("expected mid-stream error, got %v", err)
+	}
+	if deepseek.callCount() != 1 {
+		t.Errorf("deepseek calls = %d, want 1 (no fallback after first chunk)", deepseek.callCount())
+	}
+}
@@ -0,0 +1,169 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/types"
+)
+
+// probePrompt 健康探测使用的提示词（尽量短，降低探测成本）
+const probePrompt = "ping"
+
+// ProbeResult 健康探测结果
+type ProbeResult struct {
+	Healthy   bool      `json:"healthy"`
+	LatencyMs int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthProber 后台健康探测器
+// 定期向每个目标发送一次简短请求。目标为 CircuitBreakerClient 时绕过熔断直接探测，
+// 并将结果反馈给熔断器：熔断期间探测成功会提前进入半开状态。
+type HealthProber struct {
+	interval time.Duration
+	timeout  time.Duration
+
+	mu      sync.RWMutex
+	targets map[string]types.AIBridge
+	results map[string]ProbeResult
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewHealthProber 创建健康探测器
+// interval 为探测间隔（默认30s），timeout 为单次探测超时（默认10s）
+func NewHealthProber(interval, timeout time.Duration) *HealthProber {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &HealthProber{
+		interval: interval,
+		timeout:  timeout,
+		targets:  make(map[string]types.AIBridge),
+		results:  make(map[string]ProbeResult),
+	}
+}
+
+// Add 添加探测目标，name 通常为 provider/model
+func (p *HealthProber) Add(name string, client types.AIBridge) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.targets[name] = client
+}
+
+// Start 在后台开始周期性探测（立即执行第一轮），直到 ctx 结束或调用 Stop
+func (p *HealthProber) Start(ctx context.Context) {
+	p.mu.Lock()
+	if p.stop != nil {
+		p.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	p.stop = stop
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			p.ProbeOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止后台探测并等待进行中的探测结束
+func (p *HealthProber) Stop() {
+	p.mu.Lock()
+	stop := p.stop
+	p.stop = nil
+	p.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		p.wg.Wait()
+	}
+}
+
+// ProbeOnce 并发探测所有目标一次
+func (p *HealthProber) ProbeOnce(ctx context.Context) {
+	p.mu.RLock()
+	targets := make(map[string]types.AIBridge, len(p.targets))
+	for name, client := range p.targets {
+		targets[name] = client
+	}
+	p.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, client := range targets {
+		wg.Add(1)
+		go func(name string, client types.AIBridge) {
+			defer wg.Done()
+			result := p.probe(ctx, client)
+
+			p.mu.Lock()
+			p.results[name] = result
+			p.mu.Unlock()
+		}(name, client)
+	}
+	wg.Wait()
+}
+
+// Results 获取所有目标最近一次的探测结果
+func (p *HealthProber) Results() map[string]ProbeResult {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	results := make(map[string]ProbeResult, len(p.results))
+	for name, result := range p.results {
+		results[name] = result
+	}
+	return results
+}
+
+// probe 探测单个目标
+func (p *HealthProber) probe(ctx context.Context, client types.AIBridge) ProbeResult {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	target := client
+	cb, isBreaker := client.(*CircuitBreakerClient)
+	if isBreaker {
+		target = cb.inner
+	}
+
+	start := time.Now()
+	_, err := target.Chat(ctx, []*schema.Message{schema.UserMessage(probePrompt)})
+	latency := time.Since(start)
+	if isBreaker {
+		cb.breaker.Observe(providerFailure(err), latency)
+	}
+
+	result := ProbeResult{
+		Healthy:   err == nil,
+		LatencyMs: latency.Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
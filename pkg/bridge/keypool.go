@@ -0,0 +1,373 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/ratelimit"
+	"ai-bridge/pkg/types"
+)
+
+// KeyStrategy 多密钥选择策略
+type KeyStrategy string
+
+const (
+	KeyStrategyRoundRobin KeyStrategy = "round_robin" // 轮询（默认）
+	KeyStrategyLeastUsed  KeyStrategy = "least_used"  // 选择进行中请求最少的密钥
+	KeyStrategyWeighted   KeyStrategy = "weighted"    // 按权重平滑轮询
+)
+
+// DefaultKeyCooldown 密钥返回401/403/429后的默认冷却时间
+const DefaultKeyCooldown = time.Minute
+
+// ErrNoAvailableKey 所有密钥都处于冷却中
+var ErrNoAvailableKey = errors.New("no available api key: all keys are cooling down")
+
+// Credential 厂商凭证
+type Credential struct {
+	APIKey       string // API密钥
+	Organization string // 组织ID（可选，仅OpenAI使用）
+	Weight       int    // 权重（仅 weighted 策略使用，默认1）
+	Name         string // 展示名称（可选，默认为脱敏后的密钥）
+}
+
+// KeyHealth 密钥健康状态
+type KeyHealth struct {
+	Name          string    `json:"name"`
+	Healthy       bool      `json:"healthy"`
+	InFlight      int       `json:"in_flight"`
+	Requests      int64     `json:"requests"`
+	Failures      int64     `json:"failures"`
+	CooldownUntil time.Time `json:"cooldown_until,omitzero"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// keyState 单个密钥的运行状态
+type keyState struct {
+	cred          Credential
+	inFlight      int
+	requests      int64
+	failures      int64
+	cooldownUntil time.Time
+	lastError     string
+	currentWeight int
+}
+
+// KeyPool 密钥池（并发安全）
+// 按策略选择密钥，返回401/403/429的密钥自动冷却
+type KeyPool struct {
+	mu       sync.Mutex
+	keys     []*keyState
+	strategy KeyStrategy
+	cooldown time.Duration
+	next     int
+	now      func() time.Time
+}
+
+// NewKeyPool 创建密钥池
+// strategy 为空时使用轮询，cooldown <= 0 时使用 DefaultKeyCooldown
+func NewKeyPool(creds []Credential, strategy KeyStrategy, cooldown time.Duration) (*KeyPool, error) {
+	if len(creds) == 0 {
+		return nil, fmt.Errorf("at least one credential is required")
+	}
+	switch strategy {
+	case "":
+		strategy = KeyStrategyRoundRobin
+	case KeyStrategyRoundRobin, KeyStrategyLeastUsed, KeyStrategyWeighted:
+	default:
+		return nil, fmt.Errorf("unknown key strategy: %s", strategy)
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultKeyCooldown
+	}
+
+	pool := &KeyPool{strategy: strategy, cooldown: cooldown, now: time.Now}
+	for _, cred := range creds {
+		if cred.APIKey == "" {
+			return nil, fmt.Errorf("credential api key is required")
+		}
+		if cred.Weight <= 0 {
+			cred.Weight = 1
+		}
+		if cred.Name == "" {
+			cred.Name = maskKey(cred.APIKey)
+		}
+		pool.keys = append(pool.keys, &keyState{cred: cred})
+	}
+	return pool, nil
+}
+
+// Size 返回密钥数量
+func (p *KeyPool) Size() int {
+	return len(p.keys)
+}
+
+// Credential 获取指定位置的凭证
+func (p *KeyPool) Credential(idx int) Credential {
+	return p.keys[idx].cred
+}
+
+// Acquire 按策略选择一个可用密钥，返回其位置
+// 调用结束后必须调用 Release
+func (p *KeyPool) Acquire() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	idx := -1
+	switch p.strategy {
+	case KeyStrategyLeastUsed:
+		for i, k := range p.keys {
+			if !k.available(now) {
+				continue
+			}
+			if idx < 0 || k.inFlight < p.keys[idx].inFlight ||
+				(k.inFlight == p.keys[idx].inFlight && k.requests < p.keys[idx].requests) {
+				idx = i
+			}
+		}
+	case KeyStrategyWeighted:
+		// 平滑加权轮询：每次所有可用密钥加上权重，选最大者并减去总权重
+		total := 0
+		for i, k := range p.keys {
+			if !k.available(now) {
+				continue
+			}
+			k.currentWeight += k.cred.Weight
+			total += k.cred.Weight
+			if idx < 0 || k.currentWeight > p.keys[idx].currentWeight {
+				idx = i
+			}
+		}
+		if idx >= 0 {
+			p.keys[idx].currentWeight -= total
+		}
+	default:
+		for n := 0; n < len(p.keys); n++ {
+			i := (p.next + n) % len(p.keys)
+			if p.keys[i].available(now) {
+				idx = i
+				p.next = i + 1
+				break
+			}
+		}
+	}
+
+	if idx < 0 {
+		return -1, ErrNoAvailableKey
+	}
+	p.keys[idx].inFlight++
+	p.keys[idx].requests++
+	return idx, nil
+}
+
+// Release 归还密钥并记录调用结果
+// 401/403 表示密钥无效，429 表示密钥被限流，均会使密钥进入冷却
+func (p *KeyPool) Release(idx int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k := p.keys[idx]
+	k.inFlight--
+	if err == nil || errors.Is(err, ratelimit.ErrRateLimited) {
+		// 客户端侧限流未发出请求，不计入密钥失败
+		return
+	}
+
+	k.failures++
+	k.lastError = err.Error()
+	if isKeyError(err) {
+		cooldown := p.cooldown
+		var apiErr *adapters.APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > cooldown {
+			cooldown = apiErr.RetryAfter
+		}
+		k.cooldownUntil = p.now().Add(cooldown)
+	}
+}
+
+// Health 获取所有密钥的健康状态
+func (p *KeyPool) Health() []KeyHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	result := make([]KeyHealth, 0, len(p.keys))
+	for _, k := range p.keys {
+		h := KeyHealth{
+			Name:      k.cred.Name,
+			Healthy:   k.available(now),
+			InFlight:  k.inFlight,
+			Requests:  k.requests,
+			Failures:  k.failures,
+			LastError: k.lastError,
+		}
+		if !h.Healthy {
+			h.CooldownUntil = k.cooldownUntil
+		}
+		result = append(result, h)
+	}
+	return result
+}
+
+// available 密钥是否可用（不在冷却中）
+func (k *keyState) available(now time.Time) bool {
+	return !now.Before(k.cooldownUntil)
+}
+
+// isKeyError 判断错误是否与密钥本身有关（无效或被限流）
+func isKeyError(err error) bool {
+	switch adapters.StatusCodeOf(err) {
+	case 401, 403, 429:
+		return true
+	}
+	return false
+}
+
+// RetryOnSameKey 多密钥时单个密钥的重试判断（用作 options.WithRetryIf）：密钥相关错误不重试，由密钥池立即换用其他密钥
+func RetryOnSameKey(err error) bool {
+	return !isKeyError(err) && adapters.IsRetryableError(err)
+}
+
+// shouldSwitchKey 判断是否应换用其他密钥重试：密钥相关错误，或密钥级客户端限流
+func shouldSwitchKey(err error) bool {
+	return isKeyError(err) || errors.Is(err, ratelimit.ErrRateLimited)
+}
+
+// maskKey 脱敏密钥，仅保留首尾少量字符
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "***"
+	}
+	return key[:3] + "..." + key[len(key)-4:]
+}
+
+// PooledClient 多密钥客户端
+// 每个密钥对应一个AI客户端，每次调用按密钥池策略选择；
+// 密钥返回401/403/429或触发客户端限流时自动换用其他密钥重试。
+type PooledClient struct {
+	pool    *KeyPool
+	clients []types.AIBridge
+}
+
+// NewPooledClient 创建多密钥客户端，factory 为每个凭证创建AI客户端
+func NewPooledClient(pool *KeyPool, factory func(cred Credential) (types.AIBridge, error)) (*PooledClient, error) {
+	clients := make([]types.AIBridge, pool.Size())
+	for i := range clients {
+		client, err := factory(pool.Credential(i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for key %s: %w", pool.Credential(i).Name, err)
+		}
+		clients[i] = client
+	}
+	return &PooledClient{pool: pool, clients: clients}, nil
+}
+
+// Pool 获取密钥池
+func (c *PooledClient) Pool() *KeyPool {
+	return c.pool
+}
+
+// Chat 执行对话（非流式）
+func (c *PooledClient) Chat(ctx context.Context, messages []*schema.Message) (*schema.Message, error) {
+	var resp *schema.Message
+	err := c.do(func(client types.AIBridge) error {
+		var err error
+		resp, err = client.Chat(ctx, messages)
+		return err
+	})
+	return resp, err
+}
+
+// ChatStream 执行对话（流式），密钥在流结束或关闭时归还
+func (c *PooledClient) ChatStream(ctx context.Context, messages []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+	var lastErr error
+	for attempt := 0; attempt < c.pool.Size(); attempt++ {
+		idx, err := c.pool.Acquire()
+		if err != nil {
+			return nil, keyPoolError(err, lastErr)
+		}
+
+		stream, err := c.clients[idx].ChatStream(ctx, messages)
+		if err != nil {
+			c.pool.Release(idx, err)
+			if !shouldSwitchKey(err) {
+				return nil, err
+			}
+			lastErr = err
+			continue
+		}
+		return c.releaseOnFinish(idx, stream), nil
+	}
+	return nil, lastErr
+}
+
+// Generate 生成文本（简化接口）
+func (c *PooledClient) Generate(ctx context.Context, prompt string) (string, error) {
+	var result string
+	err := c.do(func(client types.AIBridge) error {
+		var err error
+		result, err = client.Generate(ctx, prompt)
+		return err
+	})
+	return result, err
+}
+
+// GenerateStream 生成文本（流式）
+func (c *PooledClient) GenerateStream(ctx context.Context, prompt string) (string, error) {
+	var result string
+	err := c.do(func(client types.AIBridge) error {
+		var err error
+		result, err = client.GenerateStream(ctx, prompt)
+		return err
+	})
+	return result, err
+}
+
+// GetModelInfo 获取模型信息
+func (c *PooledClient) GetModelInfo() *types.ModelInfo {
+	return c.clients[0].GetModelInfo()
+}
+
+// do 选择密钥执行调用，密钥相关错误时换用其他密钥，最多尝试每个密钥一次
+func (c *PooledClient) do(call func(client types.AIBridge) error) error {
+	var lastErr error
+	for attempt := 0; attempt < c.pool.Size(); attempt++ {
+		idx, err := c.pool.Acquire()
+		if err != nil {
+			return keyPoolError(err, lastErr)
+		}
+
+		err = call(c.clients[idx])
+		c.pool.Release(idx, err)
+		if err == nil || !shouldSwitchKey(err) {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// releaseOnFinish 包装流式响应，在流结束、出错或被关闭时归还密钥
+func (c *PooledClient) releaseOnFinish(idx int, stream *schema.StreamReader[*schema.Message]) *schema.StreamReader[*schema.Message] {
+	return adapters.RelayStream(stream, nil, func(err error) {
+		if errors.Is(err, adapters.ErrStreamClosed) {
+			err = nil
+		}
+		c.pool.Release(idx, err)
+	})
+}
+
+// keyPoolError 所有密钥不可用时，附带最后一次调用的错误
+func keyPoolError(err, lastErr error) error {
+	if lastErr != nil {
+		return fmt.Errorf("%w: %w", err, lastErr)
+	}
+	return err
+}
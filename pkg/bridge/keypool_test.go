@@ -0,0 +1,265 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/types"
+)
+
+// acquireSequence 连续选择 n 次密钥并立即归还，返回选中的密钥名称
+func acquireSequence(t *testing.T, pool *KeyPool, n int) []string {
+	t.Helper()
+	var names []string
+	for i := 0; i < n; i++ {
+		idx, err := pool.Acquire()
+		if err != nil {
+			t.Fatalf("Acquire() error: %v", err)
+		}
+		names = append(names, pool.Credential(idx).Name)
+		pool.Release(idx, nil)
+	}
+	return names
+}
+
+func TestKeyPoolStrategies(t *testing.T) {
+	creds := []Credential{
+		{APIKey: "sk-a", Name: "a", Weight: 3},
+		{APIKey: "sk-b", Name: "b", Weight: 1},
+	}
+
+	pool, err := NewKeyPool(creds, KeyStrategyRoundRobin, 0)
+	if err != nil {
+		t.Fatalf("NewKeyPool() error: %v", err)
+	}
+	if got := acquireSequence(t, pool, 4); !equalStrings(got, []string{"a", "b", "a", "b"}) {
+		t.Errorf("round robin = %v", got)
+	}
+
+	pool, _ = NewKeyPool(creds, KeyStrategyWeighted, 0)
+	if got := acquireSequence(t, pool, 4); !equalStrings(got, []string{"a", "a", "b", "a"}) {
+		t.Errorf("weighted = %v", got)
+	}
+
+	// least_used 优先选择进行中请求最少的密钥
+	pool, _ = NewKeyPool(creds, KeyStrategyLeastUsed, 0)
+	first, _ := pool.Acquire()
+	second, _ := pool.Acquire()
+	if first == second {
+		t.Errorf("least used picked the busy key twice")
+	}
+	pool.Release(second, nil)
+	if idx, _ := pool.Acquire(); idx != second {
+		t.Errorf("least used = %d, want %d", idx, second)
+	}
+
+	if _, err := NewKeyPool(creds, "random", 0); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+	if _, err := NewKeyPool(nil, "", 0); err == nil {
+		t.Error("expected error for empty pool")
+	}
+}
+
+func TestKeyPoolCooldown(t *testing.T) {
+	pool, _ := NewKeyPool([]Credential{{APIKey: "sk-aaaaaaaa1111"}, {APIKey: "sk-bbbbbbbb2222"}}, "", time.Minute)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	idx, _ := pool.Acquire()
+	pool.Release(idx, &adapters.APIError{StatusCode: 429, RetryAfter: 2 * time.Minute})
+	other, _ := pool.Acquire()
+	pool.Release(other, &adapters.APIError{StatusCode: 500})
+
+	health := pool.Health()
+	if health[idx].Healthy || !health[idx].CooldownUntil.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("429 key health = %+v, want cooldown by Retry-After", health[idx])
+	}
+	if !health[other].Healthy || health[other].Failures != 1 {
+		t.Errorf("500 key health = %+v, want healthy with 1 failure", health[other])
+	}
+	if data, _ := json.Marshal(health[other]); strings.Contains(string(data), "cooldown_until") {
+		t.Errorf("healthy key JSON = %s, want no cooldown_until", data)
+	}
+	if health[0].Name != "sk-...1111" {
+		t.Errorf("Name = %q, want masked key", health[0].Name)
+	}
+
+	// 冷却期间只选择健康的密钥
+	for _, name := range acquireSequence(t, pool, 3) {
+		if name != pool.Credential(other).Name {
+			t.Errorf("picked cooling key %s", name)
+		}
+	}
+
+	idx, _ = pool.Acquire()
+	pool.Release(idx, errors.New("error, status code: 401, status: 401, message: invalid key"))
+	if _, err := pool.Acquire(); !errors.Is(err, ErrNoAvailableKey) {
+		t.Errorf("Acquire() error = %v, want ErrNoAvailableKey", err)
+	}
+
+	// 冷却结束后恢复
+	now = now.Add(3 * time.Minute)
+	if _, err := pool.Acquire(); err != nil {
+		t.Errorf("Acquire() after cooldown error: %v", err)
+	}
+}
+
+func TestPooledClient(t *testing.T) {
+	fakes := map[string]*fakeBridge{
+		"sk-a": newFakeBridge(fakeResponse{err: &adapters.APIError{StatusCode: 429}}),
+		"sk-b": newFakeBridge(
+			fakeResponse{msg: schema.AssistantMessage("ok", nil)},
+			fakeResponse{err: &adapters.APIError{StatusCode: 400}},
+			fakeResponse{chunks: []*schema.Message{schema.AssistantMessage("流", nil), schema.AssistantMessage("式", nil)}},
+		),
+	}
+	pool, _ := NewKeyPool([]Credential{{APIKey: "sk-a"}, {APIKey: "sk-b"}}, KeyStrategyRoundRobin, 0)
+	client, err := NewPooledClient(pool, func(cred Credential) (types.AIBridge, error) {
+		return fakes[cred.APIKey], nil
+	})
+	if err != nil {
+		t.Fatalf("NewPooledClient() error: %v", err)
+	}
+
+	// 第一个密钥被限流，自动换用第二个密钥
+	resp, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil || resp.Content != "ok" {
+		t.Fatalf("Chat() = %v, %v", resp, err)
+	}
+
+	// 非密钥错误直接返回，不换密钥也不冷却
+	_, err = client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if adapters.StatusCodeOf(err) != 400 {
+		t.Errorf("Chat() error = %v, want 400", err)
+	}
+	if fakes["sk-a"].callCount() != 1 {
+		t.Errorf("cooling key called %d times, want 1", fakes["sk-a"].callCount())
+	}
+
+	stream, err := client.ChatStream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	var content string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		content += msg.Content
+	}
+	stream.Close()
+	if content != "流式" {
+		t.Errorf("stream content = %q, want 流式", content)
+	}
+
+	// 流结束后密钥已归还
+	deadline := time.Now().Add(time.Second)
+	for pool.Health()[1].InFlight != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if h := pool.Health()[1]; h.InFlight != 0 || h.Requests != 3 {
+		t.Errorf("key health = %+v, want 0 in flight and 3 requests", h)
+	}
+}
+
+func TestSDKCreateClientWithKeyPool(t *testing.T) {
+	sdk := NewSDK(&SDKConfig{GPT: ProviderConfig{
+		APIKeys:     ParseCredentials("sk-a, sk-b,"),
+		KeyStrategy: KeyStrategyLeastUsed,
+	}})
+
+	client, err := sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	if err != nil {
+		t.Fatalf("CreateClient() error: %v", err)
+	}
+	pooled, ok := client.(*PooledClient)
+	if !ok {
+		t.Fatalf("CreateClient() = %T, want *PooledClient", client)
+	}
+
+	// 同一厂商的客户端共享密钥池
+	other, _ := sdk.CreateClient(types.ProviderGPT, "gpt-4o-mini")
+	if other.(*PooledClient).Pool() != pooled.Pool() {
+		t.Error("clients should share the provider key pool")
+	}
+	if health := sdk.KeyHealth(types.ProviderGPT); len(health) != 2 {
+		t.Errorf("KeyHealth() = %v, want 2 keys", health)
+	}
+	if sdk.KeyHealth(types.ProviderClaude) != nil {
+		t.Error("KeyHealth() should be nil without a key pool")
+	}
+}
+
+// equalStrings 比较两个字符串切片
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSDKKeyPoolRetryAndRotate(t *testing.T) {
+	// 被限流的密钥不在适配器内重试，而是立即换用其他密钥；厂商的临时错误仍在同一密钥上退避重试
+	var mu sync.Mutex
+	hits := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		mu.Lock()
+		hits[key]++
+		first := hits[key] == 1
+		mu.Unlock()
+		if key == "sk-b" && first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"message":"overloaded","type":"server_error"}}`)
+			return
+		}
+		if key == "sk-a" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"message":"rate limited","type":"rate_limit"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	defer server.Close()
+
+	sdk := NewSDK(&SDKConfig{GPT: ProviderConfig{
+		BaseURL:    server.URL,
+		MaxRetries: 3,
+		APIKeys:    ParseCredentials("sk-a,sk-b"),
+	}})
+	client, err := sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	if err != nil {
+		t.Fatalf("CreateClient() error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")}); err != nil {
+			t.Fatalf("Chat() error: %v", err)
+		}
+	}
+	if hits["sk-a"] != 1 || hits["sk-b"] != 3 {
+		t.Errorf("hits = %v, want sk-a once and sk-b three times", hits)
+	}
+}
@@ -0,0 +1,137 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/types"
+)
+
+// MiddlewareClient 中间件客户端
+// 将 AIBridge 的四种调用统一为 types.Request/types.Response，依次经过中间件后再调用 inner。
+// 本次调用的模型选项从上下文读取（见 types.WithModelOptions、WithModelOptions），
+// 经中间件修改后继续通过上下文传给 inner。
+type MiddlewareClient struct {
+	inner   types.AIBridge
+	handler types.Handler
+}
+
+// NewMiddlewareClient 创建中间件客户端，第一个中间件位于最外层
+func NewMiddlewareClient(inner types.AIBridge, middlewares ...types.Middleware) *MiddlewareClient {
+	c := &MiddlewareClient{inner: inner}
+	c.handler = types.Chain(middlewares...)(c.invoke)
+	return c
+}
+
+// Chat 执行对话（非流式）
+func (c *MiddlewareClient) Chat(ctx context.Context, messages []*schema.Message) (*schema.Message, error) {
+	resp, err := c.handle(ctx, &types.Request{Op: types.OpChat, Messages: messages})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Message == nil {
+		return nil, fmt.Errorf("middleware returned no message for %s", types.OpChat)
+	}
+	return resp.Message, nil
+}
+
+// ChatStream 执行对话（流式）
+func (c *MiddlewareClient) ChatStream(ctx context.Context, messages []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+	resp, err := c.handle(ctx, &types.Request{Op: types.OpChatStream, Messages: messages})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Stream == nil {
+		return nil, fmt.Errorf("middleware returned no stream for %s", types.OpChatStream)
+	}
+	return resp.Stream, nil
+}
+
+// Generate 生成文本（简化接口）
+func (c *MiddlewareClient) Generate(ctx context.Context, prompt string) (string, error) {
+	resp, err := c.handle(ctx, &types.Request{Op: types.OpGenerate, Prompt: prompt})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// GenerateStream 生成文本（流式）
+func (c *MiddlewareClient) GenerateStream(ctx context.Context, prompt string) (string, error) {
+	resp, err := c.handle(ctx, &types.Request{Op: types.OpGenerateStream, Prompt: prompt})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// GetModelInfo 获取模型信息
+func (c *MiddlewareClient) GetModelInfo() *types.ModelInfo {
+	return c.inner.GetModelInfo()
+}
+
+// handle 补全请求并执行中间件链
+func (c *MiddlewareClient) handle(ctx context.Context, req *types.Request) (*types.Response, error) {
+	req.Info = c.inner.GetModelInfo()
+	req.Options = types.ModelOptionsFromContext(ctx)
+
+	resp, err := c.handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("middleware returned no response for %s", req.Op)
+	}
+	return resp, nil
+}
+
+// invoke 中间件链的末端，调用 inner
+func (c *MiddlewareClient) invoke(ctx context.Context, req *types.Request) (*types.Response, error) {
+	ctx = types.WithModelOptions(ctx, req.Options...)
+
+	switch req.Op {
+	case types.OpChat:
+		msg, err := c.inner.Chat(ctx, req.Messages)
+		if err != nil {
+			return nil, err
+		}
+		return &types.Response{Message: msg}, nil
+	case types.OpChatStream:
+		stream, err := c.inner.ChatStream(ctx, req.Messages)
+		if err != nil {
+			return nil, err
+		}
+		return &types.Response{Stream: stream}, nil
+	case types.OpGenerate:
+		text, err := c.inner.Generate(ctx, req.Prompt)
+		if err != nil {
+			return nil, err
+		}
+		return &types.Response{Text: text}, nil
+	case types.OpGenerateStream:
+		text, err := c.inner.GenerateStream(ctx, req.Prompt)
+		if err != nil {
+			return nil, err
+		}
+		return &types.Response{Text: text}, nil
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", req.Op)
+	}
+}
+
+// RecoverMiddleware 将中间件链和客户端调用中的 panic 转换为错误
+// 仅覆盖调用本身，流式响应的读取不在其中
+func RecoverMiddleware() types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (resp *types.Response, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					resp, err = nil, fmt.Errorf("panic in %s: %v", req.Op, r)
+				}
+			}()
+			return next(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+// recordMiddleware 记录调用顺序的测试中间件
+func recordMiddleware(name string, trace *[]string) types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			*trace = append(*trace, name+">")
+			resp, err := next(ctx, req)
+			*trace = append(*trace, "<"+name)
+			return resp, err
+		}
+	}
+}
+
+func TestMiddlewareClientOrderAndRewrite(t *testing.T) {
+	fake := newFakeBridge(fakeResponse{msg: schema.AssistantMessage("hello", nil)})
+	var trace []string
+
+	// 请求前追加系统消息，响应后转为大写
+	guard := func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			if req.Op != types.OpChat || req.Info == nil || req.Info.Name != "fake-model" {
+				t.Errorf("request = %+v", req)
+			}
+			req.Messages = append([]*schema.Message{schema.SystemMessage("be nice")}, req.Messages...)
+			resp, err := next(ctx, req)
+			if err == nil {
+				resp.Message.Content = strings.ToUpper(resp.Message.Content)
+			}
+			return resp, err
+		}
+	}
+	client := NewMiddlewareClient(fake, recordMiddleware("a", &trace), recordMiddleware("b", &trace), guard)
+
+	resp, err := client.Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil || resp.Content != "HELLO" {
+		t.Fatalf("Chat() = %v, %v", resp, err)
+	}
+	if got := strings.Join(trace, " "); got != "a> b> <b <a" {
+		t.Errorf("trace = %q", got)
+	}
+	if sent := fake.calls[0]; len(sent) != 2 || sent[0].Role != schema.System {
+		t.Errorf("inner messages = %v, want system message prepended", sent)
+	}
+}
+
+func TestMiddlewareClientShortCircuit(t *testing.T) {
+	fake := newFakeBridge()
+	blocked := func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			msg := schema.AssistantMessage("blocked", nil)
+			switch req.Op {
+			case types.OpChatStream:
+				return &types.Response{Stream: schema.StreamReaderFromArray([]*schema.Message{msg})}, nil
+			case types.OpGenerate, types.OpGenerateStream:
+				return &types.Response{Text: "blocked: " + req.Prompt}, nil
+			}
+			return &types.Response{Message: msg}, nil
+		}
+	}
+	client := NewMiddlewareClient(fake, blocked)
+	ctx := context.Background()
+
+	if resp, err := client.Chat(ctx, nil); err != nil || resp.Content != "blocked" {
+		t.Errorf("Chat() = %v, %v", resp, err)
+	}
+	stream, err := client.ChatStream(ctx, nil)
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	if content := readStream(t, newStreamReader(stream, nil, time.Now())); content != "blocked" {
+		t.Errorf("stream content = %q", content)
+	}
+	if text, err := client.Generate(ctx, "x"); err != nil || text != "blocked: x" {
+		t.Errorf("Generate() = %q, %v", text, err)
+	}
+	if fake.callCount() != 0 {
+		t.Errorf("inner calls = %d, want 0", fake.callCount())
+	}
+}
+
+func TestMiddlewareClientWrapsStream(t *testing.T) {
+	fake := newFakeBridge(fakeResponse{chunks: []*schema.Message{schema.AssistantMessage("a", nil), schema.AssistantMessage("b", nil)}})
+	upper := func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			resp, err := next(ctx, req)
+			if err != nil || resp.Stream == nil {
+				return resp, err
+			}
+			resp.Stream = schema.StreamReaderWithConvert(resp.Stream, func(msg *schema.Message) (*schema.Message, error) {
+				out := *msg
+				out.Content = strings.ToUpper(msg.Content)
+				return &out, nil
+			})
+			return resp, nil
+		}
+	}
+
+	stream, err := NewSDKClient(NewMiddlewareClient(fake, upper)).ChatStream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	if content := readStream(t, stream); content != "AB" {
+		t.Errorf("content = %q, want AB", content)
+	}
+}
+
+func TestMiddlewareClientModelOptions(t *testing.T) {
+	fake := newFakeBridge(fakeResponse{msg: schema.AssistantMessage("ok", nil)})
+
+	// 内层中间件客户端从上下文读取外层传下来的选项
+	var seen int
+	inner := NewMiddlewareClient(fake, func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			seen = len(req.Options)
+			return next(ctx, req)
+		}
+	})
+	outer := NewMiddlewareClient(inner, func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			if len(req.Options) != 1 {
+				t.Errorf("outer options = %d, want 1 from WithModelOptions", len(req.Options))
+			}
+			req.Options = append(req.Options, model.WithMaxTokens(16))
+			return next(ctx, req)
+		}
+	})
+
+	_, err := NewSDKClient(outer).Chat(context.Background(), []*schema.Message{schema.UserMessage("hi")},
+		WithStream(false), WithModelOptions(model.WithTemperature(0)))
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if seen != 2 {
+		t.Errorf("inner options = %d, want 2", seen)
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	panicking := func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			panic("boom")
+		}
+	}
+	client := NewMiddlewareClient(newFakeBridge(), RecoverMiddleware(), panicking)
+
+	if _, err := client.Chat(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Chat() error = %v, want recovered panic", err)
+	}
+}
+
+func TestMiddlewareClientNilResponse(t *testing.T) {
+	client := NewMiddlewareClient(newFakeBridge(), func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			return &types.Response{}, nil
+		}
+	})
+	if _, err := client.ChatStream(context.Background(), nil); err == nil {
+		t.Error("ChatStream() should fail when middleware returns no stream")
+	}
+}
+
+func TestNewAIClientWithMiddleware(t *testing.T) {
+	noop := func(next types.Handler) types.Handler { return next }
+
+	client, err := NewAIClient(types.ProviderOllama, "llama3", options.WithMiddleware(noop))
+	if err != nil {
+		t.Fatalf("NewAIClient() error: %v", err)
+	}
+	if _, ok := client.(*MiddlewareClient); !ok {
+		t.Errorf("NewAIClient() = %T, want *MiddlewareClient", client)
+	}
+
+	sdk := NewSDK(&SDKConfig{GPT: ProviderConfig{APIKey: "sk-test"}, Middlewares: []types.Middleware{noop}})
+	client, err = sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	if err != nil {
+		t.Fatalf("CreateClient() error: %v", err)
+	}
+	if _, ok := client.(*MiddlewareClient); !ok {
+		t.Errorf("CreateClient() = %T, want *MiddlewareClient", client)
+	}
+}
@@ -0,0 +1,113 @@
+package bridge
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/ratelimit"
+	"ai-bridge/pkg/types"
+)
+
+// RateLimitedClient 客户端侧限流包装器
+// 在调用前从所有限制器（厂商、模型、密钥）预约RPM和估算的TPM额度，
+// 调用结束后按厂商返回的实际token用量修正预扣。
+type RateLimitedClient struct {
+	inner     types.AIBridge
+	limiters  []*ratelimit.Limiter
+	mode      ratelimit.Mode
+	maxOutput int
+}
+
+// NewRateLimitedClient 创建限流客户端
+// mode 为默认限流模式（可通过 ratelimit.WithMode 或 WithRateLimitMode 按次覆盖），
+// maxOutput 为估算TPM时计入的最大输出token数，nil 限制器会被忽略
+func NewRateLimitedClient(inner types.AIBridge, mode ratelimit.Mode, maxOutput int, limiters ...*ratelimit.Limiter) *RateLimitedClient {
+	if mode == "" {
+		mode = ratelimit.ModeWait
+	}
+	return &RateLimitedClient{inner: inner, limiters: limiters, mode: mode, maxOutput: maxOutput}
+}
+
+// Chat 执行对话（非流式）
+func (c *RateLimitedClient) Chat(ctx context.Context, messages []*schema.Message) (*schema.Message, error) {
+	res, err := c.acquire(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.inner.Chat(ctx, messages)
+	if err != nil {
+		res.Settle(0)
+		return nil, err
+	}
+	if usage := usageFromMessage(resp); usage.TotalTokens > 0 {
+		res.Settle(usage.TotalTokens)
+	}
+	return resp, nil
+}
+
+// ChatStream 执行对话（流式），流结束时按最后返回的token用量修正预扣
+func (c *RateLimitedClient) ChatStream(ctx context.Context, messages []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+	res, err := c.acquire(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.inner.ChatStream(ctx, messages)
+	if err != nil {
+		res.Settle(0)
+		return nil, err
+	}
+
+	total := 0
+	return adapters.RelayStream(stream, func(msg *schema.Message) {
+		if usage := usageFromMessage(msg); usage.TotalTokens > 0 {
+			total = usage.TotalTokens
+		}
+	}, func(err error) {
+		if err == nil && total > 0 {
+			res.Settle(total)
+		}
+	}), nil
+}
+
+// Generate 生成文本（简化接口）
+func (c *RateLimitedClient) Generate(ctx context.Context, prompt string) (string, error) {
+	res, err := c.acquire(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if err != nil {
+		return "", err
+	}
+
+	result, err := c.inner.Generate(ctx, prompt)
+	if err != nil {
+		res.Settle(0)
+	}
+	return result, err
+}
+
+// GenerateStream 生成文本（流式）
+func (c *RateLimitedClient) GenerateStream(ctx context.Context, prompt string) (string, error) {
+	res, err := c.acquire(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if err != nil {
+		return "", err
+	}
+
+	result, err := c.inner.GenerateStream(ctx, prompt)
+	if err != nil {
+		res.Settle(0)
+	}
+	return result, err
+}
+
+// GetModelInfo 获取模型信息
+func (c *RateLimitedClient) GetModelInfo() *types.ModelInfo {
+	return c.inner.GetModelInfo()
+}
+
+// acquire 按本次调用的限流模式预约额度
+func (c *RateLimitedClient) acquire(ctx context.Context, messages []*schema.Message) (*ratelimit.Reservation, error) {
+	mode := ratelimit.ModeFromContext(ctx, c.mode)
+	return ratelimit.Acquire(ctx, mode, ratelimit.EstimateTokens(messages, c.maxOutput), c.limiters...)
+}
@@ -0,0 +1,113 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/ratelimit"
+	"ai-bridge/pkg/types"
+)
+
+// messageWithUsage 创建带token用量的助手消息
+func messageWithUsage(content string, total int) *schema.Message {
+	msg := schema.AssistantMessage(content, nil)
+	msg.ResponseMeta = &schema.ResponseMeta{Usage: &schema.TokenUsage{TotalTokens: total}}
+	return msg
+}
+
+func TestRateLimitedClient(t *testing.T) {
+	fake := newFakeBridge(
+		fakeResponse{msg: messageWithUsage("ok", 10)},
+		fakeResponse{chunks: []*schema.Message{schema.AssistantMessage("流", nil), messageWithUsage("式", 10)}},
+	)
+	limiter := ratelimit.NewLimiter("fake/fake-model", ratelimit.Limits{RPM: 2})
+	client := NewRateLimitedClient(fake, ratelimit.ModeFailFast, 100, limiter, nil)
+	messages := []*schema.Message{schema.UserMessage("hi")}
+
+	if _, err := client.Chat(context.Background(), messages); err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+
+	stream, err := client.ChatStream(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("ChatStream() error: %v", err)
+	}
+	var content string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		content += msg.Content
+	}
+	stream.Close()
+	if content != "流式" {
+		t.Errorf("stream content = %q, want 流式", content)
+	}
+
+	// 超出RPM：快速失败且不调用下游
+	_, err = client.Chat(context.Background(), messages)
+	var limitErr *ratelimit.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != "rpm" {
+		t.Fatalf("expected rpm LimitError, got %v", err)
+	}
+	if fake.callCount() != 2 {
+		t.Errorf("inner calls = %d, want 2", fake.callCount())
+	}
+
+	// 通过 ClientOption 按次切换为阻塞模式，上下文超时后返回
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, err = NewSDKClient(client).Chat(ctx, messages, WithRateLimitMode(ratelimit.ModeWait), WithTimeout(0))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded in wait mode, got %v", err)
+	}
+}
+
+func TestSDKSharedRateLimits(t *testing.T) {
+	sdk := NewSDK(&SDKConfig{GPT: ProviderConfig{
+		APIKey:          "sk-test",
+		RateLimit:       ratelimit.Limits{RPM: 100},
+		ModelRateLimits: map[string]ratelimit.Limits{"gpt-4o": {TPM: 10000}},
+		RateLimitMode:   ratelimit.ModeFailFast,
+	}})
+
+	a, err := sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	if err != nil {
+		t.Fatalf("CreateClient() error: %v", err)
+	}
+	b, _ := sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	mini, _ := sdk.CreateClient(types.ProviderGPT, "gpt-4o-mini")
+
+	limitedA, ok := a.(*RateLimitedClient)
+	if !ok {
+		t.Fatalf("CreateClient() = %T, want *RateLimitedClient", a)
+	}
+	limitedB := b.(*RateLimitedClient)
+	limitedMini := mini.(*RateLimitedClient)
+
+	if limitedA.mode != ratelimit.ModeFailFast {
+		t.Errorf("mode = %s, want fail_fast", limitedA.mode)
+	}
+	// 厂商级和模型级限制器在同模型客户端间共享，模型级限制器按模型区分
+	if limitedA.limiters[0] != limitedMini.limiters[0] || limitedA.limiters[1] != limitedB.limiters[1] {
+		t.Error("clients should share provider and model limiters")
+	}
+	if limitedMini.limiters[1] != nil || limitedA.limiters[2] != nil {
+		t.Error("unconfigured scopes should not be limited")
+	}
+
+	// 未配置限流时不包装
+	if c, _ := sdk.CreateClient(types.ProviderDeepseek, "deepseek-chat"); c != nil {
+		if _, ok := c.(*RateLimitedClient); ok {
+			t.Error("client without limits should not be wrapped")
+		}
+	}
+}
@@ -1,13 +1,22 @@
 package bridge
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cloudwego/eino/components/tool"
 
 	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/cache"
+	"ai-bridge/pkg/circuit"
 	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/ratelimit"
 	"ai-bridge/pkg/types"
 )
 
@@ -23,6 +32,8 @@ type SDKConfig struct {
 	Grok     ProviderConfig // xAI Grok配置
 	Deepseek ProviderConfig // Deepseek配置
 	Ollama   ProviderConfig // Ollama本地模型配置
+
+	Middlewares []types.Middleware // 调用中间件（可选，作用于SDK创建的所有客户端，位于最外层）
 }
 
 // ProviderConfig 厂商配置
@@ -30,34 +41,214 @@ type ProviderConfig struct {
 	APIKey      string        // API密钥
 	BaseURL     string        // 自定义API地址（可选）
 	Timeout     time.Duration // 请求超时（默认120s）
-	MaxRetries  int           // 最大重试次数（默认3）
+	MaxRetries  int           // 最大重试次数（默认3，配置 APIKeys 时401/403/429不重试而是换用其他密钥）
 	Temperature float32       // 温度参数（默认0.7）
 	TopP        float32       // Top P参数（默认0.9）
 	MaxTokens   int           // 最大Token数（默认2048）
-	Proxy       string        // 代理地址（可选）
+	Proxy       string        // 代理地址（可选，支持 http/https/socks5）
+
+	OnRetry func(ctx context.Context, event types.RetryEvent) // 重试钩子（可选，每次重试等待前调用，如 metrics.Metrics.OnRetry）
+
+	ExtraHeaders map[string]string // 额外请求头（可选）
+	Organization string            // OpenAI组织ID（可选）
+	CAFile       string            // 自定义CA证书文件（可选）
+	CertFile     string            // mTLS客户端证书文件（可选）
+	KeyFile      string            // mTLS客户端私钥文件（可选）
+
+	// 多密钥负载均衡（可选，配置后忽略 APIKey/Organization）
+	APIKeys     []Credential  // 凭证池
+	KeyStrategy KeyStrategy   // 选择策略（默认轮询）
+	KeyCooldown time.Duration // 密钥返回401/403/429后的冷却时间（默认1分钟）
+
+	// 客户端侧限流（可选，SDK创建的所有客户端共享额度）
+	RateLimit       ratelimit.Limits            // 厂商级限制（该厂商所有模型和密钥共享）
+	ModelRateLimits map[string]ratelimit.Limits // 模型级限制（按模型名）
+	KeyRateLimit    ratelimit.Limits            // 密钥级限制（每个密钥独立）
+	RateLimitMode   ratelimit.Mode              // 超出限制时的默认处理方式（默认阻塞等待）
+
+	CircuitBreaker *circuit.Settings // 熔断配置（可选，按 厂商/模型 熔断，SDK创建的所有客户端共享状态）
+
+	Cache    cache.Cache   // 响应缓存（可选，如 cache.NewMemoryCache、cache.NewDiskCache）
+	CacheTTL time.Duration // 缓存有效期（默认永不过期）
+
+	SemanticCache *cache.SemanticCache // 语义缓存（可选，可多个厂商共享，按 厂商/模型/系统提示词 隔离）
 }
 
 // SDK AI Bridge SDK
 type SDK struct {
 	config *SDKConfig
+
+	mu       sync.Mutex
+	pools    map[types.Provider]*KeyPool // 各厂商的密钥池（所有客户端共享）
+	limiters *ratelimit.Registry         // 限流器（所有客户端共享）
+	breakers *circuit.Registry           // 熔断器（所有客户端共享）
 }
 
 // NewSDK 创建SDK实例
 func NewSDK(config *SDKConfig) *SDK {
-	return &SDK{config: config}
+	return &SDK{
+		config:   config,
+		pools:    make(map[types.Provider]*KeyPool),
+		limiters: ratelimit.NewRegistry(),
+		breakers: circuit.NewRegistry(),
+	}
 }
 
 // CreateClient 创建AI客户端
+// 厂商配置了 APIKeys 时返回多密钥客户端，配置了限流时每个密钥的客户端都会被限流包装，
+// 配置了熔断时包装熔断客户端，配置了语义缓存时包装语义缓存客户端，配置了缓存时包装缓存客户端，
+// 配置了中间件时最外层为中间件客户端
 func (s *SDK) CreateClient(provider types.Provider, modelName string) (types.AIBridge, error) {
-	opts := s.buildOptions(provider)
-	return adapters.GetAdapter(provider, modelName, opts...)
+	return s.createClient(provider, modelName)
 }
 
 // CreateClientWithTools 创建带MCP工具的AI客户端
 func (s *SDK) CreateClientWithTools(provider types.Provider, modelName string, tools []tool.BaseTool) (types.AIBridge, error) {
-	opts := s.buildOptions(provider)
-	opts = append(opts, options.WithTools(tools...))
-	return adapters.GetAdapter(provider, modelName, opts...)
+	return s.createClient(provider, modelName, options.WithTools(tools...))
+}
+
+// KeyHealth 获取厂商密钥池中每个密钥的健康状态，未配置 APIKeys 时返回 nil
+func (s *SDK) KeyHealth(provider types.Provider) []KeyHealth {
+	s.mu.Lock()
+	pool := s.pools[provider]
+	s.mu.Unlock()
+
+	if pool == nil {
+		return nil
+	}
+	return pool.Health()
+}
+
+// CircuitStatus 获取SDK创建的所有熔断器状态（名称为 provider/model）
+func (s *SDK) CircuitStatus() []circuit.Status {
+	return s.breakers.Statuses()
+}
+
+// createClient 根据厂商配置创建客户端，配置了中间件时包装在最外层
+func (s *SDK) createClient(provider types.Provider, modelName string, extra ...options.Option) (types.AIBridge, error) {
+	client, err := s.createCachedClient(provider, modelName, extra...)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.config.Middlewares) > 0 {
+		return NewMiddlewareClient(client, s.config.Middlewares...), nil
+	}
+	return client, nil
+}
+
+// createCachedClient 根据厂商配置创建客户端，并按配置包装熔断、语义缓存和缓存
+func (s *SDK) createCachedClient(provider types.Provider, modelName string, extra ...options.Option) (types.AIBridge, error) {
+	client, err := s.createKeyedClient(provider, modelName, extra...)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := s.providerConfig(provider)
+	if cfg.CircuitBreaker != nil {
+		breaker := s.breakers.Get(string(provider)+"/"+modelName, *cfg.CircuitBreaker)
+		client = NewCircuitBreakerClient(client, breaker)
+	}
+	if cfg.Cache == nil && cfg.SemanticCache == nil {
+		return client, nil
+	}
+
+	config := types.DefaultConfig()
+	for _, opt := range append(s.buildOptions(provider), extra...) {
+		opt(config)
+	}
+	if cfg.SemanticCache != nil {
+		semantic := NewSemanticCachedClient(client, cfg.SemanticCache, config.SystemPrompt)
+		semantic.tools = semanticToolsHash(config)
+		client = semantic
+	}
+	if cfg.Cache == nil {
+		return client, nil
+	}
+
+	// 缓存位于最外层，精确命中时不计算向量，也不经过熔断和限流
+	params, err := cache.ParamsFromConfig(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := NewCachedClient(client, cfg.Cache, cfg.CacheTTL, &params)
+	if err != nil {
+		return nil, err
+	}
+	return cached, nil
+}
+
+// createKeyedClient 根据厂商配置创建单密钥或多密钥客户端
+func (s *SDK) createKeyedClient(provider types.Provider, modelName string, extra ...options.Option) (types.AIBridge, error) {
+	opts := append(s.buildOptions(provider), extra...)
+
+	pool, err := s.keyPool(provider)
+	if err != nil {
+		return nil, err
+	}
+	if pool == nil {
+		client, err := adapters.GetAdapter(provider, modelName, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return s.rateLimited(provider, modelName, s.providerConfig(provider).APIKey, client), nil
+	}
+
+	pooled, err := NewPooledClient(pool, func(cred Credential) (types.AIBridge, error) {
+		keyOpts := append(opts[:len(opts):len(opts)], options.WithAPIKey(cred.APIKey), options.WithRetryIf(RetryOnSameKey))
+		if cred.Organization != "" {
+			keyOpts = append(keyOpts, options.WithOrganization(cred.Organization))
+		}
+		client, err := adapters.GetAdapter(provider, modelName, keyOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return s.rateLimited(provider, modelName, cred.APIKey, client), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pooled, nil
+}
+
+// rateLimited 按厂商配置为客户端添加限流，未配置限流时原样返回
+// 厂商、模型、密钥三级限制器来自SDK共享的注册表，同一作用域的客户端共享额度
+func (s *SDK) rateLimited(provider types.Provider, modelName, apiKey string, client types.AIBridge) types.AIBridge {
+	cfg := s.providerConfig(provider)
+	limiters := []*ratelimit.Limiter{
+		s.limiters.Get(string(provider), cfg.RateLimit),
+		s.limiters.Get(string(provider)+"/"+modelName, cfg.ModelRateLimits[modelName]),
+		s.limiters.Get(string(provider)+"/key/"+keyID(apiKey), cfg.KeyRateLimit),
+	}
+	if limiters[0] == nil && limiters[1] == nil && limiters[2] == nil {
+		return client
+	}
+
+	maxOutput := cfg.MaxTokens
+	if maxOutput <= 0 {
+		maxOutput = types.DefaultConfig().MaxTokens
+	}
+	return NewRateLimitedClient(client, cfg.RateLimitMode, maxOutput, limiters...)
+}
+
+// keyPool 获取或创建厂商的密钥池，未配置 APIKeys 时返回 nil
+func (s *SDK) keyPool(provider types.Provider) (*KeyPool, error) {
+	cfg := s.providerConfig(provider)
+	if len(cfg.APIKeys) == 0 {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pool, ok := s.pools[provider]; ok {
+		return pool, nil
+	}
+	pool, err := NewKeyPool(cfg.APIKeys, cfg.KeyStrategy, cfg.KeyCooldown)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key pool for %s: %w", provider, err)
+	}
+	s.pools[provider] = pool
+	return pool, nil
 }
 
 // CreateSDKClient 创建支持高级Option的SDK客户端
@@ -79,8 +270,8 @@ func (s *SDK) CreateSDKClientWithTools(provider types.Provider, modelName string
 	return NewSDKClient(inner), nil
 }
 
-// buildOptions 根据厂商配置构建选项
-func (s *SDK) buildOptions(provider types.Provider) []options.Option {
+// providerConfig 获取厂商配置
+func (s *SDK) providerConfig(provider types.Provider) ProviderConfig {
 	var cfg ProviderConfig
 
 	switch provider {
@@ -105,6 +296,12 @@ func (s *SDK) buildOptions(provider types.Provider) []options.Option {
 	case types.ProviderOllama:
 		cfg = s.config.Ollama
 	}
+	return cfg
+}
+
+// buildOptions 根据厂商配置构建选项
+func (s *SDK) buildOptions(provider types.Provider) []options.Option {
+	cfg := s.providerConfig(provider)
 
 	opts := []options.Option{
 		options.WithAPIKey(cfg.APIKey),
@@ -119,6 +316,9 @@ func (s *SDK) buildOptions(provider types.Provider) []options.Option {
 	if cfg.MaxRetries > 0 {
 		opts = append(opts, options.WithMaxRetries(cfg.MaxRetries))
 	}
+	if cfg.OnRetry != nil {
+		opts = append(opts, options.WithOnRetry(cfg.OnRetry))
+	}
 	if cfg.Temperature > 0 {
 		opts = append(opts, options.WithTemperature(cfg.Temperature))
 	}
@@ -131,6 +331,18 @@ func (s *SDK) buildOptions(provider types.Provider) []options.Option {
 	if cfg.Proxy != "" {
 		opts = append(opts, options.WithProxy(cfg.Proxy))
 	}
+	if len(cfg.ExtraHeaders) > 0 {
+		opts = append(opts, options.WithExtraHeaders(cfg.ExtraHeaders))
+	}
+	if cfg.Organization != "" {
+		opts = append(opts, options.WithOrganization(cfg.Organization))
+	}
+	if cfg.CAFile != "" {
+		opts = append(opts, options.WithCAFile(cfg.CAFile))
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		opts = append(opts, options.WithClientCert(cfg.CertFile, cfg.KeyFile))
+	}
 
 	return opts
 }
@@ -139,31 +351,40 @@ func (s *SDK) buildOptions(provider types.Provider) []options.Option {
 func ConfigFromEnv() *SDKConfig {
 	return &SDKConfig{
 		GPT: ProviderConfig{
-			APIKey: os.Getenv("GPT_API_KEY"),
+			APIKey:  os.Getenv("GPT_API_KEY"),
+			APIKeys: ParseCredentials(os.Getenv("GPT_API_KEYS")),
 		},
 		QWen: ProviderConfig{
-			APIKey: os.Getenv("QWEN_API_KEY"),
+			APIKey:  os.Getenv("QWEN_API_KEY"),
+			APIKeys: ParseCredentials(os.Getenv("QWEN_API_KEYS")),
 		},
 		Kimi: ProviderConfig{
-			APIKey: os.Getenv("KIMI_API_KEY"),
+			APIKey:  os.Getenv("KIMI_API_KEY"),
+			APIKeys: ParseCredentials(os.Getenv("KIMI_API_KEYS")),
 		},
 		GLM: ProviderConfig{
-			APIKey: os.Getenv("GLM_API_KEY"),
+			APIKey:  os.Getenv("GLM_API_KEY"),
+			APIKeys: ParseCredentials(os.Getenv("GLM_API_KEYS")),
 		},
 		MiniMax: ProviderConfig{
-			APIKey: os.Getenv("MINIMAX_API_KEY"),
+			APIKey:  os.Getenv("MINIMAX_API_KEY"),
+			APIKeys: ParseCredentials(os.Getenv("MINIMAX_API_KEYS")),
 		},
 		Claude: ProviderConfig{
-			APIKey: os.Getenv("CLAUDE_API_KEY"),
+			APIKey:  os.Getenv("CLAUDE_API_KEY"),
+			APIKeys: ParseCredentials(os.Getenv("CLAUDE_API_KEYS")),
 		},
 		Gemini: ProviderConfig{
-			APIKey: os.Getenv("GEMINI_API_KEY"),
+			APIKey:  os.Getenv("GEMINI_API_KEY"),
+			APIKeys: ParseCredentials(os.Getenv("GEMINI_API_KEYS")),
 		},
 		Grok: ProviderConfig{
-			APIKey: os.Getenv("GROK_API_KEY"),
+			APIKey:  os.Getenv("GROK_API_KEY"),
+			APIKeys: ParseCredentials(os.Getenv("GROK_API_KEYS")),
 		},
 		Deepseek: ProviderConfig{
-			APIKey: os.Getenv("DEEPSEEK_API_KEY"),
+			APIKey:  os.Getenv("DEEPSEEK_API_KEY"),
+			APIKeys: ParseCredentials(os.Getenv("DEEPSEEK_API_KEYS")),
 		},
 		Ollama: ProviderConfig{
 			BaseURL: getEnvOrDefault("OLLAMA_BASE_URL", "http://localhost:11434"),
@@ -171,6 +392,23 @@ func ConfigFromEnv() *SDKConfig {
 	}
 }
 
+// keyID 密钥的短哈希，用作限流作用域（避免在作用域名称中暴露密钥）
+func keyID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:6])
+}
+
+// ParseCredentials 解析逗号分隔的密钥列表（如 "sk-a,sk-b"），空字符串返回 nil
+func ParseCredentials(keys string) []Credential {
+	var creds []Credential
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			creds = append(creds, Credential{APIKey: key})
+		}
+	}
+	return creds
+}
+
 // getEnvOrDefault 获取环境变量，如果不存在返回默认值
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,188 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/cache"
+	"ai-bridge/pkg/types"
+)
+
+// SemanticCachedClient 语义缓存客户端
+// 对最后一条用户消息计算向量，在同一厂商、模型、系统提示词、之前的对话轮次和工具集合范围内查找相似问题，
+// 相似度达到阈值时直接返回已缓存的回答；最后一条消息不是用户消息时不使用缓存。
+// 可通过 WithCacheBypass 按次跳过，通过 WithCacheTags 为写入的条目附加标签。
+type SemanticCachedClient struct {
+	inner        types.AIBridge
+	cache        *cache.SemanticCache
+	systemPrompt string
+	tools        string // 适配器绑定的工具集合哈希
+}
+
+// NewSemanticCachedClient 创建语义缓存客户端
+// systemPrompt 为适配器配置的系统提示词，消息中包含系统消息时以消息为准；
+// inner 为适配器时可传空字符串，系统提示词和工具自动从适配器配置中读取
+func NewSemanticCachedClient(inner types.AIBridge, sc *cache.SemanticCache, systemPrompt string) *SemanticCachedClient {
+	c := &SemanticCachedClient{inner: inner, cache: sc, systemPrompt: systemPrompt}
+	if cfg, ok := inner.(configurer); ok && cfg.GetConfig() != nil {
+		if c.systemPrompt == "" {
+			c.systemPrompt = cfg.GetConfig().SystemPrompt
+		}
+		c.tools = semanticToolsHash(cfg.GetConfig())
+	}
+	return c
+}
+
+// semanticToolsHash 计算配置中工具集合的哈希，获取工具信息失败时返回不可能命中的占位值
+func semanticToolsHash(cfg *types.Config) string {
+	params, err := cache.ParamsFromConfig(context.Background(), cfg)
+	if err != nil {
+		return "invalid"
+	}
+	hash, err := cache.ToolsHash(params.Tools)
+	if err != nil {
+		return "invalid"
+	}
+	return hash
+}
+
+// Cache 获取语义缓存（用于查看统计、按标签失效）
+func (c *SemanticCachedClient) Cache() *cache.SemanticCache {
+	return c.cache
+}
+
+// Chat 执行对话（非流式），命中缓存时不调用厂商
+func (c *SemanticCachedClient) Chat(ctx context.Context, messages []*schema.Message) (*schema.Message, error) {
+	l := c.lookup(ctx, messages)
+	if l.hit != nil {
+		return l.hit, nil
+	}
+
+	resp, err := c.inner.Chat(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	c.store(ctx, l, resp)
+	return resp, nil
+}
+
+// ChatStream 执行对话（流式）
+// 命中缓存时回放缓存的完整消息；未命中时在流正常结束后写入缓存
+func (c *SemanticCachedClient) ChatStream(ctx context.Context, messages []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+	l := c.lookup(ctx, messages)
+	if l.hit != nil {
+		return schema.StreamReaderFromArray([]*schema.Message{l.hit}), nil
+	}
+
+	stream, err := c.inner.ChatStream(ctx, messages)
+	if err != nil || l.vector == nil {
+		return stream, err
+	}
+
+	var chunks []*schema.Message
+	return adapters.RelayStream(stream, func(msg *schema.Message) {
+		chunks = append(chunks, msg)
+	}, func(err error) {
+		if err != nil || len(chunks) == 0 {
+			return
+		}
+		if full, err := schema.ConcatMessages(chunks); err == nil {
+			c.store(ctx, l, full)
+		}
+	}), nil
+}
+
+// Generate 生成文本（简化接口）
+func (c *SemanticCachedClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return c.generate(ctx, prompt, c.inner.Generate)
+}
+
+// GenerateStream 生成文本（流式）
+func (c *SemanticCachedClient) GenerateStream(ctx context.Context, prompt string) (string, error) {
+	return c.generate(ctx, prompt, c.inner.GenerateStream)
+}
+
+// GetModelInfo 获取模型信息
+func (c *SemanticCachedClient) GetModelInfo() *types.ModelInfo {
+	return c.inner.GetModelInfo()
+}
+
+// generate Generate/GenerateStream 的缓存逻辑（与 Chat 共享缓存）
+func (c *SemanticCachedClient) generate(ctx context.Context, prompt string, call func(context.Context, string) (string, error)) (string, error) {
+	l := c.lookup(ctx, []*schema.Message{schema.UserMessage(prompt)})
+	if l.hit != nil {
+		return l.hit.Content, nil
+	}
+
+	result, err := call(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	c.store(ctx, l, schema.AssistantMessage(result, nil))
+	return result, nil
+}
+
+// semanticLookup 一次查找的结果，未命中时保留向量用于写入
+type semanticLookup struct {
+	scope  cache.SemanticScope
+	query  string
+	vector []float64
+	hit    *schema.Message
+}
+
+// lookup 查找语义缓存；跳过缓存、带有按次模型选项、没有用户问题或向量计算失败时返回空结果（不写入）
+func (c *SemanticCachedClient) lookup(ctx context.Context, messages []*schema.Message) semanticLookup {
+	var l semanticLookup
+	if cache.IsBypassed(ctx) || len(types.ModelOptionsFromContext(ctx)) > 0 || len(messages) == 0 {
+		return l
+	}
+	last := messages[len(messages)-1]
+	if last == nil || last.Role != schema.User || strings.TrimSpace(last.Content) == "" {
+		return l
+	}
+
+	l.scope = cache.SemanticScope{SystemPrompt: c.systemPrompt, Tools: c.tools}
+	if info := c.inner.GetModelInfo(); info != nil {
+		l.scope.Provider, l.scope.Model = info.Provider, info.Name
+	}
+	var prior []*schema.Message
+	systemFound := false
+	for _, msg := range messages[:len(messages)-1] {
+		if msg != nil && msg.Role == schema.System {
+			if !systemFound {
+				l.scope.SystemPrompt = msg.Content
+				systemFound = true
+			}
+			continue
+		}
+		prior = append(prior, msg)
+	}
+	history, err := cache.HistoryHash(prior)
+	if err != nil {
+		return semanticLookup{}
+	}
+	l.scope.History = history
+	l.query = strings.TrimSpace(last.Content)
+
+	match, vector, err := c.cache.Lookup(ctx, l.scope, l.query)
+	if err != nil {
+		return semanticLookup{}
+	}
+	l.vector = vector
+	if match.Entry != nil && match.Entry.Message != nil {
+		l.hit = cacheHitMessage(match.Entry.Message)
+	}
+	return l
+}
+
+// store 写入语义缓存，工具调用和被内容过滤拦截的响应不缓存
+func (c *SemanticCachedClient) store(ctx context.Context, l semanticLookup, msg *schema.Message) {
+	if l.vector == nil || msg == nil || len(msg.ToolCalls) > 0 || isContentFiltered(msg) {
+		return
+	}
+	c.cache.Add(l.scope, l.vector, l.query, &cache.Entry{Message: cloneMessage(msg), CreatedAt: time.Now()}, cache.TagsFromContext(ctx)...)
+}
@@ -0,0 +1,162 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/cache"
+	"ai-bridge/pkg/types"
+)
+
+// vectorEmbedder 按文本返回固定向量的测试用向量模型，未知文本返回正交向量
+type vectorEmbedder map[string][]float64
+
+func (v vectorEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	out := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		if vec, ok := v[text]; ok {
+			out = append(out, vec)
+		} else {
+			out = append(out, []float64{0, 0, 1})
+		}
+	}
+	return out, nil
+}
+
+// infoTool 只提供描述信息的测试用工具
+type infoTool schema.ToolInfo
+
+func (t *infoTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return (*schema.ToolInfo)(t), nil
+}
+
+var testEmbedder = vectorEmbedder{
+	"什么是Go语言": {1, 0, 0},
+	"Go语言是什么": {0.99, 0.1, 0},
+	"今天天气怎么样": {0, 1, 0},
+}
+
+func TestSemanticCachedClientChat(t *testing.T) {
+	fake := newNamedFakeBridge(types.ProviderGPT, "gpt-4o",
+		fakeResponse{msg: schema.AssistantMessage("Go是一门编程语言", nil)},
+		fakeResponse{msg: schema.AssistantMessage("Go是Google开发的语言", nil)},
+		fakeResponse{msg: schema.AssistantMessage("晴", nil)},
+	)
+	sc := cache.NewSemanticCache(testEmbedder, cache.SemanticConfig{})
+	sdk := NewSDKClient(NewSemanticCachedClient(fake, sc, ""))
+	ctx := context.Background()
+
+	if _, err := sdk.Chat(ctx, []*schema.Message{schema.UserMessage("什么是Go语言")}, WithStream(false), WithCacheTags("doc:go")); err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	// 最后一条用户消息相似，命中缓存
+	hit, err := sdk.Chat(ctx, []*schema.Message{schema.UserMessage("Go语言是什么")}, WithStream(false))
+	if err != nil || hit.Content != "Go是一门编程语言" || !hit.Cached {
+		t.Fatalf("similar Chat() = %+v, %v, want cached answer", hit, err)
+	}
+	// 之前的对话轮次不同，回答可能依赖上下文，不共享缓存
+	multiTurn := []*schema.Message{schema.UserMessage("你好"), schema.AssistantMessage("你好！", nil), schema.UserMessage("Go语言是什么")}
+	if miss, err := sdk.Chat(ctx, multiTurn, WithStream(false)); err != nil || miss.Cached {
+		t.Fatalf("Chat() with history = %+v, %v, want miss", miss, err)
+	}
+	if miss, err := sdk.Chat(ctx, []*schema.Message{schema.UserMessage("今天天气怎么样")}, WithStream(false)); err != nil || miss.Cached {
+		t.Fatalf("unrelated Chat() = %+v, %v", miss, err)
+	}
+	if fake.callCount() != 3 {
+		t.Errorf("inner calls = %d, want 3", fake.callCount())
+	}
+
+	if n := sc.InvalidateTag("doc:go"); n != 1 {
+		t.Errorf("InvalidateTag() = %d, want 1", n)
+	}
+	if stats := sc.Stats(); stats.Hits != 1 || stats.Misses != 3 || stats.Entries != 2 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestSemanticCachedClientScopeAndSkip(t *testing.T) {
+	fake := newNamedFakeBridge(types.ProviderGPT, "gpt-4o",
+		fakeResponse{msg: schema.AssistantMessage("a", nil)},
+		fakeResponse{msg: schema.AssistantMessage("b", nil)},
+		fakeResponse{msg: schema.AssistantMessage("c", nil)},
+		fakeResponse{msg: schema.AssistantMessage("d", nil)},
+		fakeResponse{msg: schema.AssistantMessage("e", nil)},
+	)
+	client := NewSemanticCachedClient(fake, cache.NewSemanticCache(testEmbedder, cache.SemanticConfig{}), "你是助手")
+	ctx := context.Background()
+
+	client.Chat(ctx, []*schema.Message{schema.UserMessage("什么是Go语言")})
+
+	// 系统提示词不同，不共享缓存
+	resp, _ := client.Chat(ctx, []*schema.Message{schema.SystemMessage("你是翻译"), schema.UserMessage("什么是Go语言")})
+	if CacheHit(resp) {
+		t.Error("different system prompt should miss")
+	}
+	// 绑定的工具不同，不共享缓存
+	withTools := NewSemanticCachedClient(fake, client.Cache(), "你是助手")
+	withTools.tools = semanticToolsHash(&types.Config{Tools: []tool.BaseTool{&infoTool{Name: "search", Desc: "搜索"}}})
+	resp, _ = withTools.Chat(ctx, []*schema.Message{schema.UserMessage("什么是Go语言")})
+	if CacheHit(resp) {
+		t.Error("different tool set should miss")
+	}
+	// 跳过缓存
+	resp, _ = client.Chat(cache.WithBypass(ctx), []*schema.Message{schema.UserMessage("什么是Go语言")})
+	if CacheHit(resp) || resp.Content != "d" {
+		t.Errorf("bypassed Chat() = %v", resp)
+	}
+	// 最后一条不是用户消息时不使用缓存
+	resp, _ = client.Chat(ctx, []*schema.Message{schema.UserMessage("什么是Go语言"), schema.ToolMessage("result", "call-1")})
+	if CacheHit(resp) || resp.Content != "e" {
+		t.Errorf("tool turn Chat() = %v", resp)
+	}
+}
+
+func TestSemanticCachedClientStream(t *testing.T) {
+	fake := newNamedFakeBridge(types.ProviderGPT, "gpt-4o",
+		fakeResponse{chunks: []*schema.Message{schema.AssistantMessage("Go是", nil), schema.AssistantMessage("编程语言", nil)}},
+	)
+	client := NewSemanticCachedClient(fake, cache.NewSemanticCache(testEmbedder, cache.SemanticConfig{}), "")
+	sdk := NewSDKClient(client)
+
+	for i, question := range []string{"什么是Go语言", "Go语言是什么"} {
+		stream, err := sdk.ChatStream(context.Background(), []*schema.Message{schema.UserMessage(question)})
+		if err != nil {
+			t.Fatalf("ChatStream(%d) error: %v", i, err)
+		}
+		if content := readStream(t, stream); content != "Go是编程语言" {
+			t.Fatalf("stream %d content = %q", i, content)
+		}
+		if result := stream.Result(); result == nil || result.Cached != (i == 1) {
+			t.Errorf("stream %d result = %+v", i, result)
+		}
+	}
+
+	// Generate 与 Chat 共享缓存
+	if out, err := client.Generate(context.Background(), "什么是Go语言"); err != nil || out != "Go是编程语言" {
+		t.Errorf("Generate() = %q, %v", out, err)
+	}
+	if fake.callCount() != 1 {
+		t.Errorf("inner calls = %d, want 1", fake.callCount())
+	}
+}
+
+func TestSDKCreateClientWithSemanticCache(t *testing.T) {
+	sc := cache.NewSemanticCache(testEmbedder, cache.SemanticConfig{})
+	sdk := NewSDK(&SDKConfig{GPT: ProviderConfig{APIKey: "sk-test", SemanticCache: sc, Cache: cache.NewMemoryCache(10)}})
+
+	client, err := sdk.CreateClient(types.ProviderGPT, "gpt-4o")
+	if err != nil {
+		t.Fatalf("CreateClient() error: %v", err)
+	}
+	cached, ok := client.(*CachedClient)
+	if !ok {
+		t.Fatalf("CreateClient() = %T, want *CachedClient", client)
+	}
+	if semantic, ok := cached.inner.(*SemanticCachedClient); !ok || semantic.Cache() != sc {
+		t.Errorf("inner = %T, want *SemanticCachedClient", cached.inner)
+	}
+}
@@ -0,0 +1,41 @@
+// Package cache 提供对话响应缓存
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Entry 缓存条目
+type Entry struct {
+	Message   *schema.Message `json:"message"`    // 完整的助手消息
+	CreatedAt time.Time       `json:"created_at"` // 写入时间
+}
+
+// Cache 缓存存储接口，实现需并发安全
+type Cache interface {
+	// Get 获取缓存条目，不存在或已过期时返回 false
+	Get(ctx context.Context, key string) (*Entry, bool, error)
+
+	// Set 写入缓存条目，ttl <= 0 表示永不过期
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error
+
+	// Delete 删除缓存条目
+	Delete(ctx context.Context, key string) error
+}
+
+// bypassKey 上下文中跳过缓存标记的键
+type bypassKey struct{}
+
+// WithBypass 标记本次调用跳过缓存（不读取，也不写入）
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// IsBypassed 本次调用是否跳过缓存
+func IsBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
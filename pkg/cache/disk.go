@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// diskRecord 磁盘缓存文件内容
+type diskRecord struct {
+	Entry     *Entry    `json:"entry"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // 零值表示永不过期
+}
+
+// DiskCache 磁盘缓存
+// 每个条目保存为 <dir>/<key前两位>/<key>.json，进程重启后仍然有效，
+// 适合评测和CI流水线在多次运行间复用响应。
+// 不是小写十六进制的 key（非 Key 生成）先做 SHA-256 再用作文件名，避免路径穿越出缓存目录。
+type DiskCache struct {
+	dir string
+	now func() time.Time
+}
+
+// NewDiskCache 创建磁盘缓存，目录不存在时自动创建
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	return &DiskCache{dir: dir, now: time.Now}, nil
+}
+
+// Get 获取缓存条目，过期条目会被删除
+func (c *DiskCache) Get(ctx context.Context, key string) (*Entry, bool, error) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var record diskRecord
+	if err := json.Unmarshal(data, &record); err != nil || record.Entry == nil {
+		// 损坏的条目视为未命中并清理
+		os.Remove(path)
+		return nil, false, nil
+	}
+	if !record.ExpiresAt.IsZero() && !c.now().Before(record.ExpiresAt) {
+		os.Remove(path)
+		return nil, false, nil
+	}
+	return record.Entry, true, nil
+}
+
+// Set 写入缓存条目（先写临时文件再重命名，避免并发读到不完整内容）
+func (c *DiskCache) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	record := diskRecord{Entry: entry}
+	if ttl > 0 {
+		record.ExpiresAt = c.now().Add(ttl)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除缓存条目
+func (c *DiskCache) Delete(ctx context.Context, key string) error {
+	err := os.Remove(c.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// path 获取条目文件路径
+func (c *DiskCache) path(key string) string {
+	name := fileName(key)
+	return filepath.Join(c.dir, name[:2], name+".json")
+}
+
+// fileName 将 key 转为安全的文件名：小写十六进制（至少两位）原样使用，其他 key 取 SHA-256
+func fileName(key string) string {
+	if len(key) >= 2 && isLowerHex(key) {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// isLowerHex 判断字符串是否只包含小写十六进制字符
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
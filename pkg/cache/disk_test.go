@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error: %v", err)
+	}
+
+	if _, ok, err := c.Get(ctx, "abcdef"); ok || err != nil {
+		t.Fatalf("Get() on empty cache = %v, %v", ok, err)
+	}
+	if err := c.Set(ctx, "abcdef", newEntry("hello"), 0); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ab", "abcdef.json")); err != nil {
+		t.Errorf("entry file not found: %v", err)
+	}
+
+	// 新实例读取同一目录（模拟进程重启）
+	reopened, _ := NewDiskCache(dir)
+	entry, ok, err := reopened.Get(ctx, "abcdef")
+	if err != nil || !ok || entry.Message.Content != "hello" {
+		t.Fatalf("Get() = %v, %v, %v", entry, ok, err)
+	}
+
+	if err := c.Delete(ctx, "abcdef"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if _, ok, _ := c.Get(ctx, "abcdef"); ok {
+		t.Error("deleted entry should miss")
+	}
+	if err := c.Delete(ctx, "abcdef"); err != nil {
+		t.Errorf("Delete() of missing entry error: %v", err)
+	}
+}
+
+func TestDiskCacheUnsafeKey(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	dir := filepath.Join(root, "a", "b")
+	c, _ := NewDiskCache(dir)
+
+	for _, key := range []string{"../../x", "/etc/x", "x", "ABCDEF"} {
+		if err := c.Set(ctx, key, newEntry(key), 0); err != nil {
+			t.Fatalf("Set(%q) error: %v", key, err)
+		}
+		if rel, err := filepath.Rel(dir, c.path(key)); err != nil || strings.HasPrefix(rel, "..") {
+			t.Errorf("path(%q) = %s, escapes cache dir", key, c.path(key))
+		}
+		entry, ok, err := c.Get(ctx, key)
+		if err != nil || !ok || entry.Message.Content != key {
+			t.Errorf("Get(%q) = %v, %v, %v", key, entry, ok, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "x.json")); !os.IsNotExist(err) {
+		t.Errorf("entry written outside cache dir: %v", err)
+	}
+}
+
+func TestDiskCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	c, _ := NewDiskCache(t.TempDir())
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "abcdef", newEntry("hello"), time.Minute)
+	now = now.Add(time.Minute)
+	if _, ok, _ := c.Get(ctx, "abcdef"); ok {
+		t.Error("expected miss after ttl")
+	}
+	if _, err := os.Stat(c.path("abcdef")); !os.IsNotExist(err) {
+		t.Error("expired entry file should be removed")
+	}
+
+	// 损坏的条目视为未命中
+	os.WriteFile(c.path("abcdef"), []byte("{bad"), 0o644)
+	if _, ok, err := c.Get(ctx, "abcdef"); ok || err != nil {
+		t.Errorf("Get() on corrupt entry = %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/types"
+)
+
+// Params 影响响应内容的调用参数
+type Params struct {
+	Temperature  float32            `json:"temperature"`
+	TopP         float32            `json:"top_p"`
+	MaxTokens    int                `json:"max_tokens"`
+	SystemPrompt string             `json:"system_prompt,omitempty"`
+	Tools        []*schema.ToolInfo `json:"tools,omitempty"`
+}
+
+// ParamsFromConfig 从客户端配置提取缓存参数（包括工具的名称和参数schema）
+func ParamsFromConfig(ctx context.Context, cfg *types.Config) (Params, error) {
+	if cfg == nil {
+		return Params{}, nil
+	}
+	params := Params{
+		Temperature:  cfg.Temperature,
+		TopP:         cfg.TopP,
+		MaxTokens:    cfg.MaxTokens,
+		SystemPrompt: cfg.SystemPrompt,
+	}
+	for _, t := range cfg.Tools {
+		if t == nil {
+			continue
+		}
+		info, err := t.Info(ctx)
+		if err != nil {
+			return Params{}, fmt.Errorf("failed to get tool info: %w", err)
+		}
+		params.Tools = append(params.Tools, info)
+	}
+	return params, nil
+}
+
+// KeyInput 缓存键的组成部分
+type KeyInput struct {
+	Provider types.Provider
+	Model    string
+	Op       string // 调用类型，如 chat、generate
+	Messages []*schema.Message
+	Params   Params
+}
+
+// normalizedMessage 归一化后的消息
+// 只保留影响响应的字段，去掉厂商生成的ID、响应元数据和首尾空白
+type normalizedMessage struct {
+	Role         schema.RoleType           `json:"role"`
+	Content      string                    `json:"content,omitempty"`
+	Name         string                    `json:"name,omitempty"`
+	MultiContent []schema.ChatMessagePart  `json:"multi_content,omitempty"`
+	InputParts   []schema.MessageInputPart `json:"input_parts,omitempty"`
+	ToolCalls    []normalizedToolCall      `json:"tool_calls,omitempty"`
+	ToolName     string                    `json:"tool_name,omitempty"`
+}
+
+// normalizedToolCall 归一化后的工具调用
+type normalizedToolCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Key 计算缓存键（归一化内容的SHA-256）
+func Key(in KeyInput) (string, error) {
+	messages := normalizeMessages(in.Messages)
+
+	data, err := json.Marshal(struct {
+		Provider types.Provider      `json:"provider"`
+		Model    string              `json:"model"`
+		Op       string              `json:"op"`
+		Messages []normalizedMessage `json:"messages"`
+		Params   Params              `json:"params"`
+	}{in.Provider, in.Model, in.Op, messages, in.Params})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeMessages 归一化消息列表，跳过 nil 消息
+func normalizeMessages(in []*schema.Message) []normalizedMessage {
+	messages := make([]normalizedMessage, 0, len(in))
+	for _, msg := range in {
+		if msg == nil {
+			continue
+		}
+		n := normalizedMessage{
+			Role:         msg.Role,
+			Content:      strings.TrimSpace(msg.Content),
+			Name:         msg.Name,
+			MultiContent: msg.MultiContent,
+			InputParts:   msg.UserInputMultiContent,
+			ToolName:     msg.ToolName,
+		}
+		for _, call := range msg.ToolCalls {
+			n.ToolCalls = append(n.ToolCalls, normalizedToolCall{
+				Name:      call.Function.Name,
+				Arguments: strings.TrimSpace(call.Function.Arguments),
+			})
+		}
+		messages = append(messages, n)
+	}
+	return messages
+}
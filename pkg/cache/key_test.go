@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/types"
+)
+
+// staticTool 只提供描述信息的测试工具
+type staticTool struct{ info *schema.ToolInfo }
+
+func (s staticTool) Info(ctx context.Context) (*schema.ToolInfo, error) { return s.info, nil }
+
+func TestKeyNormalization(t *testing.T) {
+	base := KeyInput{
+		Provider: types.ProviderGPT,
+		Model:    "gpt-4o",
+		Op:       "chat",
+		Messages: []*schema.Message{schema.SystemMessage("be brief"), schema.UserMessage("hello")},
+		Params:   Params{Temperature: 0.7},
+	}
+	key, err := Key(base)
+	if err != nil {
+		t.Fatalf("Key() error: %v", err)
+	}
+
+	// 首尾空白、响应元数据和工具调用ID不影响缓存键
+	same := base
+	assistant := schema.AssistantMessage("hi ", []schema.ToolCall{{ID: "call_1", Function: schema.FunctionCall{Name: "f", Arguments: "{}"}}})
+	assistant.ResponseMeta = &schema.ResponseMeta{FinishReason: "stop"}
+	same.Messages = []*schema.Message{schema.SystemMessage("be brief\n"), schema.UserMessage("  hello")}
+	if k, _ := Key(same); k != key {
+		t.Error("whitespace should be normalized")
+	}
+	withCall := base
+	withCall.Messages = append(append([]*schema.Message{}, base.Messages...), assistant)
+	otherCall := base
+	otherAssistant := *assistant
+	otherAssistant.ToolCalls = []schema.ToolCall{{ID: "call_2", Function: schema.FunctionCall{Name: "f", Arguments: "{}"}}}
+	otherAssistant.ResponseMeta = nil
+	otherCall.Messages = append(append([]*schema.Message{}, base.Messages...), &otherAssistant)
+	k1, _ := Key(withCall)
+	k2, _ := Key(otherCall)
+	if k1 != k2 {
+		t.Error("tool call IDs and response meta should not affect the key")
+	}
+
+	variants := map[string]KeyInput{}
+	v := base
+	v.Model = "gpt-4o-mini"
+	variants["model"] = v
+	v = base
+	v.Provider = types.ProviderDeepseek
+	variants["provider"] = v
+	v = base
+	v.Params.Temperature = 0
+	variants["temperature"] = v
+	v = base
+	v.Op = "generate"
+	variants["op"] = v
+	v = base
+	v.Params.Tools = []*schema.ToolInfo{{Name: "search"}}
+	variants["tools"] = v
+	for name, in := range variants {
+		if k, _ := Key(in); k == key {
+			t.Errorf("changing %s should change the key", name)
+		}
+	}
+}
+
+func TestParamsFromConfig(t *testing.T) {
+	cfg := types.DefaultConfig()
+	cfg.SystemPrompt = "sys"
+	cfg.Tools = []tool.BaseTool{staticTool{info: &schema.ToolInfo{Name: "search", Desc: "web search"}}}
+
+	params, err := ParamsFromConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("ParamsFromConfig() error: %v", err)
+	}
+	if params.Temperature != 0.7 || params.MaxTokens != 2048 || params.SystemPrompt != "sys" {
+		t.Errorf("params = %+v", params)
+	}
+	if len(params.Tools) != 1 || params.Tools[0].Name != "search" {
+		t.Errorf("tools = %+v", params.Tools)
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryCapacity 内存缓存默认容量（条目数）
+const DefaultMemoryCapacity = 1000
+
+// memoryItem 内存缓存项
+type memoryItem struct {
+	key       string
+	entry     *Entry
+	expiresAt time.Time // 零值表示永不过期
+}
+
+// MemoryCache 内存LRU缓存（并发安全）
+// 超出容量时淘汰最久未使用的条目
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // 头部为最近使用
+	now      func() time.Time
+}
+
+// NewMemoryCache 创建内存LRU缓存，capacity <= 0 时使用 DefaultMemoryCapacity
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = DefaultMemoryCapacity
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get 获取缓存条目
+func (c *MemoryCache) Get(ctx context.Context, key string) (*Entry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	item := elem.Value.(*memoryItem)
+	if !item.expiresAt.IsZero() && !c.now().Before(item.expiresAt) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return item.entry, true, nil
+}
+
+// Set 写入缓存条目
+func (c *MemoryCache) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item := &memoryItem{key: key, entry: entry}
+	if ttl > 0 {
+		item.expiresAt = c.now().Add(ttl)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value = item
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(item)
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Delete 删除缓存条目
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	return nil
+}
+
+// Len 返回当前条目数（包括尚未清理的过期条目）
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove 移除条目（调用方持有锁）
+func (c *MemoryCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*memoryItem).key)
+}
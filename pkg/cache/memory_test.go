@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// newEntry 创建测试用缓存条目
+func newEntry(content string) *Entry {
+	return &Entry{Message: schema.AssistantMessage(content, nil), CreatedAt: time.Now()}
+}
+
+func TestMemoryCacheLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	c.Set(ctx, "a", newEntry("A"), 0)
+	c.Set(ctx, "b", newEntry("B"), 0)
+	if _, ok, _ := c.Get(ctx, "a"); !ok { // a 变为最近使用
+		t.Fatal("expected hit for a")
+	}
+	c.Set(ctx, "c", newEntry("C"), 0)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("least recently used entry b should be evicted")
+	}
+	if entry, ok, _ := c.Get(ctx, "a"); !ok || entry.Message.Content != "A" {
+		t.Errorf("Get(a) = %v, %v", entry, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+
+	c.Delete(ctx, "a")
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("deleted entry should miss")
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(0)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "k", newEntry("v"), time.Minute)
+	if _, ok, _ := c.Get(ctx, "k"); !ok {
+		t.Fatal("expected hit before ttl")
+	}
+	now = now.Add(time.Minute)
+	if _, ok, _ := c.Get(ctx, "k"); ok {
+		t.Error("expected miss after ttl")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expired entry should be removed, Len() = %d", c.Len())
+	}
+}
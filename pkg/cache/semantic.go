@@ -0,0 +1,309 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/types"
+)
+
+// 语义缓存默认配置
+const (
+	DefaultSemanticThreshold  = 0.95
+	DefaultSemanticMaxEntries = 10000
+)
+
+// SemanticScope 语义缓存的作用域，只在同一作用域内匹配
+// 只比较最后一个问题的语义，之前的对话轮次和可用工具不同时回答也可能不同，因此计入作用域。
+type SemanticScope struct {
+	Provider     types.Provider
+	Model        string
+	SystemPrompt string
+	History      string // 之前对话轮次的哈希（见 HistoryHash，单轮对话为空）
+	Tools        string // 工具集合的哈希（见 ToolsHash，没有工具为空）
+}
+
+// key 作用域键（系统提示词取哈希）
+func (s SemanticScope) key() string {
+	sum := sha256.Sum256([]byte(s.SystemPrompt))
+	return string(s.Provider) + "/" + s.Model + "/" + hex.EncodeToString(sum[:8]) + "/" + s.History + "/" + s.Tools
+}
+
+// HistoryHash 计算之前对话轮次的哈希（与缓存键相同的归一化规则），没有消息时返回空字符串
+func HistoryHash(messages []*schema.Message) (string, error) {
+	normalized := normalizeMessages(messages)
+	if len(normalized) == 0 {
+		return "", nil
+	}
+	return scopeHash(normalized)
+}
+
+// ToolsHash 计算工具集合（名称、说明、参数schema）的哈希，没有工具时返回空字符串
+func ToolsHash(tools []*schema.ToolInfo) (string, error) {
+	if len(tools) == 0 {
+		return "", nil
+	}
+	return scopeHash(tools)
+}
+
+// scopeHash 计算作用域组成部分的短哈希
+func scopeHash(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode semantic scope: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// SemanticConfig 语义缓存配置
+type SemanticConfig struct {
+	Threshold  float64       // 余弦相似度阈值（默认0.95）
+	TTL        time.Duration // 条目有效期（默认永不过期）
+	MaxEntries int           // 每个作用域的最大条目数，超出时淘汰最早写入的条目（默认10000）
+}
+
+// SemanticStats 语义缓存统计
+type SemanticStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+	Entries int     `json:"entries"`
+}
+
+// SemanticMatch 语义缓存查询结果
+type SemanticMatch struct {
+	Entry      *Entry  // 命中的条目（未命中时为 nil）
+	Query      string  // 命中条目对应的原始问题
+	Similarity float64 // 最高相似度
+}
+
+// semanticEntry 语义缓存条目
+type semanticEntry struct {
+	vector    []float64 // 已归一化，余弦相似度即点积
+	query     string
+	entry     *Entry
+	tags      []string
+	expiresAt time.Time
+}
+
+// SemanticCache 语义缓存（并发安全）
+// 使用进程内向量索引（暴力检索），问题向量与已缓存问题的余弦相似度超过阈值时返回已缓存的回答。
+type SemanticCache struct {
+	embedder embedding.Embedder
+	config   SemanticConfig
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string][]*semanticEntry // 作用域键 -> 条目（按写入顺序）
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewSemanticCache 创建语义缓存，embedder 可使用 adapters.NewOllamaEmbedder 离线运行
+func NewSemanticCache(embedder embedding.Embedder, config SemanticConfig) *SemanticCache {
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultSemanticThreshold
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultSemanticMaxEntries
+	}
+	return &SemanticCache{
+		embedder: embedder,
+		config:   config,
+		now:      time.Now,
+		entries:  make(map[string][]*semanticEntry),
+	}
+}
+
+// Embed 计算文本的归一化向量
+func (c *SemanticCache) Embed(ctx context.Context, text string) ([]float64, error) {
+	vectors, err := c.embedder.EmbedStrings(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 || len(vectors[0]) == 0 {
+		return nil, fmt.Errorf("embedder returned no vector")
+	}
+	return normalize(vectors[0]), nil
+}
+
+// Search 在作用域内查找与向量最相似的条目，相似度达到阈值时命中，并计入命中/未命中统计
+func (c *SemanticCache) Search(scope SemanticScope, vector []float64) SemanticMatch {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := c.now()
+	var best SemanticMatch
+	var bestEntry *semanticEntry
+	for _, e := range c.entries[scope.key()] {
+		if !e.expiresAt.IsZero() && !now.Before(e.expiresAt) {
+			continue
+		}
+		if sim := dot(vector, e.vector); bestEntry == nil || sim > best.Similarity {
+			best.Similarity, bestEntry = sim, e
+		}
+	}
+
+	if bestEntry != nil && best.Similarity >= c.config.Threshold {
+		best.Entry, best.Query = bestEntry.entry, bestEntry.query
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return best
+}
+
+// Add 写入条目，tags 用于之后按标签失效（如文档ID、知识库版本）
+func (c *SemanticCache) Add(scope SemanticScope, vector []float64, query string, entry *Entry, tags ...string) {
+	e := &semanticEntry{vector: vector, query: query, entry: entry, tags: tags}
+	if c.config.TTL > 0 {
+		e.expiresAt = c.now().Add(c.config.TTL)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := scope.key()
+	list := c.pruneExpired(c.entries[key])
+	list = append(list, e)
+	if over := len(list) - c.config.MaxEntries; over > 0 {
+		list = list[over:]
+	}
+	c.entries[key] = list
+}
+
+// Lookup 计算问题向量并查找，返回查询结果和向量（未命中时可用于 Add）
+func (c *SemanticCache) Lookup(ctx context.Context, scope SemanticScope, query string) (SemanticMatch, []float64, error) {
+	vector, err := c.Embed(ctx, query)
+	if err != nil {
+		return SemanticMatch{}, nil, err
+	}
+	return c.Search(scope, vector), vector, nil
+}
+
+// InvalidateTag 删除所有带有指定标签的条目，返回删除数量
+func (c *SemanticCache) InvalidateTag(tag string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, list := range c.entries {
+		kept := list[:0]
+		for _, e := range list {
+			if hasTag(e.tags, tag) {
+				removed++
+				continue
+			}
+			kept = append(kept, e)
+		}
+		if len(kept) == 0 {
+			delete(c.entries, key)
+		} else {
+			c.entries[key] = kept
+		}
+	}
+	return removed
+}
+
+// Clear 清空所有条目（不重置统计）
+func (c *SemanticCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string][]*semanticEntry)
+}
+
+// Stats 获取命中/未命中统计
+func (c *SemanticCache) Stats() SemanticStats {
+	c.mu.RLock()
+	entries := 0
+	for _, list := range c.entries {
+		entries += len(list)
+	}
+	c.mu.RUnlock()
+
+	stats := SemanticStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Entries: entries}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// pruneExpired 移除过期条目（调用方持有写锁）
+func (c *SemanticCache) pruneExpired(list []*semanticEntry) []*semanticEntry {
+	if c.config.TTL <= 0 {
+		return list
+	}
+	now := c.now()
+	kept := list[:0]
+	for _, e := range list {
+		if now.Before(e.expiresAt) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// normalize 向量归一化（零向量原样返回）
+func normalize(v []float64) []float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	norm := math.Sqrt(sum)
+	if norm == 0 {
+		return v
+	}
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+// dot 点积，维度不一致时返回 0
+func dot(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// hasTag 判断标签列表是否包含指定标签
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// tagsKey 上下文中缓存标签的键
+type tagsKey struct{}
+
+// WithTags 为本次调用写入的缓存条目附加标签
+func WithTags(ctx context.Context, tags ...string) context.Context {
+	return context.WithValue(ctx, tagsKey{}, tags)
+}
+
+// TagsFromContext 获取上下文中的缓存标签
+func TagsFromContext(ctx context.Context) []string {
+	tags, _ := ctx.Value(tagsKey{}).([]string)
+	return tags
+}
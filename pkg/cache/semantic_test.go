@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+)
+
+// fakeEmbedder 按文本返回固定向量的测试用向量模型
+type fakeEmbedder struct {
+	vectors map[string][]float64
+	calls   int
+}
+
+func (f *fakeEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	f.calls++
+	out := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		v, ok := f.vectors[text]
+		if !ok {
+			return nil, errors.New("unknown text: " + text)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func newFakeEmbedder() *fakeEmbedder {
+	return &fakeEmbedder{vectors: map[string][]float64{
+		"什么是Go语言": {1, 0, 0},
+		"Go语言是什么": {0.99, 0.1, 0},
+		"今天天气怎么样": {0, 1, 0},
+	}}
+}
+
+func TestSemanticCacheLookup(t *testing.T) {
+	ctx := context.Background()
+	c := NewSemanticCache(newFakeEmbedder(), SemanticConfig{})
+	scope := SemanticScope{Provider: "gpt", Model: "gpt-4o", SystemPrompt: "你是助手"}
+
+	match, vector, err := c.Lookup(ctx, scope, "什么是Go语言")
+	if err != nil || match.Entry != nil {
+		t.Fatalf("Lookup() on empty cache = %+v, %v", match, err)
+	}
+	c.Add(scope, vector, "什么是Go语言", newEntry("Go是一门编程语言"))
+
+	match, _, _ = c.Lookup(ctx, scope, "Go语言是什么")
+	if match.Entry == nil || match.Entry.Message.Content != "Go是一门编程语言" || match.Query != "什么是Go语言" {
+		t.Fatalf("similar query should hit, got %+v", match)
+	}
+	if match.Similarity < DefaultSemanticThreshold || match.Similarity > 1+1e-9 {
+		t.Errorf("Similarity = %v", match.Similarity)
+	}
+
+	if match, _, _ = c.Lookup(ctx, scope, "今天天气怎么样"); match.Entry != nil {
+		t.Errorf("unrelated query should miss, got %+v", match)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 1 || math.Abs(stats.HitRate-1.0/3) > 1e-9 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestSemanticCacheScope(t *testing.T) {
+	ctx := context.Background()
+	c := NewSemanticCache(newFakeEmbedder(), SemanticConfig{})
+	scope := SemanticScope{Provider: "gpt", Model: "gpt-4o", SystemPrompt: "你是助手"}
+
+	_, vector, _ := c.Lookup(ctx, scope, "什么是Go语言")
+	c.Add(scope, vector, "什么是Go语言", newEntry("answer"))
+
+	for _, other := range []SemanticScope{
+		{Provider: "claude", Model: "gpt-4o", SystemPrompt: "你是助手"},
+		{Provider: "gpt", Model: "gpt-4o-mini", SystemPrompt: "你是助手"},
+		{Provider: "gpt", Model: "gpt-4o", SystemPrompt: "你是翻译"},
+	} {
+		if match, _, _ := c.Lookup(ctx, other, "什么是Go语言"); match.Entry != nil {
+			t.Errorf("scope %+v should not see entries of %+v", other, scope)
+		}
+	}
+}
+
+func TestSemanticCacheInvalidateTag(t *testing.T) {
+	ctx := context.Background()
+	c := NewSemanticCache(newFakeEmbedder(), SemanticConfig{})
+	scope := SemanticScope{Provider: "gpt", Model: "gpt-4o"}
+
+	_, v1, _ := c.Lookup(ctx, scope, "什么是Go语言")
+	_, v2, _ := c.Lookup(ctx, scope, "今天天气怎么样")
+	c.Add(scope, v1, "什么是Go语言", newEntry("go"), "doc:go", "kb:v1")
+	c.Add(scope, v2, "今天天气怎么样", newEntry("sunny"), "kb:v1")
+
+	if n := c.InvalidateTag("doc:go"); n != 1 {
+		t.Errorf("InvalidateTag(doc:go) = %d, want 1", n)
+	}
+	if match, _, _ := c.Lookup(ctx, scope, "Go语言是什么"); match.Entry != nil {
+		t.Error("invalidated entry should miss")
+	}
+	if match, _, _ := c.Lookup(ctx, scope, "今天天气怎么样"); match.Entry == nil {
+		t.Error("entry without the tag should still hit")
+	}
+	if n := c.InvalidateTag("kb:v1"); n != 1 || c.Stats().Entries != 0 {
+		t.Errorf("InvalidateTag(kb:v1) = %d, entries = %d", n, c.Stats().Entries)
+	}
+}
+
+func TestSemanticCacheTTLAndMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := NewSemanticCache(newFakeEmbedder(), SemanticConfig{TTL: time.Minute, MaxEntries: 1})
+	c.now = func() time.Time { return now }
+	scope := SemanticScope{Provider: "gpt", Model: "gpt-4o"}
+
+	_, v1, _ := c.Lookup(ctx, scope, "什么是Go语言")
+	_, v2, _ := c.Lookup(ctx, scope, "今天天气怎么样")
+	c.Add(scope, v1, "什么是Go语言", newEntry("go"))
+	c.Add(scope, v2, "今天天气怎么样", newEntry("sunny"))
+
+	if match, _, _ := c.Lookup(ctx, scope, "什么是Go语言"); match.Entry != nil {
+		t.Error("oldest entry should be evicted when MaxEntries is exceeded")
+	}
+	if match, _, _ := c.Lookup(ctx, scope, "今天天气怎么样"); match.Entry == nil {
+		t.Fatal("latest entry should hit")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if match, _, _ := c.Lookup(ctx, scope, "今天天气怎么样"); match.Entry != nil {
+		t.Error("expired entry should miss")
+	}
+}
+
+func TestSemanticCacheEmbedError(t *testing.T) {
+	c := NewSemanticCache(newFakeEmbedder(), SemanticConfig{})
+	if _, _, err := c.Lookup(context.Background(), SemanticScope{}, "unknown"); err == nil {
+		t.Error("Lookup() should return the embedder error")
+	}
+}
+
+func TestSemanticScopeHistoryAndTools(t *testing.T) {
+	ctx := context.Background()
+	c := NewSemanticCache(newFakeEmbedder(), SemanticConfig{})
+
+	history, _ := HistoryHash([]*schema.Message{schema.UserMessage("你好"), schema.AssistantMessage("你好！", nil)})
+	if empty, _ := HistoryHash(nil); empty != "" || history == "" {
+		t.Fatalf("HistoryHash() = %q, empty = %q", history, empty)
+	}
+	tools, _ := ToolsHash([]*schema.ToolInfo{{Name: "search", Desc: "搜索"}})
+	if tools == "" {
+		t.Fatal("ToolsHash() should not be empty")
+	}
+
+	scope := SemanticScope{Provider: "gpt", Model: "gpt-4o"}
+	_, vector, _ := c.Lookup(ctx, scope, "什么是Go语言")
+	c.Add(scope, vector, "什么是Go语言", newEntry("answer"))
+
+	for _, other := range []SemanticScope{
+		{Provider: "gpt", Model: "gpt-4o", History: history},
+		{Provider: "gpt", Model: "gpt-4o", Tools: tools},
+	} {
+		if match, _, _ := c.Lookup(ctx, other, "什么是Go语言"); match.Entry != nil {
+			t.Errorf("scope %+v should not see entries of %+v", other, scope)
+		}
+	}
+}
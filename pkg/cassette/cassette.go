@@ -0,0 +1,111 @@
+// Package cassette 录制和回放上游厂商的HTTP交互
+// Recorder 作为底层传输注入任意适配器（options.WithTransportWrapper 或 Recorder.Option），
+// 录制时保存请求与响应（包括SSE流）到脱敏后的YAML文件，回放时按请求匹配返回录制的响应，
+// 无需密钥和网络即可确定性地运行测试。
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// formatVersion 磁带文件格式版本
+const formatVersion = 1
+
+// ErrInteractionNotFound 回放时磁带中没有匹配的交互
+var ErrInteractionNotFound = errors.New("cassette: no matching interaction")
+
+// Mode 录制模式
+type Mode string
+
+const (
+	ModeReplay Mode = "replay" // 仅回放，没有匹配的交互时返回 ErrInteractionNotFound
+	ModeRecord Mode = "record" // 全部请求发往上游并重新录制（覆盖原磁带）
+	ModeAuto   Mode = "auto"   // 有匹配的交互时回放，否则请求上游并追加录制
+)
+
+// ParseMode 解析录制模式（空字符串为 ModeReplay）
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return ModeReplay, nil
+	case ModeReplay, ModeRecord, ModeAuto:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid cassette mode: %s", s)
+	}
+}
+
+// Cassette 磁带文件
+type Cassette struct {
+	Version      int            `yaml:"version"`
+	Interactions []*Interaction `yaml:"interactions"`
+}
+
+// Interaction 一次HTTP交互
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+// Request 录制的请求（已脱敏）
+type Request struct {
+	Method  string              `yaml:"method"`
+	URL     string              `yaml:"url"`
+	Headers map[string][]string `yaml:"headers,omitempty"`
+	Body    string              `yaml:"body,omitempty"`
+}
+
+// Response 录制的响应（已脱敏，流式响应为完整的SSE文本）
+type Response struct {
+	StatusCode int                 `yaml:"status_code"`
+	Headers    map[string][]string `yaml:"headers,omitempty"`
+	Body       string              `yaml:"body,omitempty"`
+}
+
+// Load 加载磁带文件
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c Cassette
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if c.Version > formatVersion {
+		return nil, fmt.Errorf("unsupported cassette version %d in %s", c.Version, path)
+	}
+	return &c, nil
+}
+
+// Save 保存磁带文件（自动创建目录）
+func (c *Cassette) Save(path string) error {
+	c.Version = formatVersion
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// header 转换为 http.Header
+func header(h map[string][]string) http.Header {
+	result := make(http.Header, len(h))
+	for k, v := range h {
+		result[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+	}
+	return result
+}
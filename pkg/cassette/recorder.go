@@ -0,0 +1,380 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"ai-bridge/pkg/options"
+)
+
+// redactedValue 脱敏后的占位符
+const redactedValue = "[REDACTED]"
+
+// DefaultIgnoredFields 匹配请求体时默认忽略的易变JSON字段（任意层级）
+var DefaultIgnoredFields = []string{"id", "created", "created_at", "timestamp", "request_id"}
+
+// sensitiveHeaders 录制时脱敏的请求/响应头
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "X-Api-Key", "X-Goog-Api-Key", "Api-Key", "Openai-Organization", "Cookie", "Set-Cookie"}
+
+// droppedHeaders 不录制的易变请求/响应头
+var droppedHeaders = []string{"Traceparent", "Tracestate", "Baggage", "Date", "Content-Length"}
+
+// sensitiveParams 录制时脱敏的URL查询参数
+var sensitiveParams = []string{"key", "api_key", "apikey", "access_token"}
+
+// Option 录制器选项
+type Option func(*Recorder)
+
+// WithIgnoredFields 匹配请求体时额外忽略的JSON字段
+func WithIgnoredFields(fields ...string) Option {
+	return func(r *Recorder) {
+		for _, f := range fields {
+			r.ignored[f] = true
+		}
+	}
+}
+
+// WithSecrets 录制时从URL、请求体和响应体中脱敏的内容（如API Key）
+func WithSecrets(secrets ...string) Option {
+	return func(r *Recorder) {
+		for _, s := range secrets {
+			if s != "" {
+				r.secrets = append(r.secrets, s)
+			}
+		}
+	}
+}
+
+// Recorder 录制/回放HTTP交互（并发安全）
+// 请求按方法、脱敏后的URL和忽略易变字段后的请求体匹配；相同请求按录制顺序依次回放，
+// 用完后重复回放最后一次匹配的交互。
+type Recorder struct {
+	path    string
+	mode    Mode
+	ignored map[string]bool
+	secrets []string
+
+	mu       sync.Mutex
+	cassette *Cassette
+	keys     []matchKey
+	used     []bool
+	dirty    bool
+}
+
+// New 创建录制器
+// ModeReplay 要求磁带文件存在；ModeAuto 在文件存在时加载；ModeRecord 从空磁带开始
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:     path,
+		mode:     mode,
+		ignored:  make(map[string]bool),
+		cassette: &Cassette{},
+	}
+	for _, f := range DefaultIgnoredFields {
+		r.ignored[f] = true
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	switch mode {
+	case ModeReplay, ModeAuto:
+		c, err := Load(path)
+		if err != nil {
+			if mode == ModeAuto && errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+			return nil, err
+		}
+		r.cassette = c
+	case ModeRecord:
+	default:
+		return nil, fmt.Errorf("invalid cassette mode: %s", mode)
+	}
+
+	for _, it := range r.cassette.Interactions {
+		r.keys = append(r.keys, r.key(it.Request.Method, it.Request.URL, []byte(it.Request.Body)))
+		r.used = append(r.used, false)
+	}
+	return r, nil
+}
+
+// Mode 返回录制模式
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Wrap 包装底层传输，可直接用作 options.WithTransportWrapper 的参数
+func (r *Recorder) Wrap(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{recorder: r, base: base}
+}
+
+// Option 返回将录制器注入适配器的配置选项
+func (r *Recorder) Option() options.Option {
+	return options.WithTransportWrapper(r.Wrap)
+}
+
+// Stop 保存新录制的交互（未录制时不写文件）
+// 流式响应在读取结束或关闭时才会录制，应在所有响应处理完后调用
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.dirty {
+		return nil
+	}
+	if err := r.cassette.Save(r.path); err != nil {
+		return err
+	}
+	r.dirty = false
+	return nil
+}
+
+// matchKey 请求匹配键
+type matchKey struct {
+	method string
+	url    string
+	body   string
+}
+
+// key 计算匹配键（URL和请求体先脱敏，与录制内容保持一致）
+func (r *Recorder) key(method, rawURL string, body []byte) matchKey {
+	return matchKey{
+		method: strings.ToUpper(method),
+		url:    r.redactURL(rawURL),
+		body:   r.normalizeBody(r.redact(string(body))),
+	}
+}
+
+// find 查找匹配的交互：优先返回第一个未使用的，否则返回最后一个匹配的
+func (r *Recorder) find(key matchKey) *Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, k := range r.keys {
+		if k != key {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return r.cassette.Interactions[i]
+		}
+		last = i
+	}
+	if last >= 0 {
+		return r.cassette.Interactions[last]
+	}
+	return nil
+}
+
+// add 追加新录制的交互
+func (r *Recorder) add(key matchKey, it *Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, it)
+	r.keys = append(r.keys, key)
+	r.used = append(r.used, true)
+	r.dirty = true
+}
+
+// transport 绑定底层传输的录制器
+type transport struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := t.recorder
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		// RoundTripper 不应修改原请求
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	key := r.key(req.Method, req.URL.String(), body)
+	if r.mode != ModeRecord {
+		if it := r.find(key); it != nil {
+			return it.Response.toHTTP(req), nil
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, key.method, key.url)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	it := &Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     key.url,
+			Headers: r.redactHeaders(req.Header),
+			Body:    r.redact(string(body)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    r.redactHeaders(resp.Header),
+		},
+	}
+	if resp.Body == nil {
+		r.add(key, it)
+		return resp, nil
+	}
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		done: func(data []byte) {
+			it.Response.Body = r.redact(string(data))
+			r.add(key, it)
+		},
+	}
+	return resp, nil
+}
+
+// toHTTP 转换为回放的 http.Response
+func (resp Response) toHTTP(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header(resp.Headers),
+		Body:          io.NopCloser(strings.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
+
+// recordingBody 边转发边记录响应体，读到结尾或关闭时录制（流式响应逐块转发）
+type recordingBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func([]byte)
+}
+
+// Read 实现 io.Reader 接口
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+// Close 实现 io.Closer 接口，提前关闭时录制已读取的部分
+func (b *recordingBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+// finish 录制一次
+func (b *recordingBody) finish() {
+	b.once.Do(func() {
+		b.done(b.buf.Bytes())
+	})
+}
+
+// redact 将内容中出现的密钥替换为占位符
+func (r *Recorder) redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+	return s
+}
+
+// redactURL 脱敏URL中的密钥和敏感查询参数
+func (r *Recorder) redactURL(rawURL string) string {
+	u, err := url.Parse(r.redact(rawURL))
+	if err != nil {
+		return r.redact(rawURL)
+	}
+	query := u.Query()
+	for _, name := range sensitiveParams {
+		if query.Has(name) {
+			query.Set(name, redactedValue)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// redactHeaders 复制请求/响应头，脱敏敏感字段并去掉易变字段
+func (r *Recorder) redactHeaders(h http.Header) map[string][]string {
+	if len(h) == 0 {
+		return nil
+	}
+	result := make(map[string][]string, len(h))
+	for k, v := range h {
+		result[k] = append([]string(nil), v...)
+	}
+	for _, k := range droppedHeaders {
+		delete(result, k)
+	}
+	for _, k := range sensitiveHeaders {
+		if _, ok := result[k]; ok {
+			result[k] = []string{redactedValue}
+		}
+	}
+	for _, v := range result {
+		for i := range v {
+			v[i] = r.redact(v[i])
+		}
+	}
+	return result
+}
+
+// normalizeBody 去掉JSON请求体中的易变字段并规范化格式，非JSON内容原样返回
+func (r *Recorder) normalizeBody(body string) string {
+	var v any
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+	data, err := json.Marshal(r.stripIgnored(v))
+	if err != nil {
+		return body
+	}
+	return string(data)
+}
+
+// stripIgnored 递归删除忽略的字段
+func (r *Recorder) stripIgnored(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if r.ignored[k] {
+				delete(val, k)
+				continue
+			}
+			val[k] = r.stripIgnored(item)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = r.stripIgnored(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,231 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+func TestRecorder_RecordAndReplayAdapter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"stream":true`) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, chunk := range []string{"Hel", "lo"} {
+				fmt.Fprintf(w, "data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"model\":\"gpt-4o\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", chunk)
+				w.(http.Flusher).Flush()
+			}
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		fmt.Fprint(w, `{"id":"c0","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"recorded"}}]}`)
+	}))
+
+	path := filepath.Join(t.TempDir(), "gpt.yaml")
+	ctx := context.Background()
+	messages := []*schema.Message{schema.UserMessage("hi")}
+
+	run := func(rec *Recorder, apiKey string) (string, string) {
+		t.Helper()
+		adapter, err := adapters.NewGPTAdapter(types.ProviderGPT, "gpt-4o",
+			options.WithAPIKey(apiKey),
+			options.WithBaseURL(server.URL),
+			options.WithMaxRetries(0),
+			rec.Option(),
+		)
+		if err != nil {
+			t.Fatalf("NewGPTAdapter() error: %v", err)
+		}
+		resp, err := adapter.Chat(ctx, messages)
+		if err != nil {
+			t.Fatalf("Chat() error: %v", err)
+		}
+		stream, err := adapter.ChatStream(ctx, messages)
+		if err != nil {
+			t.Fatalf("ChatStream() error: %v", err)
+		}
+		defer stream.Close()
+		var content strings.Builder
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Recv() error: %v", err)
+			}
+			content.WriteString(chunk.Content)
+		}
+		return resp.Content, content.String()
+	}
+
+	// 录制
+	rec, err := New(path, ModeRecord, WithSecrets("sk-secret"))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	chat, stream := run(rec, "sk-secret")
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if chat != "recorded" || stream != "Hello" {
+		t.Fatalf("record: chat = %q, stream = %q", chat, stream)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	if strings.Contains(string(data), "sk-secret") || strings.Contains(string(data), "session=secret") {
+		t.Errorf("cassette contains secrets:\n%s", data)
+	}
+	if !strings.Contains(string(data), "data: [DONE]") {
+		t.Errorf("cassette missing SSE body:\n%s", data)
+	}
+
+	// 回放：上游已关闭，密钥不同
+	server.Close()
+	rec, err = New(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	chat, stream = run(rec, "other-key")
+	if chat != "recorded" || stream != "Hello" {
+		t.Errorf("replay: chat = %q, stream = %q", chat, stream)
+	}
+}
+
+func TestRecorder_Matching(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, "response %d", calls)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "match.yaml")
+	post := func(rec *Recorder, body string) (string, error) {
+		t.Helper()
+		client := &http.Client{Transport: rec.Wrap(nil)}
+		resp, err := client.Post(server.URL+"/v1/chat?key=secret", "application/json", strings.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		return string(data), err
+	}
+
+	rec, err := New(path, ModeRecord)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	for _, body := range []string{`{"id":"a","q":"x"}`, `{"id":"b","q":"x"}`, `{"q":"y"}`} {
+		if _, err := post(rec, body); err != nil {
+			t.Fatalf("post() error: %v", err)
+		}
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "key=secret") {
+		t.Errorf("cassette contains api key query:\n%s", data)
+	}
+
+	rec, err = New(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	// 忽略易变字段，相同请求按录制顺序回放，用完后重复最后一个
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"q":"y","created":123}`, "response 3"},
+		{`{"q":"x","id":"z"}`, "response 1"},
+		{`{"q": "x"}`, "response 2"},
+		{`{"q":"x"}`, "response 2"},
+	}
+	for _, tt := range tests {
+		got, err := post(rec, tt.body)
+		if err != nil || got != tt.want {
+			t.Errorf("post(%s) = %q, %v; want %q", tt.body, got, err, tt.want)
+		}
+	}
+	if _, err := post(rec, `{"q":"z"}`); !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("expected ErrInteractionNotFound, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("upstream calls = %d, want 3", calls)
+	}
+}
+
+func TestRecorder_AutoMode(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "auto.yaml")
+	get := func(p string) {
+		t.Helper()
+		rec, err := New(path, ModeAuto)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		resp, err := (&http.Client{Transport: rec.Wrap(nil)}).Get(server.URL + p)
+		if err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := rec.Stop(); err != nil {
+			t.Fatalf("Stop() error: %v", err)
+		}
+	}
+
+	get("/a")
+	get("/a")
+	get("/b")
+	if calls != 2 {
+		t.Errorf("upstream calls = %d, want 2", calls)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(c.Interactions) != 2 {
+		t.Errorf("interactions = %d, want 2", len(c.Interactions))
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]Mode{"": ModeReplay, "record": ModeRecord, " Auto ": ModeAuto} {
+		if got, err := ParseMode(in); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseMode("rewind"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+	if _, err := New(filepath.Join(t.TempDir(), "missing.yaml"), ModeReplay); err == nil {
+		t.Error("expected error for missing cassette in replay mode")
+	}
+}
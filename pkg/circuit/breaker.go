@@ -0,0 +1,344 @@
+// Package circuit 提供按厂商/模型的熔断器
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// State 熔断器状态
+type State string
+
+const (
+	StateClosed   State = "closed"    // 正常放行，统计错误率和慢调用比例
+	StateOpen     State = "open"      // 熔断，拒绝所有请求
+	StateHalfOpen State = "half_open" // 放行少量试探请求，成功则恢复，失败则重新熔断
+)
+
+// ErrOpen 熔断器处于打开状态（可通过 errors.Is 判断）
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Settings 熔断器配置，零值字段使用默认值
+type Settings struct {
+	Window           time.Duration // 统计窗口（默认60s）
+	MinRequests      int           // 窗口内达到该请求数才判断是否熔断（默认10）
+	FailureRate      float64       // 错误率阈值（默认0.5）
+	SlowCallDuration time.Duration // 超过该耗时视为慢调用（默认0，不统计）
+	SlowCallRate     float64       // 慢调用比例阈值（默认0.8）
+	OpenTimeout      time.Duration // 熔断持续时间，之后进入半开状态（默认30s）
+	HalfOpenRequests int           // 半开状态允许的试探请求数，全部成功后恢复（默认1）
+
+	// OnStateChange 状态变化回调（可选，在锁外同步调用）
+	OnStateChange func(name string, from, to State)
+}
+
+// withDefaults 填充默认值
+func (s Settings) withDefaults() Settings {
+	if s.Window <= 0 {
+		s.Window = 60 * time.Second
+	}
+	if s.MinRequests <= 0 {
+		s.MinRequests = 10
+	}
+	if s.FailureRate <= 0 {
+		s.FailureRate = 0.5
+	}
+	if s.SlowCallRate <= 0 {
+		s.SlowCallRate = 0.8
+	}
+	if s.OpenTimeout <= 0 {
+		s.OpenTimeout = 30 * time.Second
+	}
+	if s.HalfOpenRequests <= 0 {
+		s.HalfOpenRequests = 1
+	}
+	return s
+}
+
+// Status 熔断器状态快照
+type Status struct {
+	Name        string    `json:"name"`
+	State       State     `json:"state"`
+	Requests    int       `json:"requests"`   // 当前窗口内的请求数
+	Failures    int       `json:"failures"`   // 当前窗口内的失败数
+	SlowCalls   int       `json:"slow_calls"` // 当前窗口内的慢调用数
+	FailureRate float64   `json:"failure_rate"`
+	LastError   string    `json:"last_error,omitempty"`
+	OpenedAt    time.Time `json:"opened_at,omitzero"`
+}
+
+// Breaker 熔断器（并发安全）
+type Breaker struct {
+	mu       sync.Mutex
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	state       State
+	generation  uint64 // 每次状态变化递增，用于忽略旧状态下发出的请求结果
+	windowStart time.Time
+	requests    int
+	failures    int
+	slowCalls   int
+	openedAt    time.Time
+	lastError   string
+
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+}
+
+// NewBreaker 创建熔断器
+func NewBreaker(name string, settings Settings) *Breaker {
+	return &Breaker{
+		name:        name,
+		settings:    settings.withDefaults(),
+		now:         time.Now,
+		state:       StateClosed,
+		windowStart: time.Now(),
+	}
+}
+
+// Name 获取熔断器名称
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State 获取当前状态（打开状态超时后视为半开）
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.settings.OpenTimeout)) {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Allow 判断是否放行请求
+// 放行时返回 done，调用结束后必须以调用结果和耗时调用 done；拒绝时返回包装了 ErrOpen 的错误。
+// err 为 nil 视为成功，调用方应只传入代表厂商故障的错误。
+func (b *Breaker) Allow() (done func(err error, latency time.Duration), err error) {
+	b.mu.Lock()
+	now := b.now()
+	var notify func()
+	if b.state == StateOpen && !now.Before(b.openedAt.Add(b.settings.OpenTimeout)) {
+		notify = b.setState(StateHalfOpen, now)
+	}
+
+	switch b.state {
+	case StateOpen:
+		retryAfter := b.openedAt.Add(b.settings.OpenTimeout).Sub(now)
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%s: %w (retry after %s)", b.name, ErrOpen, retryAfter.Round(time.Millisecond))
+	case StateHalfOpen:
+		if b.halfOpenInFlight >= b.settings.HalfOpenRequests {
+			b.mu.Unlock()
+			runNotify(notify)
+			return nil, fmt.Errorf("%s: %w (half-open, probing)", b.name, ErrOpen)
+		}
+		b.halfOpenInFlight++
+	}
+
+	generation := b.generation
+	b.mu.Unlock()
+	runNotify(notify)
+
+	var once sync.Once
+	return func(err error, latency time.Duration) {
+		once.Do(func() { b.record(generation, err, latency) })
+	}, nil
+}
+
+// Observe 记录一次不经过 Allow 的调用结果（如后台健康探测）
+// 打开状态下探测成功会提前进入半开状态，探测失败则延长熔断。
+func (b *Breaker) Observe(err error, latency time.Duration) {
+	b.mu.Lock()
+	now := b.now()
+	var notify func()
+	switch b.state {
+	case StateOpen:
+		if err == nil {
+			notify = b.setState(StateHalfOpen, now)
+		} else {
+			b.openedAt = now
+			b.lastError = err.Error()
+		}
+	case StateHalfOpen:
+		if err != nil {
+			b.lastError = err.Error()
+			notify = b.setState(StateOpen, now)
+		}
+	default:
+		notify = b.count(err, latency, now)
+	}
+	b.mu.Unlock()
+	runNotify(notify)
+}
+
+// Status 获取状态快照
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	state := b.state
+	if state == StateOpen && !now.Before(b.openedAt.Add(b.settings.OpenTimeout)) {
+		state = StateHalfOpen
+	}
+	s := Status{
+		Name:      b.name,
+		State:     state,
+		Requests:  b.requests,
+		Failures:  b.failures,
+		SlowCalls: b.slowCalls,
+		LastError: b.lastError,
+	}
+	if b.requests > 0 {
+		s.FailureRate = float64(b.failures) / float64(b.requests)
+	}
+	if state != StateClosed {
+		s.OpenedAt = b.openedAt
+	}
+	return s
+}
+
+// record 记录经 Allow 放行的调用结果
+func (b *Breaker) record(generation uint64, err error, latency time.Duration) {
+	b.mu.Lock()
+	if generation != b.generation {
+		// 状态已变化，结果属于旧状态，忽略
+		b.mu.Unlock()
+		return
+	}
+
+	now := b.now()
+	var notify func()
+	switch b.state {
+	case StateHalfOpen:
+		b.halfOpenInFlight--
+		if err != nil || b.isSlow(latency) {
+			if err != nil {
+				b.lastError = err.Error()
+			}
+			notify = b.setState(StateOpen, now)
+		} else if b.halfOpenSuccesses++; b.halfOpenSuccesses >= b.settings.HalfOpenRequests {
+			notify = b.setState(StateClosed, now)
+		}
+	case StateClosed:
+		notify = b.count(err, latency, now)
+	}
+	b.mu.Unlock()
+	runNotify(notify)
+}
+
+// count 关闭状态下统计调用结果，超过阈值时熔断（调用方持有锁）
+func (b *Breaker) count(err error, latency time.Duration, now time.Time) func() {
+	if now.Sub(b.windowStart) >= b.settings.Window {
+		b.resetWindow(now)
+	}
+
+	b.requests++
+	if err != nil {
+		b.failures++
+		b.lastError = err.Error()
+	}
+	if b.isSlow(latency) {
+		b.slowCalls++
+	}
+
+	if b.requests < b.settings.MinRequests {
+		return nil
+	}
+	failureRate := float64(b.failures) / float64(b.requests)
+	slowRate := float64(b.slowCalls) / float64(b.requests)
+	if failureRate >= b.settings.FailureRate || (b.settings.SlowCallDuration > 0 && slowRate >= b.settings.SlowCallRate) {
+		return b.setState(StateOpen, now)
+	}
+	return nil
+}
+
+// isSlow 判断是否为慢调用
+func (b *Breaker) isSlow(latency time.Duration) bool {
+	return b.settings.SlowCallDuration > 0 && latency >= b.settings.SlowCallDuration
+}
+
+// resetWindow 开始新的统计窗口
+func (b *Breaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests, b.failures, b.slowCalls = 0, 0, 0
+}
+
+// setState 切换状态并返回状态变化回调（调用方持有锁，需在解锁后执行回调）
+func (b *Breaker) setState(to State, now time.Time) func() {
+	from := b.state
+	if from == to {
+		return nil
+	}
+
+	b.state = to
+	b.generation++
+	b.halfOpenInFlight, b.halfOpenSuccesses = 0, 0
+	switch to {
+	case StateOpen:
+		b.openedAt = now
+	case StateClosed:
+		b.resetWindow(now)
+		b.lastError = ""
+	}
+
+	if b.settings.OnStateChange == nil {
+		return nil
+	}
+	callback, name := b.settings.OnStateChange, b.name
+	return func() { callback(name, from, to) }
+}
+
+// runNotify 执行状态变化回调
+func runNotify(notify func()) {
+	if notify != nil {
+		notify()
+	}
+}
+
+// Registry 熔断器注册表（并发安全），同名熔断器共享状态
+type Registry struct {
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewRegistry 创建熔断器注册表
+func NewRegistry() *Registry {
+	return &Registry{breakers: make(map[string]*Breaker)}
+}
+
+// Get 获取或创建熔断器，熔断器在首次创建后保持其配置不变
+func (r *Registry) Get(name string, settings Settings) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if b, ok := r.breakers[name]; ok {
+		return b
+	}
+	b := NewBreaker(name, settings)
+	r.breakers[name] = b
+	return b
+}
+
+// Statuses 获取所有熔断器的状态快照（按名称排序）
+func (r *Registry) Statuses() []Status {
+	r.mu.Lock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, b := range r.breakers {
+		breakers = append(breakers, b)
+	}
+	r.mu.Unlock()
+
+	statuses := make([]Status, 0, len(breakers))
+	for _, b := range breakers {
+		statuses = append(statuses, b.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
@@ -0,0 +1,173 @@
+package circuit
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// transitions 记录状态变化
+type transitions struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (t *transitions) record(name string, from, to State) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.list = append(t.list, string(from)+"->"+string(to))
+}
+
+func (t *transitions) get() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.list...)
+}
+
+// newTestBreaker 创建使用可控时钟的熔断器
+func newTestBreaker(settings Settings) (*Breaker, *time.Time, *transitions) {
+	tr := &transitions{}
+	settings.OnStateChange = tr.record
+	now := time.Now()
+	b := NewBreaker("gpt/gpt-4o", settings)
+	b.now = func() time.Time { return now }
+	b.windowStart = now
+	return b, &now, tr
+}
+
+// call 执行一次调用并记录结果
+func call(t *testing.T, b *Breaker, err error, latency time.Duration) {
+	t.Helper()
+	done, allowErr := b.Allow()
+	if allowErr != nil {
+		t.Fatalf("Allow() error: %v", allowErr)
+	}
+	done(err, latency)
+}
+
+func TestBreakerOpensOnFailureRate(t *testing.T) {
+	b, now, tr := newTestBreaker(Settings{MinRequests: 4, FailureRate: 0.5, OpenTimeout: 10 * time.Second})
+	failure := errors.New("status code: 503")
+
+	call(t, b, nil, 0)
+	call(t, b, failure, 0)
+	call(t, b, nil, 0)
+	if b.State() != StateClosed {
+		t.Fatalf("State() = %s before min requests, want closed", b.State())
+	}
+	call(t, b, failure, 0)
+	if b.State() != StateOpen {
+		t.Fatalf("State() = %s, want open", b.State())
+	}
+
+	if _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Allow() error = %v, want ErrOpen", err)
+	}
+	if s := b.Status(); s.Failures != 2 || s.FailureRate != 0.5 || s.LastError != failure.Error() {
+		t.Errorf("Status() = %+v", s)
+	}
+
+	// 熔断超时后进入半开状态，只放行一个试探请求
+	*now = now.Add(10 * time.Second)
+	done, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() in half-open error: %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("second half-open Allow() error = %v, want ErrOpen", err)
+	}
+	done(nil, 0)
+	if b.State() != StateClosed {
+		t.Errorf("State() = %s after successful probe, want closed", b.State())
+	}
+
+	want := []string{"closed->open", "open->half_open", "half_open->closed"}
+	if got := tr.get(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("transitions = %v, want %v", got, want)
+	}
+}
+
+func TestStatusJSON(t *testing.T) {
+	b, _, _ := newTestBreaker(Settings{})
+	data, err := json.Marshal(b.Status())
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if strings.Contains(string(data), "opened_at") {
+		t.Errorf("closed breaker status = %s, want no opened_at", data)
+	}
+}
+
+func TestBreakerHalfOpenFailure(t *testing.T) {
+	b, now, _ := newTestBreaker(Settings{MinRequests: 1, OpenTimeout: time.Second})
+
+	call(t, b, errors.New("timeout"), 0)
+	*now = now.Add(time.Second)
+	call(t, b, errors.New("timeout"), 0)
+	if b.State() != StateOpen {
+		t.Errorf("State() = %s after failed probe, want open", b.State())
+	}
+}
+
+func TestBreakerSlowCalls(t *testing.T) {
+	b, _, _ := newTestBreaker(Settings{MinRequests: 2, SlowCallDuration: time.Second, SlowCallRate: 1})
+
+	call(t, b, nil, 2*time.Second)
+	call(t, b, nil, 3*time.Second)
+	if b.State() != StateOpen {
+		t.Errorf("State() = %s after slow calls, want open", b.State())
+	}
+}
+
+func TestBreakerWindowReset(t *testing.T) {
+	b, now, _ := newTestBreaker(Settings{Window: time.Minute, MinRequests: 2})
+
+	call(t, b, errors.New("boom"), 0)
+	*now = now.Add(time.Minute)
+	call(t, b, errors.New("boom"), 0)
+	if b.State() != StateClosed {
+		t.Errorf("failures in different windows should not open the breaker")
+	}
+}
+
+func TestBreakerObserve(t *testing.T) {
+	b, _, _ := newTestBreaker(Settings{MinRequests: 1, OpenTimeout: time.Hour})
+
+	b.Observe(errors.New("probe failed"), 0)
+	if b.State() != StateOpen {
+		t.Fatalf("State() = %s, want open", b.State())
+	}
+	// 探测成功后提前进入半开
+	b.Observe(nil, 0)
+	if b.State() != StateHalfOpen {
+		t.Errorf("State() = %s after successful probe, want half_open", b.State())
+	}
+}
+
+func TestBreakerIgnoresStaleResults(t *testing.T) {
+	b, _, _ := newTestBreaker(Settings{MinRequests: 1})
+
+	done, _ := b.Allow()
+	call(t, b, errors.New("boom"), 0) // 熔断
+	done(nil, 0)                      // 熔断前发出的请求结果不影响当前状态
+	if b.State() != StateOpen {
+		t.Errorf("State() = %s, want open", b.State())
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	a := r.Get("gpt/gpt-4o", Settings{})
+	if r.Get("gpt/gpt-4o", Settings{}) != a {
+		t.Error("same name should share breaker")
+	}
+	r.Get("deepseek/deepseek-chat", Settings{})
+
+	statuses := r.Statuses()
+	if len(statuses) != 2 || statuses[0].Name != "deepseek/deepseek-chat" || statuses[1].State != StateClosed {
+		t.Errorf("Statuses() = %+v", statuses)
+	}
+}
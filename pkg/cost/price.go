@@ -0,0 +1,110 @@
+package cost
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"ai-bridge/pkg/types"
+)
+
+// Price 模型价格（美元/百万token）
+type Price struct {
+	Input       float64 `yaml:"input" json:"input"`                                   // 输入价格
+	Output      float64 `yaml:"output" json:"output"`                                 // 输出价格
+	CachedInput float64 `yaml:"cached_input,omitempty" json:"cached_input,omitempty"` // 命中缓存的输入价格（0表示按输入价格计算）
+}
+
+// Calculate 根据token使用情况计算费用（美元）
+func (p Price) Calculate(usage types.Usage) float64 {
+	cached := usage.CachedTokens
+	if cached > usage.PromptTokens {
+		cached = usage.PromptTokens
+	}
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+
+	total := float64(usage.PromptTokens-cached)*p.Input +
+		float64(cached)*cachedPrice +
+		float64(usage.CompletionTokens)*p.Output
+	return total / 1_000_000
+}
+
+// PriceTable 价格表（并发安全）
+// 默认价格来自 types.ModelRegistry，可通过 Set 或 LoadFile 覆盖
+type PriceTable struct {
+	mu     sync.RWMutex
+	prices map[types.Provider]map[string]Price
+}
+
+// NewPriceTable 创建价格表，并使用 types.ModelRegistry 中的价格初始化
+func NewPriceTable() *PriceTable {
+	t := &PriceTable{prices: make(map[types.Provider]map[string]Price)}
+	for provider, models := range types.ModelRegistry {
+		for _, m := range models {
+			t.Set(provider, m.Name, Price{
+				Input:       m.InputPrice,
+				Output:      m.OutputPrice,
+				CachedInput: m.CachedInputPrice,
+			})
+		}
+	}
+	return t
+}
+
+// Get 获取模型价格
+func (t *PriceTable) Get(provider types.Provider, model string) (Price, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	p, ok := t.prices[provider][model]
+	return p, ok
+}
+
+// Set 设置模型价格
+func (t *PriceTable) Set(provider types.Provider, model string, price Price) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.prices[provider] == nil {
+		t.prices[provider] = make(map[string]Price)
+	}
+	t.prices[provider][model] = price
+}
+
+// Calculate 计算一次调用的费用，未知模型返回 0 和 false
+func (t *PriceTable) Calculate(provider types.Provider, model string, usage types.Usage) (float64, bool) {
+	p, ok := t.Get(provider, model)
+	if !ok {
+		return 0, false
+	}
+	return p.Calculate(usage), true
+}
+
+// LoadFile 从YAML或JSON文件加载价格并覆盖已有价格
+// 文件格式：
+//
+//	gpt:
+//	  gpt-4o: {input: 2.5, output: 10, cached_input: 1.25}
+//	deepseek:
+//	  deepseek-chat: {input: 0.27, output: 1.1}
+func (t *PriceTable) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read price file: %w", err)
+	}
+
+	var overrides map[types.Provider]map[string]Price
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("failed to parse price file %s: %w", path, err)
+	}
+
+	for provider, models := range overrides {
+		for model, price := range models {
+			t.Set(provider, model, price)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cost
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ai-bridge/pkg/types"
+)
+
+// almostEqual 比较浮点数
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPriceCalculate(t *testing.T) {
+	price := Price{Input: 2.5, Output: 10, CachedInput: 1.25}
+	usage := types.Usage{PromptTokens: 1_000_000, CompletionTokens: 500_000, CachedTokens: 400_000}
+
+	// 600k * 2.5 + 400k * 1.25 + 500k * 10
+	if got := price.Calculate(usage); !almostEqual(got, 1.5+0.5+5) {
+		t.Errorf("Calculate() = %v, want 7", got)
+	}
+
+	// 未设置缓存价格时按输入价格计算
+	price.CachedInput = 0
+	if got := price.Calculate(usage); !almostEqual(got, 2.5+5) {
+		t.Errorf("Calculate() without cached price = %v, want 7.5", got)
+	}
+}
+
+func TestPriceTableDefaults(t *testing.T) {
+	table := NewPriceTable()
+
+	price, ok := table.Get(types.ProviderGPT, "gpt-4o")
+	if !ok {
+		t.Fatal("expected default price for gpt-4o")
+	}
+	if price.Input != 2.5 || price.Output != 10 || price.CachedInput != 1.25 {
+		t.Errorf("unexpected gpt-4o price: %+v", price)
+	}
+
+	if _, ok := table.Calculate(types.ProviderOllama, "llama3", types.Usage{PromptTokens: 10}); ok {
+		t.Error("unknown model should not have a price")
+	}
+}
+
+func TestPriceTableLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	content := `
+gpt:
+  gpt-4o: {input: 2, output: 8, cached_input: 1}
+ollama:
+  llama3:
+    input: 0.1
+    output: 0.2
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write price file: %v", err)
+	}
+
+	table := NewPriceTable()
+	if err := table.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+
+	if price, _ := table.Get(types.ProviderGPT, "gpt-4o"); price.Input != 2 || price.Output != 8 || price.CachedInput != 1 {
+		t.Errorf("gpt-4o price not overridden: %+v", price)
+	}
+	if price, ok := table.Get(types.ProviderOllama, "llama3"); !ok || price.Output != 0.2 {
+		t.Errorf("llama3 price not loaded: %+v", price)
+	}
+	// 未覆盖的模型保留默认价格
+	if price, _ := table.Get(types.ProviderGPT, "gpt-4o-mini"); price.Input != 0.15 {
+		t.Errorf("gpt-4o-mini price changed: %+v", price)
+	}
+
+	if err := table.LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
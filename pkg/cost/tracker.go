@@ -0,0 +1,126 @@
+package cost
+
+import (
+	"sync"
+	"time"
+
+	"ai-bridge/pkg/types"
+)
+
+// Stats 用量与费用统计
+type Stats struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CachedTokens     int     `json:"cached_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"` // 费用（美元）
+}
+
+// add 累加一次调用
+func (s *Stats) add(usage types.Usage, cost float64) {
+	s.Requests++
+	s.PromptTokens += usage.PromptTokens
+	s.CompletionTokens += usage.CompletionTokens
+	s.CachedTokens += usage.CachedTokens
+	s.TotalTokens += usage.TotalTokens
+	s.Cost += cost
+}
+
+// Summary 费用汇总
+type Summary struct {
+	Total      Stats             `json:"total"`
+	ByProvider map[string]*Stats `json:"by_provider"`
+	ByModel    map[string]*Stats `json:"by_model"` // 键为 provider/model
+	ByTag      map[string]*Stats `json:"by_tag"`
+	Since      time.Time         `json:"since"`
+}
+
+// CostTracker 费用追踪器（并发安全）
+// 按厂商、模型和调用方自定义标签（如用户、团队、功能）聚合费用
+type CostTracker struct {
+	mu      sync.Mutex
+	prices  *PriceTable
+	summary Summary
+}
+
+// NewCostTracker 创建费用追踪器，prices 为 nil 时使用默认价格表
+func NewCostTracker(prices *PriceTable) *CostTracker {
+	if prices == nil {
+		prices = NewPriceTable()
+	}
+	t := &CostTracker{prices: prices}
+	t.Reset()
+	return t
+}
+
+// Prices 获取追踪器使用的价格表
+func (t *CostTracker) Prices() *PriceTable {
+	return t.prices
+}
+
+// Record 记录一次调用的用量并返回本次费用（美元）
+// 未知模型按 0 计费，但仍统计token用量
+func (t *CostTracker) Record(provider types.Provider, model string, usage types.Usage, tags ...string) float64 {
+	cost, _ := t.prices.Calculate(provider, model, usage)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.summary.Total.add(usage, cost)
+	statsFor(t.summary.ByProvider, string(provider)).add(usage, cost)
+	statsFor(t.summary.ByModel, string(provider)+"/"+model).add(usage, cost)
+	for _, tag := range tags {
+		if tag != "" {
+			statsFor(t.summary.ByTag, tag).add(usage, cost)
+		}
+	}
+	return cost
+}
+
+// Summary 获取当前费用汇总的副本
+func (t *CostTracker) Summary() Summary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return Summary{
+		Total:      t.summary.Total,
+		ByProvider: copyStats(t.summary.ByProvider),
+		ByModel:    copyStats(t.summary.ByModel),
+		ByTag:      copyStats(t.summary.ByTag),
+		Since:      t.summary.Since,
+	}
+}
+
+// Reset 清空统计
+func (t *CostTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.summary = Summary{
+		ByProvider: make(map[string]*Stats),
+		ByModel:    make(map[string]*Stats),
+		ByTag:      make(map[string]*Stats),
+		Since:      time.Now(),
+	}
+}
+
+// statsFor 获取或创建指定键的统计
+func statsFor(m map[string]*Stats, key string) *Stats {
+	s, ok := m[key]
+	if !ok {
+		s = &Stats{}
+		m[key] = s
+	}
+	return s
+}
+
+// copyStats 深拷贝统计表
+func copyStats(m map[string]*Stats) map[string]*Stats {
+	result := make(map[string]*Stats, len(m))
+	for k, v := range m {
+		s := *v
+		result[k] = &s
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package cost
+
+import (
+	"sync"
+	"testing"
+
+	"ai-bridge/pkg/types"
+)
+
+func TestCostTracker(t *testing.T) {
+	tracker := NewCostTracker(nil)
+	usage := types.Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500}
+
+	cost := tracker.Record(types.ProviderGPT, "gpt-4o", usage, "team-a")
+	if !almostEqual(cost, (1000*2.5+500*10)/1e6) {
+		t.Errorf("Record() cost = %v", cost)
+	}
+	tracker.Record(types.ProviderGPT, "gpt-4o-mini", usage, "team-b")
+	tracker.Record(types.ProviderOllama, "llama3", usage, "team-a")
+
+	summary := tracker.Summary()
+	if summary.Total.Requests != 3 || summary.Total.TotalTokens != 4500 {
+		t.Errorf("unexpected total: %+v", summary.Total)
+	}
+	if gpt := summary.ByProvider["gpt"]; gpt == nil || gpt.Requests != 2 {
+		t.Errorf("unexpected gpt stats: %+v", gpt)
+	}
+	if m := summary.ByModel["ollama/llama3"]; m == nil || m.Cost != 0 || m.TotalTokens != 1500 {
+		t.Errorf("unknown model should be tracked at zero cost: %+v", m)
+	}
+	if a := summary.ByTag["team-a"]; a == nil || a.Requests != 2 || !almostEqual(a.Cost, cost) {
+		t.Errorf("unexpected team-a stats: %+v", a)
+	}
+
+	// 返回的汇总是副本
+	summary.ByProvider["gpt"].Requests = 100
+	if tracker.Summary().ByProvider["gpt"].Requests != 2 {
+		t.Error("Summary() should return a copy")
+	}
+
+	tracker.Reset()
+	if tracker.Summary().Total.Requests != 0 {
+		t.Error("Reset() should clear stats")
+	}
+}
+
+func TestCostTrackerConcurrent(t *testing.T) {
+	tracker := NewCostTracker(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracker.Record(types.ProviderDeepseek, "deepseek-chat", types.Usage{PromptTokens: 10, TotalTokens: 10}, "tag")
+		}()
+	}
+	wg.Wait()
+
+	if got := tracker.Summary().ByTag["tag"].Requests; got != 50 {
+		t.Errorf("requests = %d, want 50", got)
+	}
+}
@@ -0,0 +1,408 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/eino-contrib/jsonschema"
+)
+
+// ErrClientClosed 客户端已关闭或连接已断开
+var ErrClientClosed = errors.New("mcp client closed")
+
+// refreshTimeout 收到工具列表变化通知后重新获取列表的超时时间
+const refreshTimeout = 30 * time.Second
+
+// ClientOption 客户端选项
+type ClientOption func(*Client)
+
+// WithClientInfo 设置上报给服务端的客户端名称和版本
+func WithClientInfo(name, version string) ClientOption {
+	return func(c *Client) {
+		c.info = Implementation{Name: name, Version: version}
+	}
+}
+
+// WithToolsChangedHandler 设置工具列表变化回调
+// 收到 notifications/tools/list_changed 后客户端重新获取工具列表并调用回调；
+// 已绑定到适配器的工具不会自动更新，可在回调中重新创建客户端
+func WithToolsChangedHandler(handler func(tools []Tool)) ClientOption {
+	return func(c *Client) {
+		c.onToolsChanged = handler
+	}
+}
+
+// Client MCP客户端（并发安全）
+// 通过 Transport 与MCP服务端通信，完成 initialize 握手后可列出和调用远程工具，
+// 并将其转换为 tool.BaseTool（用于 options.WithTools）或 MCPTool（用于 ToolRegistry）。
+type Client struct {
+	transport      Transport
+	info           Implementation
+	onToolsChanged func(tools []Tool)
+
+	nextID  atomic.Int64
+	mu      sync.Mutex
+	pending map[string]chan *Message
+	done    chan struct{}
+	err     error // 连接断开原因
+
+	server InitializeResult // 由 mu 保护
+
+	toolsMu sync.RWMutex
+	tools   []Tool
+}
+
+// NewClient 创建客户端并完成 initialize 握手
+// 握手失败时关闭传输
+func NewClient(ctx context.Context, transport Transport, opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		transport: transport,
+		info:      Implementation{Name: "ai-bridge", Version: "1.0.0"},
+		pending:   make(map[string]chan *Message),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	go c.readLoop()
+
+	if err := c.initialize(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewStdioClient 启动MCP服务端子进程并创建客户端
+func NewStdioClient(ctx context.Context, cfg StdioConfig, opts ...ClientOption) (*Client, error) {
+	transport, err := NewStdioTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(ctx, transport, opts...)
+}
+
+// initialize 握手：发送 initialize 请求，随后发送 initialized 通知
+func (c *Client) initialize(ctx context.Context) error {
+	params := InitializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    map[string]any{},
+		ClientInfo:      c.info,
+	}
+	var result InitializeResult
+	if err := c.call(ctx, MethodInitialize, params, &result); err != nil {
+		return fmt.Errorf("mcp initialize failed: %w", err)
+	}
+	if err := c.notify(ctx, NotificationInitialized, nil); err != nil {
+		return fmt.Errorf("mcp initialize failed: %w", err)
+	}
+
+	c.mu.Lock()
+	c.server = result
+	c.mu.Unlock()
+	return nil
+}
+
+// ServerInfo 返回服务端名称和版本
+func (c *Client) ServerInfo() Implementation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.server.ServerInfo
+}
+
+// ServerCapabilities 返回服务端能力
+func (c *Client) ServerCapabilities() ServerCapabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.server.Capabilities
+}
+
+// Instructions 返回服务端提供的使用说明（可用作系统提示词）
+func (c *Client) Instructions() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.server.Instructions
+}
+
+// Ping 检查连接
+func (c *Client) Ping(ctx context.Context) error {
+	return c.request(ctx, MethodPing, nil, nil)
+}
+
+// ListTools 获取服务端的全部工具（自动翻页）
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var (
+		tools  []Tool
+		cursor string
+	)
+	for {
+		var result ListToolsResult
+		if err := c.request(ctx, MethodToolsList, ListToolsParams{Cursor: cursor}, &result); err != nil {
+			return nil, fmt.Errorf("mcp tools/list failed: %w", err)
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	c.toolsMu.Lock()
+	c.tools = tools
+	c.toolsMu.Unlock()
+	return tools, nil
+}
+
+// CachedTools 返回最近一次获取的工具列表
+func (c *Client) CachedTools() []Tool {
+	c.toolsMu.RLock()
+	defer c.toolsMu.RUnlock()
+	return append([]Tool(nil), c.tools...)
+}
+
+// CallTool 调用远程工具；arguments 可为 map、结构体或 json.RawMessage
+// 工具自身执行失败时返回 IsError 为 true 的结果，而不是错误
+func (c *Client) CallTool(ctx context.Context, name string, arguments any) (*CallToolResult, error) {
+	var result CallToolResult
+	if err := c.request(ctx, MethodToolsCall, CallToolParams{Name: name, Arguments: arguments}, &result); err != nil {
+		return nil, fmt.Errorf("mcp tools/call %s failed: %w", name, err)
+	}
+	return &result, nil
+}
+
+// Tools 获取远程工具并转换为 Eino 工具，可用于 options.WithTools
+func (c *Client) Tools(ctx context.Context) ([]tool.BaseTool, error) {
+	tools, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		result = append(result, &remoteTool{client: c, tool: t})
+	}
+	return result, nil
+}
+
+// MCPTools 获取远程工具并转换为 MCPTool，可注册到 ToolRegistry 供 Agent 循环调用
+func (c *Client) MCPTools(ctx context.Context) ([]*MCPTool, error) {
+	tools, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*MCPTool, 0, len(tools))
+	for _, t := range tools {
+		var params map[string]interface{}
+		if len(t.InputSchema) > 0 {
+			if err := json.Unmarshal(t.InputSchema, &params); err != nil {
+				return nil, fmt.Errorf("invalid input schema for tool %s: %w", t.Name, err)
+			}
+		}
+		name := t.Name
+		result = append(result, NewTool(name, t.Description, params, func(ctx context.Context, params map[string]interface{}) (string, error) {
+			return c.callText(ctx, name, params)
+		}))
+	}
+	return result, nil
+}
+
+// Close 关闭客户端和传输，等待中的调用返回 ErrClientClosed
+func (c *Client) Close() error {
+	err := c.transport.Close()
+	<-c.done
+	return err
+}
+
+// callText 调用工具并返回文本结果，工具执行失败时返回错误
+func (c *Client) callText(ctx context.Context, name string, arguments any) (string, error) {
+	result, err := c.CallTool(ctx, name, arguments)
+	if err != nil {
+		return "", err
+	}
+	if result.IsError {
+		return "", fmt.Errorf("mcp tool %s failed: %s", name, result.Text())
+	}
+	return result.Text(), nil
+}
+
+// request 发送请求；会话失效（ErrSessionExpired）时重新初始化并重试一次
+func (c *Client) request(ctx context.Context, method string, params, result any) error {
+	err := c.call(ctx, method, params, result)
+	if !errors.Is(err, ErrSessionExpired) {
+		return err
+	}
+	if err := c.initialize(ctx); err != nil {
+		return err
+	}
+	return c.call(ctx, method, params, result)
+}
+
+// call 发送请求并等待响应，result 为 nil 时忽略响应内容
+// ctx 结束时发送 notifications/cancelled 通知服务端放弃该请求
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	id := json.RawMessage(strconv.FormatInt(c.nextID.Add(1), 10))
+	msg, err := newRequest(id, method, params)
+	if err != nil {
+		return err
+	}
+
+	ch := make(chan *Message, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	c.pending[string(id)] = ch
+	c.mu.Unlock()
+
+	if err := c.transport.Send(ctx, msg); err != nil {
+		c.removePending(string(id))
+		if ctx.Err() != nil {
+			c.cancelRequest(id, ctx.Err())
+			return ctx.Err()
+		}
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("failed to decode %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		c.removePending(string(id))
+		c.cancelRequest(id, ctx.Err())
+		return ctx.Err()
+	case <-c.done:
+		return c.err
+	}
+}
+
+// cancelRequest 通知服务端放弃请求（尽力而为）
+func (c *Client) cancelRequest(id json.RawMessage, reason error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c.notify(ctx, NotificationCancelled, map[string]any{"requestId": id, "reason": reason.Error()})
+}
+
+// notify 发送通知
+func (c *Client) notify(ctx context.Context, method string, params any) error {
+	msg, err := newRequest(nil, method, params)
+	if err != nil {
+		return err
+	}
+	return c.transport.Send(ctx, msg)
+}
+
+// removePending 移除等待中的请求
+func (c *Client) removePending(id string) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// readLoop 接收并分发服务端消息，连接断开时结束所有等待中的请求
+func (c *Client) readLoop() {
+	for {
+		msg, err := c.transport.Recv()
+		if err != nil {
+			c.mu.Lock()
+			c.err = ErrClientClosed
+			if !errors.Is(err, io.EOF) {
+				c.err = fmt.Errorf("%w: %v", ErrClientClosed, err)
+			}
+			c.pending = make(map[string]chan *Message)
+			c.mu.Unlock()
+			close(c.done)
+			return
+		}
+
+		switch {
+		case msg.IsResponse():
+			c.mu.Lock()
+			ch, ok := c.pending[string(msg.ID)]
+			delete(c.pending, string(msg.ID))
+			c.mu.Unlock()
+			if ok {
+				ch <- msg
+			}
+		case msg.IsRequest():
+			go c.handleRequest(msg)
+		case msg.IsNotification():
+			if msg.Method == NotificationToolsListChanged {
+				go c.refreshTools()
+			}
+		}
+	}
+}
+
+// handleRequest 响应服务端发起的请求（仅支持 ping）
+func (c *Client) handleRequest(msg *Message) {
+	ctx := context.Background()
+	if msg.Method != MethodPing {
+		c.transport.Send(ctx, newErrorResponse(msg.ID, CodeMethodNotFound, "method not found: "+msg.Method))
+		return
+	}
+	if resp, err := newResponse(msg.ID, struct{}{}); err == nil {
+		c.transport.Send(ctx, resp)
+	}
+}
+
+// refreshTools 重新获取工具列表并调用变化回调
+func (c *Client) refreshTools() {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	tools, err := c.ListTools(ctx)
+	if err != nil {
+		return
+	}
+	if c.onToolsChanged != nil {
+		c.onToolsChanged(tools)
+	}
+}
+
+// remoteTool 远程MCP工具，实现 tool.InvokableTool
+type remoteTool struct {
+	client *Client
+	tool   Tool
+}
+
+// Info 返回工具信息（参数使用服务端提供的 JSON Schema）
+func (t *remoteTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	info := &schema.ToolInfo{
+		Name: t.tool.Name,
+		Desc: t.tool.Description,
+	}
+	if len(t.tool.InputSchema) > 0 {
+		var js jsonschema.Schema
+		if err := json.Unmarshal(t.tool.InputSchema, &js); err != nil {
+			return nil, fmt.Errorf("invalid input schema for tool %s: %w", t.tool.Name, err)
+		}
+		info.ParamsOneOf = schema.NewParamsOneOfByJSONSchema(&js)
+	}
+	return info, nil
+}
+
+// InvokableRun 调用远程工具
+func (t *remoteTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	if argumentsInJSON == "" {
+		argumentsInJSON = "{}"
+	}
+	return t.client.callText(ctx, t.tool.Name, json.RawMessage(argumentsInJSON))
+}
@@ -0,0 +1,265 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// fixtureEnv 设置后测试二进制作为MCP服务端运行（TestMain）
+const fixtureEnv = "AI_BRIDGE_MCP_FIXTURE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fixtureEnv) == "1" {
+		runFixtureServer(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runFixtureServer 用于测试的最小MCP服务端
+// 工具：echo、add、fail、ping_client（向客户端发送 ping）、register_late（新增工具并发送列表变化通知）
+func runFixtureServer(r io.Reader, w io.Writer) {
+	transport := NewStreamTransport(r, w)
+	ctx := context.Background()
+	tools := []Tool{
+		{Name: "echo", Description: "Echo text", InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]}`)},
+		{Name: "add", Description: "Add numbers", InputSchema: json.RawMessage(`{"type":"object","properties":{"a":{"type":"number"},"b":{"type":"number"}}}`)},
+		{Name: "fail", Description: "Always fails", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		{Name: "ping_client", Description: "Ping the client", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		{Name: "register_late", Description: "Register another tool", InputSchema: json.RawMessage(`{"type":"object"}`)},
+	}
+	reply := func(id json.RawMessage, result any) {
+		resp, _ := newResponse(id, result)
+		transport.Send(ctx, resp)
+	}
+
+	for {
+		msg, err := transport.Recv()
+		if err != nil {
+			return
+		}
+		if !msg.IsRequest() {
+			continue
+		}
+
+		switch msg.Method {
+		case MethodInitialize:
+			reply(msg.ID, InitializeResult{
+				ProtocolVersion: ProtocolVersion,
+				Capabilities:    ServerCapabilities{Tools: &ToolsCapability{ListChanged: true}},
+				ServerInfo:      Implementation{Name: "fixture", Version: "0.1.0"},
+			})
+		case MethodPing:
+			reply(msg.ID, struct{}{})
+		case MethodToolsList:
+			// 每页两个工具
+			var params ListToolsParams
+			json.Unmarshal(msg.Params, &params)
+			start := 0
+			fmt.Sscan(params.Cursor, &start)
+			end := min(start+2, len(tools))
+			result := ListToolsResult{Tools: tools[start:end]}
+			if end < len(tools) {
+				result.NextCursor = fmt.Sprint(end)
+			}
+			reply(msg.ID, result)
+		case MethodToolsCall:
+			var params struct {
+				Name      string         `json:"name"`
+				Arguments map[string]any `json:"arguments"`
+			}
+			json.Unmarshal(msg.Params, &params)
+			switch params.Name {
+			case "echo":
+				reply(msg.ID, CallToolResult{Content: []Content{TextContent(fmt.Sprint(params.Arguments["text"]))}})
+			case "add":
+				a, _ := params.Arguments["a"].(float64)
+				b, _ := params.Arguments["b"].(float64)
+				reply(msg.ID, CallToolResult{Content: []Content{TextContent(fmt.Sprint(a + b))}})
+			case "fail":
+				reply(msg.ID, CallToolResult{Content: []Content{TextContent("something broke")}, IsError: true})
+			case "ping_client":
+				ping, _ := newRequest(json.RawMessage(`"server-1"`), MethodPing, nil)
+				transport.Send(ctx, ping)
+				resp, err := transport.Recv()
+				if err != nil || !resp.IsResponse() || resp.Error != nil {
+					reply(msg.ID, CallToolResult{Content: []Content{TextContent("no pong")}, IsError: true})
+					continue
+				}
+				reply(msg.ID, CallToolResult{Content: []Content{TextContent("pong")}})
+			case "register_late":
+				tools = append(tools, Tool{Name: "late", InputSchema: json.RawMessage(`{"type":"object"}`)})
+				reply(msg.ID, CallToolResult{Content: []Content{TextContent("ok")}})
+				notification, _ := newRequest(nil, NotificationToolsListChanged, nil)
+				transport.Send(ctx, notification)
+			default:
+				transport.Send(ctx, newErrorResponse(msg.ID, CodeInvalidParams, "unknown tool: "+params.Name))
+			}
+		default:
+			transport.Send(ctx, newErrorResponse(msg.ID, CodeMethodNotFound, "method not found: "+msg.Method))
+		}
+	}
+}
+
+// newFixtureClient 以子进程方式启动测试服务端并创建客户端
+func newFixtureClient(t *testing.T, opts ...ClientOption) *Client {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := NewStdioClient(ctx, StdioConfig{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^$"},
+		Env:     append(os.Environ(), fixtureEnv+"=1"),
+	}, opts...)
+	if err != nil {
+		t.Fatalf("NewStdioClient() error: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClient_Stdio(t *testing.T) {
+	client := newFixtureClient(t)
+	ctx := context.Background()
+
+	if info := client.ServerInfo(); info.Name != "fixture" {
+		t.Errorf("ServerInfo() = %+v", info)
+	}
+	if caps := client.ServerCapabilities(); caps.Tools == nil || !caps.Tools.ListChanged {
+		t.Errorf("ServerCapabilities() = %+v", caps)
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Errorf("Ping() error: %v", err)
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools() error: %v", err)
+	}
+	if len(tools) != 5 || tools[0].Name != "echo" || tools[4].Name != "register_late" {
+		t.Fatalf("ListTools() = %+v", tools)
+	}
+
+	result, err := client.CallTool(ctx, "add", map[string]any{"a": 2, "b": 3})
+	if err != nil || result.Text() != "5" {
+		t.Errorf("CallTool(add) = %+v, %v", result, err)
+	}
+	result, err = client.CallTool(ctx, "fail", nil)
+	if err != nil || !result.IsError || result.Text() != "something broke" {
+		t.Errorf("CallTool(fail) = %+v, %v", result, err)
+	}
+	var rpcErr *RPCError
+	if _, err := client.CallTool(ctx, "missing", nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidParams {
+		t.Errorf("CallTool(missing) error = %v", err)
+	}
+
+	// 服务端发起的 ping
+	result, err = client.CallTool(ctx, "ping_client", nil)
+	if err != nil || result.Text() != "pong" {
+		t.Errorf("CallTool(ping_client) = %+v, %v", result, err)
+	}
+}
+
+func TestClient_EinoTools(t *testing.T) {
+	client := newFixtureClient(t)
+	ctx := context.Background()
+
+	tools, err := client.Tools(ctx)
+	if err != nil {
+		t.Fatalf("Tools() error: %v", err)
+	}
+	echo := tools[0].(tool.InvokableTool)
+	info, err := echo.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error: %v", err)
+	}
+	if info.Name != "echo" || info.ParamsOneOf == nil {
+		t.Errorf("Info() = %+v", info)
+	}
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil || js.Properties == nil || len(js.Required) != 1 {
+		t.Errorf("ToJSONSchema() = %+v, %v", js, err)
+	}
+
+	out, err := echo.InvokableRun(ctx, `{"text":"hello"}`)
+	if err != nil || out != "hello" {
+		t.Errorf("InvokableRun(echo) = %q, %v", out, err)
+	}
+	if _, err := tools[2].(tool.InvokableTool).InvokableRun(ctx, ""); err == nil || !strings.Contains(err.Error(), "something broke") {
+		t.Errorf("InvokableRun(fail) error = %v", err)
+	}
+
+	// MCPTool 可注册到 ToolRegistry
+	mcpTools, err := client.MCPTools(ctx)
+	if err != nil {
+		t.Fatalf("MCPTools() error: %v", err)
+	}
+	registry := NewToolRegistry()
+	for _, tl := range mcpTools {
+		registry.Register(tl)
+	}
+	add, ok := registry.Get("add")
+	if !ok || add.Definition.Parameters["type"] != "object" {
+		t.Fatalf("registry add = %+v", add)
+	}
+	if out, err := add.Handler(ctx, map[string]interface{}{"a": 1.5, "b": 1}); err != nil || out != "2.5" {
+		t.Errorf("add handler = %q, %v", out, err)
+	}
+}
+
+func TestClient_ToolsListChanged(t *testing.T) {
+	changed := make(chan []Tool, 1)
+	client := newFixtureClient(t, WithToolsChangedHandler(func(tools []Tool) {
+		changed <- tools
+	}))
+	ctx := context.Background()
+
+	if _, err := client.CallTool(ctx, "register_late", nil); err != nil {
+		t.Fatalf("CallTool(register_late) error: %v", err)
+	}
+	select {
+	case tools := <-changed:
+		if len(tools) != 6 || tools[5].Name != "late" {
+			t.Errorf("changed tools = %+v", tools)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tools changed handler not called")
+	}
+	if cached := client.CachedTools(); len(cached) != 6 {
+		t.Errorf("CachedTools() = %d tools, want 6", len(cached))
+	}
+}
+
+func TestClient_Closed(t *testing.T) {
+	client := newFixtureClient(t)
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if _, err := client.ListTools(context.Background()); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("ListTools() after Close error = %v", err)
+	}
+}
+
+func TestClient_ContextCanceled(t *testing.T) {
+	// 服务端不响应
+	serverR, clientW := io.Pipe()
+	clientR, serverW := io.Pipe()
+	defer serverW.Close()
+	go io.Copy(io.Discard, serverR)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := NewClient(ctx, NewStreamTransport(clientR, clientW)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("NewClient() error = %v, want deadline exceeded", err)
+	}
+}
@@ -0,0 +1,469 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTP 请求头
+const (
+	HeaderSessionID       = "Mcp-Session-Id"
+	HeaderProtocolVersion = "MCP-Protocol-Version"
+	headerLastEventID     = "Last-Event-ID"
+)
+
+// ErrSessionExpired 服务端会话已失效（Streamable HTTP 返回 404），客户端会自动重新初始化
+var ErrSessionExpired = errors.New("mcp session expired")
+
+// HTTPConfig HTTP传输配置
+type HTTPConfig struct {
+	URL            string            // MCP端点（Streamable HTTP）或SSE端点（旧版SSE）
+	Headers        map[string]string // 附加到每个请求的请求头（如 Authorization）
+	HTTPClient     *http.Client      // HTTP客户端（默认 http.DefaultClient，不应设置 Timeout 以免中断长连接）
+	MaxReconnects  int               // 流断开后的最大连续重连次数（默认5，负数表示不重连）
+	ReconnectDelay time.Duration     // 重连的初始等待时间（默认500ms，每次翻倍，最多10s）
+}
+
+// maxReconnectDelay 重连的最大等待时间
+const maxReconnectDelay = 10 * time.Second
+
+// withDefaults 填充默认值
+func (c HTTPConfig) withDefaults() HTTPConfig {
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+	if c.MaxReconnects == 0 {
+		c.MaxReconnects = 5
+	}
+	if c.ReconnectDelay <= 0 {
+		c.ReconnectDelay = 500 * time.Millisecond
+	}
+	return c
+}
+
+// reconnectDelay 第 attempt 次重连前的等待时间
+func (c HTTPConfig) reconnectDelay(attempt int) time.Duration {
+	delay := c.ReconnectDelay
+	for i := 1; i < attempt && delay < maxReconnectDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxReconnectDelay)
+}
+
+// newHTTPRequest 创建请求并附加自定义请求头
+func (c HTTPConfig) newHTTPRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mcp request: %w", err)
+	}
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// statusError 非2xx响应的错误
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("mcp http status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+}
+
+// mediaType 响应的媒体类型
+func mediaType(resp *http.Response) string {
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mt
+}
+
+// sleepContext 等待 d，ctx 结束时返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// messageQueue HTTP传输的接收队列
+type messageQueue struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	incoming chan *Message
+}
+
+// newMessageQueue 创建接收队列
+func newMessageQueue() messageQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return messageQueue{ctx: ctx, cancel: cancel, incoming: make(chan *Message, 64)}
+}
+
+// deliver 放入一条消息，队列关闭后丢弃
+func (q messageQueue) deliver(msg *Message) {
+	select {
+	case q.incoming <- msg:
+	case <-q.ctx.Done():
+	}
+}
+
+// Recv 接收下一条消息，传输关闭后返回 io.EOF
+func (q messageQueue) Recv() (*Message, error) {
+	select {
+	case msg := <-q.incoming:
+		return msg, nil
+	case <-q.ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+// StreamableHTTPTransport MCP Streamable HTTP 传输
+// 每条消息通过 POST 发送，服务端以JSON或SSE流返回响应；初始化完成后通过 GET 建立SSE流接收服务端通知。
+// 服务端分配的会话ID（Mcp-Session-Id）会附加到后续请求，关闭时通过 DELETE 结束会话；
+// SSE流中断时携带 Last-Event-ID 重连恢复。
+type StreamableHTTPTransport struct {
+	messageQueue
+	cfg HTTPConfig
+
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	mu              sync.Mutex
+	sessionID       string
+	protocolVersion string
+	initID          string // 等待响应的 initialize 请求ID
+	listening       bool
+	listenGen       int // 会话失效后递增，旧会话的 GET 流随之结束
+}
+
+// NewStreamableHTTPTransport 创建 Streamable HTTP 传输
+func NewStreamableHTTPTransport(cfg HTTPConfig) (*StreamableHTTPTransport, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("mcp http url is required")
+	}
+	return &StreamableHTTPTransport{
+		messageQueue: newMessageQueue(),
+		cfg:          cfg.withDefaults(),
+	}, nil
+}
+
+// NewStreamableHTTPClient 通过 Streamable HTTP 连接MCP服务端并创建客户端
+func NewStreamableHTTPClient(ctx context.Context, cfg HTTPConfig, opts ...ClientOption) (*Client, error) {
+	transport, err := NewStreamableHTTPTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(ctx, transport, opts...)
+}
+
+// SessionID 返回服务端分配的会话ID
+func (t *StreamableHTTPTransport) SessionID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+// Send 通过 POST 发送一条消息
+func (t *StreamableHTTPTransport) Send(ctx context.Context, msg *Message) error {
+	if t.ctx.Err() != nil {
+		return ErrClientClosed
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	t.mu.Lock()
+	if msg.Method == MethodInitialize {
+		// 重新初始化时开始新会话
+		t.initID, t.sessionID = string(msg.ID), ""
+	}
+	sessionID := t.sessionID
+	t.mu.Unlock()
+
+	// 关闭传输时中断进行中的请求和流
+	reqCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(t.ctx, cancel)
+	release := func() {
+		stop()
+		cancel()
+	}
+
+	req, err := t.newRequest(reqCtx, http.MethodPost, data)
+	if err != nil {
+		release()
+		return err
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	resp, err := t.cfg.HTTPClient.Do(req)
+	if err != nil {
+		release()
+		return fmt.Errorf("mcp http request failed: %w", err)
+	}
+
+	if id := resp.Header.Get(HeaderSessionID); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && sessionID != "":
+		resp.Body.Close()
+		release()
+		t.mu.Lock()
+		t.sessionID = ""
+		t.listening = false
+		t.listenGen++
+		t.mu.Unlock()
+		return ErrSessionExpired
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		err := statusError(resp)
+		resp.Body.Close()
+		release()
+		return err
+	case resp.StatusCode == http.StatusAccepted || !msg.IsRequest():
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		release()
+	case mediaType(resp) == "text/event-stream":
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			defer release()
+			t.readResponseStream(resp.Body, msg.ID)
+		}()
+	default:
+		err := t.readJSON(resp.Body)
+		resp.Body.Close()
+		release()
+		if err != nil {
+			return err
+		}
+	}
+
+	if msg.Method == NotificationInitialized {
+		t.startListening()
+	}
+	return nil
+}
+
+// Close 结束会话并关闭传输
+func (t *StreamableHTTPTransport) Close() error {
+	t.closeOnce.Do(func() {
+		if sessionID := t.SessionID(); sessionID != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if req, err := t.newRequest(ctx, http.MethodDelete, nil); err == nil {
+				if resp, err := t.cfg.HTTPClient.Do(req); err == nil {
+					resp.Body.Close()
+				}
+			}
+			cancel()
+		}
+		t.cancel()
+		t.wg.Wait()
+	})
+	return nil
+}
+
+// newRequest 创建发往MCP端点的请求，附加会话ID和协议版本
+func (t *StreamableHTTPTransport) newRequest(ctx context.Context, method string, body []byte) (*http.Request, error) {
+	req, err := t.cfg.newHTTPRequest(ctx, method, t.cfg.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessionID != "" {
+		req.Header.Set(HeaderSessionID, t.sessionID)
+	}
+	if t.protocolVersion != "" {
+		req.Header.Set(HeaderProtocolVersion, t.protocolVersion)
+	}
+	return req, nil
+}
+
+// receive 放入一条消息，记录 initialize 响应中协商的协议版本
+func (t *StreamableHTTPTransport) receive(msg *Message) {
+	if msg.IsResponse() {
+		t.mu.Lock()
+		if t.initID != "" && string(msg.ID) == t.initID {
+			t.initID = ""
+			var result InitializeResult
+			if json.Unmarshal(msg.Result, &result) == nil && result.ProtocolVersion != "" {
+				t.protocolVersion = result.ProtocolVersion
+			}
+		}
+		t.mu.Unlock()
+	}
+	t.deliver(msg)
+}
+
+// readJSON 读取JSON响应（单条消息或消息数组）
+func (t *StreamableHTTPTransport) readJSON(body io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(body, maxMessageSize))
+	if err != nil {
+		return fmt.Errorf("failed to read mcp response: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	var messages []*Message
+	if data[0] == '[' {
+		err = json.Unmarshal(data, &messages)
+	} else {
+		var msg Message
+		err = json.Unmarshal(data, &msg)
+		messages = []*Message{&msg}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode mcp response: %w", err)
+	}
+	for _, msg := range messages {
+		t.receive(msg)
+	}
+	return nil
+}
+
+// readEvents 读取SSE流中的消息直至流结束，返回最后一个事件ID
+func (t *StreamableHTTPTransport) readEvents(body io.ReadCloser, lastEventID string, onMessage func(*Message)) string {
+	defer body.Close()
+	reader := newSSEReader(body)
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			return lastEventID
+		}
+		if event.ID != "" {
+			lastEventID = event.ID
+		}
+		if event.Data == "" || (event.Event != "" && event.Event != "message") {
+			continue
+		}
+		var msg Message
+		if json.Unmarshal([]byte(event.Data), &msg) != nil {
+			continue
+		}
+		t.receive(&msg)
+		if onMessage != nil {
+			onMessage(&msg)
+		}
+	}
+}
+
+// readResponseStream 读取POST请求的SSE响应流
+// 流在收到响应前中断时携带 Last-Event-ID 通过 GET 恢复；无法恢复时返回错误响应，避免调用方一直等待
+func (t *StreamableHTTPTransport) readResponseStream(body io.ReadCloser, id json.RawMessage) {
+	done := false
+	onMessage := func(msg *Message) {
+		if msg.IsResponse() && string(msg.ID) == string(id) {
+			done = true
+		}
+	}
+
+	lastEventID := t.readEvents(body, "", onMessage)
+	for attempt := 1; !done && lastEventID != "" && attempt <= t.cfg.MaxReconnects; attempt++ {
+		if !sleepContext(t.ctx, t.cfg.reconnectDelay(attempt)) {
+			return
+		}
+		resp, err := t.openStream(lastEventID)
+		if err != nil {
+			continue
+		}
+		lastEventID = t.readEvents(resp.Body, lastEventID, onMessage)
+		attempt = 0
+	}
+	if !done && t.ctx.Err() == nil {
+		t.deliver(newErrorResponse(id, CodeInternalError, "mcp stream closed before response"))
+	}
+}
+
+// startListening 建立 GET SSE 流接收服务端主动发送的消息（仅一次）
+func (t *StreamableHTTPTransport) startListening() {
+	t.mu.Lock()
+	if t.listening {
+		t.mu.Unlock()
+		return
+	}
+	t.listening = true
+	gen := t.listenGen
+	t.mu.Unlock()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.listen(gen)
+	}()
+}
+
+// listen 保持 GET SSE 流，断开后重连；服务端不支持（405）、会话失效或重连次数用完时结束
+func (t *StreamableHTTPTransport) listen(gen int) {
+	var lastEventID string
+	for failures := 0; failures <= t.cfg.MaxReconnects && t.listenGeneration() == gen; {
+		resp, err := t.openStream(lastEventID)
+		if errors.Is(err, errMethodNotAllowed) || t.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			failures++
+		} else {
+			failures = 0
+			lastEventID = t.readEvents(resp.Body, lastEventID, nil)
+		}
+		if !sleepContext(t.ctx, t.cfg.reconnectDelay(max(failures, 1))) {
+			return
+		}
+	}
+}
+
+// listenGeneration 当前 GET 流的代数
+func (t *StreamableHTTPTransport) listenGeneration() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.listenGen
+}
+
+// errMethodNotAllowed 服务端不支持 GET SSE 流
+var errMethodNotAllowed = errors.New("mcp server does not support sse stream")
+
+// openStream 通过 GET 打开SSE流
+func (t *StreamableHTTPTransport) openStream(lastEventID string) (*http.Response, error) {
+	req, err := t.newRequest(t.ctx, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set(headerLastEventID, lastEventID)
+	}
+	resp, err := t.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		resp.Body.Close()
+		return nil, errMethodNotAllowed
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || mediaType(resp) != "text/event-stream" {
+		err := statusError(resp)
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,423 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// fixtureCall 测试服务端的 tools/call 处理结果
+func fixtureCall(params json.RawMessage) (string, CallToolResult) {
+	var call struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	json.Unmarshal(params, &call)
+	switch call.Name {
+	case "echo":
+		return call.Name, CallToolResult{Content: []Content{TextContent(fmt.Sprint(call.Arguments["text"]))}}
+	default:
+		return call.Name, CallToolResult{Content: []Content{TextContent("ok")}}
+	}
+}
+
+// writeSSE 写入一个SSE事件
+func writeSSE(w http.ResponseWriter, id, event string, data any) {
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	if event != "" {
+		fmt.Fprintf(w, "event: %s\n", event)
+	}
+	switch v := data.(type) {
+	case string:
+		fmt.Fprintf(w, "data: %s\n\n", v)
+	default:
+		b, _ := json.Marshal(v)
+		fmt.Fprintf(w, "data: %s\n\n", b)
+	}
+	w.(http.Flusher).Flush()
+}
+
+// streamableFixture 用于测试的 Streamable HTTP MCP 服务端
+type streamableFixture struct {
+	mu          sync.Mutex
+	sessions    map[string]bool
+	nextSession int
+	tools       []Tool
+	cancelled   []string
+	deleted     []string
+	resume      map[string]*Message // Last-Event-ID → 待恢复的响应
+	notify      chan *Message       // 通过 GET 流发送的消息
+}
+
+func newStreamableFixture() *streamableFixture {
+	return &streamableFixture{
+		sessions: make(map[string]bool),
+		tools: []Tool{
+			{Name: "echo", Description: "Echo text", InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}}}`)},
+			{Name: "slow", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		},
+		resume: make(map[string]*Message),
+		notify: make(chan *Message, 8),
+	}
+}
+
+func (f *streamableFixture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	sessionID := r.Header.Get(HeaderSessionID)
+	validSession := f.sessions[sessionID]
+	f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodDelete:
+		f.mu.Lock()
+		f.deleted = append(f.deleted, sessionID)
+		delete(f.sessions, sessionID)
+		f.mu.Unlock()
+	case http.MethodGet:
+		if !validSession {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		if lastID := r.Header.Get(headerLastEventID); lastID != "" {
+			f.mu.Lock()
+			msg := f.resume[lastID]
+			f.mu.Unlock()
+			if msg != nil {
+				writeSSE(w, lastID+"-1", "", msg)
+			}
+			return
+		}
+		for {
+			select {
+			case msg := <-f.notify:
+				writeSSE(w, "", "message", msg)
+			case <-r.Context().Done():
+				return
+			}
+		}
+	case http.MethodPost:
+		var msg Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if msg.Method == MethodInitialize {
+			f.mu.Lock()
+			f.nextSession++
+			sessionID = fmt.Sprintf("s%d", f.nextSession)
+			f.sessions[sessionID] = true
+			f.mu.Unlock()
+			w.Header().Set(HeaderSessionID, sessionID)
+			resp, _ := newResponse(msg.ID, InitializeResult{
+				ProtocolVersion: ProtocolVersion,
+				Capabilities:    ServerCapabilities{Tools: &ToolsCapability{ListChanged: true}},
+				ServerInfo:      Implementation{Name: "http-fixture", Version: "0.1.0"},
+			})
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+		if !validSession {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		if r.Header.Get(HeaderProtocolVersion) != ProtocolVersion {
+			http.Error(w, "missing protocol version", http.StatusBadRequest)
+			return
+		}
+		if !msg.IsRequest() {
+			if msg.Method == NotificationCancelled {
+				var params struct {
+					RequestID json.RawMessage `json:"requestId"`
+				}
+				json.Unmarshal(msg.Params, &params)
+				f.mu.Lock()
+				f.cancelled = append(f.cancelled, string(params.RequestID))
+				f.mu.Unlock()
+			}
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		f.handleRequest(w, r, &msg)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *streamableFixture) handleRequest(w http.ResponseWriter, r *http.Request, msg *Message) {
+	switch msg.Method {
+	case MethodToolsList:
+		// 以SSE流返回
+		f.mu.Lock()
+		resp, _ := newResponse(msg.ID, ListToolsResult{Tools: f.tools})
+		f.mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeSSE(w, "list-1", "", resp)
+		return
+	case MethodToolsCall:
+	default:
+		json.NewEncoder(w).Encode(newErrorResponse(msg.ID, CodeMethodNotFound, "method not found"))
+		return
+	}
+
+	name, result := fixtureCall(msg.Params)
+	resp, _ := newResponse(msg.ID, result)
+	switch name {
+	case "slow":
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		return
+	case "resume":
+		// 发送一个只有ID的事件后断开，响应在客户端携带 Last-Event-ID 重连后返回
+		f.mu.Lock()
+		f.resume["r1"] = resp
+		f.mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: r1\ndata: \n\n")
+		return
+	case "register_late":
+		f.mu.Lock()
+		f.tools = append(f.tools, Tool{Name: "late", InputSchema: json.RawMessage(`{"type":"object"}`)})
+		f.mu.Unlock()
+		notification, _ := newRequest(nil, NotificationToolsListChanged, nil)
+		f.notify <- notification
+	case "expire":
+		f.mu.Lock()
+		f.sessions = make(map[string]bool)
+		f.mu.Unlock()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestStreamableHTTP(t *testing.T) {
+	fixture := newStreamableFixture()
+	server := httptest.NewServer(fixture)
+	defer server.Close()
+
+	ctx := context.Background()
+	changed := make(chan []Tool, 1)
+	client, err := NewStreamableHTTPClient(ctx, HTTPConfig{
+		URL:            server.URL,
+		Headers:        map[string]string{"Authorization": "Bearer token"},
+		ReconnectDelay: 10 * time.Millisecond,
+	}, WithToolsChangedHandler(func(tools []Tool) { changed <- tools }))
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPClient() error: %v", err)
+	}
+	defer client.Close()
+
+	if info := client.ServerInfo(); info.Name != "http-fixture" {
+		t.Errorf("ServerInfo() = %+v", info)
+	}
+
+	// 工具发现（SSE响应）与调用（JSON响应）
+	tools, err := client.Tools(ctx)
+	if err != nil {
+		t.Fatalf("Tools() error: %v", err)
+	}
+	if len(tools) != 2 {
+		t.Fatalf("Tools() = %d tools, want 2", len(tools))
+	}
+	out, err := tools[0].(tool.InvokableTool).InvokableRun(ctx, `{"text":"over http"}`)
+	if err != nil || out != "over http" {
+		t.Errorf("InvokableRun(echo) = %q, %v", out, err)
+	}
+
+	// GET 流上的工具列表变化通知
+	if _, err := client.CallTool(ctx, "register_late", nil); err != nil {
+		t.Fatalf("CallTool(register_late) error: %v", err)
+	}
+	select {
+	case tools := <-changed:
+		if len(tools) != 3 {
+			t.Errorf("changed tools = %+v", tools)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tools changed handler not called")
+	}
+
+	// SSE响应流中断后携带 Last-Event-ID 恢复
+	result, err := client.CallTool(ctx, "resume", nil)
+	if err != nil || result.Text() != "ok" {
+		t.Errorf("CallTool(resume) = %+v, %v", result, err)
+	}
+
+	// 取消请求时发送 notifications/cancelled
+	slowCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.CallTool(slowCtx, "slow", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CallTool(slow) error = %v, want deadline exceeded", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		fixture.mu.Lock()
+		n := len(fixture.cancelled)
+		fixture.mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			if n != 1 {
+				t.Errorf("cancelled notifications = %d, want 1", n)
+			}
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 会话失效后自动重新初始化
+	if _, err := client.CallTool(ctx, "expire", nil); err != nil {
+		t.Fatalf("CallTool(expire) error: %v", err)
+	}
+	result, err = client.CallTool(ctx, "echo", map[string]any{"text": "again"})
+	if err != nil || result.Text() != "again" {
+		t.Errorf("CallTool(echo) after expiry = %+v, %v", result, err)
+	}
+
+	client.Close()
+	fixture.mu.Lock()
+	defer fixture.mu.Unlock()
+	if fixture.nextSession != 2 {
+		t.Errorf("sessions created = %d, want 2", fixture.nextSession)
+	}
+	if len(fixture.deleted) != 1 || fixture.deleted[0] != "s2" {
+		t.Errorf("deleted sessions = %v, want [s2]", fixture.deleted)
+	}
+}
+
+func TestStreamableHTTP_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(newStreamableFixture())
+	defer server.Close()
+
+	_, err := NewStreamableHTTPClient(context.Background(), HTTPConfig{URL: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected 401 error, got %v", err)
+	}
+}
+
+// sseFixture 用于测试的旧版 HTTP+SSE MCP 服务端
+type sseFixture struct {
+	mu       sync.Mutex
+	sessions map[string]chan *Message // nil 消息表示断开连接
+	next     int
+}
+
+func (f *sseFixture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/sse":
+		f.mu.Lock()
+		f.next++
+		session := fmt.Sprint(f.next)
+		ch := make(chan *Message, 8)
+		f.sessions[session] = ch
+		f.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeSSE(w, "", "endpoint", "/messages?session="+session)
+		for {
+			select {
+			case msg := <-ch:
+				if msg == nil {
+					return
+				}
+				writeSSE(w, "", "message", msg)
+			case <-r.Context().Done():
+				return
+			}
+		}
+	case r.Method == http.MethodPost && r.URL.Path == "/messages":
+		f.mu.Lock()
+		ch := f.sessions[r.URL.Query().Get("session")]
+		f.mu.Unlock()
+		if ch == nil {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		var msg Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		if !msg.IsRequest() {
+			return
+		}
+
+		var resp *Message
+		switch msg.Method {
+		case MethodInitialize:
+			resp, _ = newResponse(msg.ID, InitializeResult{ProtocolVersion: "2024-11-05", ServerInfo: Implementation{Name: "sse-fixture"}})
+		case MethodToolsList:
+			resp, _ = newResponse(msg.ID, ListToolsResult{Tools: []Tool{{Name: "echo", InputSchema: json.RawMessage(`{"type":"object"}`)}, {Name: "drop", InputSchema: json.RawMessage(`{"type":"object"}`)}}})
+		case MethodToolsCall:
+			name, result := fixtureCall(msg.Params)
+			resp, _ = newResponse(msg.ID, result)
+			if name == "drop" {
+				ch <- resp
+				ch <- nil
+				return
+			}
+		default:
+			resp = newErrorResponse(msg.ID, CodeMethodNotFound, "method not found")
+		}
+		ch <- resp
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestSSETransport(t *testing.T) {
+	fixture := &sseFixture{sessions: make(map[string]chan *Message)}
+	server := httptest.NewServer(fixture)
+	defer server.Close()
+
+	ctx := context.Background()
+	client, err := NewSSEClient(ctx, HTTPConfig{URL: server.URL + "/sse", ReconnectDelay: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewSSEClient() error: %v", err)
+	}
+	defer client.Close()
+
+	if info := client.ServerInfo(); info.Name != "sse-fixture" {
+		t.Errorf("ServerInfo() = %+v", info)
+	}
+	tools, err := client.ListTools(ctx)
+	if err != nil || len(tools) != 2 {
+		t.Fatalf("ListTools() = %+v, %v", tools, err)
+	}
+	result, err := client.CallTool(ctx, "echo", map[string]any{"text": "over sse"})
+	if err != nil || result.Text() != "over sse" {
+		t.Errorf("CallTool(echo) = %+v, %v", result, err)
+	}
+
+	// 服务端断开后自动重连
+	if _, err := client.CallTool(ctx, "drop", nil); err != nil {
+		t.Fatalf("CallTool(drop) error: %v", err)
+	}
+	result, err = client.CallTool(ctx, "echo", map[string]any{"text": "reconnected"})
+	if err != nil || result.Text() != "reconnected" {
+		t.Errorf("CallTool(echo) after reconnect = %+v, %v", result, err)
+	}
+	fixture.mu.Lock()
+	defer fixture.mu.Unlock()
+	if fixture.next != 2 {
+		t.Errorf("sse connections = %d, want 2", fixture.next)
+	}
+}
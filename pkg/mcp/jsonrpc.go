@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonrpcVersion JSON-RPC版本
+const jsonrpcVersion = "2.0"
+
+// JSON-RPC 错误码
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Message JSON-RPC 2.0 消息（请求、响应或通知）
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// IsRequest 是否为请求（有方法名和ID）
+func (m *Message) IsRequest() bool {
+	return m.Method != "" && len(m.ID) > 0
+}
+
+// IsNotification 是否为通知（有方法名、无ID）
+func (m *Message) IsNotification() bool {
+	return m.Method != "" && len(m.ID) == 0
+}
+
+// IsResponse 是否为响应（无方法名、有ID）
+func (m *Message) IsResponse() bool {
+	return m.Method == "" && len(m.ID) > 0
+}
+
+// RPCError JSON-RPC 错误
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error 实现 error 接口
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// newRequest 创建请求
+func newRequest(id json.RawMessage, method string, params any) (*Message, error) {
+	msg := &Message{JSONRPC: jsonrpcVersion, ID: id, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s params: %w", method, err)
+		}
+		msg.Params = data
+	}
+	return msg, nil
+}
+
+// newResponse 创建成功响应
+func newResponse(id json.RawMessage, result any) (*Message, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return &Message{JSONRPC: jsonrpcVersion, ID: id, Result: data}, nil
+}
+
+// newErrorResponse 创建错误响应
+func newErrorResponse(id json.RawMessage, code int, message string) *Message {
+	return &Message{JSONRPC: jsonrpcVersion, ID: id, Error: &RPCError{Code: code, Message: message}}
+}
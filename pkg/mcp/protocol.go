@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ProtocolVersion 支持的MCP协议版本
+const ProtocolVersion = "2025-06-18"
+
+// MCP 方法名
+const (
+	MethodInitialize             = "initialize"
+	MethodPing                   = "ping"
+	MethodToolsList              = "tools/list"
+	MethodToolsCall              = "tools/call"
+	NotificationInitialized      = "notifications/initialized"
+	NotificationCancelled        = "notifications/cancelled"
+	NotificationToolsListChanged = "notifications/tools/list_changed"
+	NotificationProgress         = "notifications/progress"
+)
+
+// supportedProtocolVersions 服务端支持的协议版本（新版本在前）
+var supportedProtocolVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// Implementation 客户端或服务端的名称和版本
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ToolsCapability 工具能力
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"` // 工具列表变化时是否发送通知
+}
+
+// ServerCapabilities 服务端能力
+type ServerCapabilities struct {
+	Tools *ToolsCapability `json:"tools,omitempty"`
+}
+
+// InitializeParams initialize 请求参数
+type InitializeParams struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ClientInfo      Implementation `json:"clientInfo"`
+}
+
+// InitializeResult initialize 响应
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
+	Instructions    string             `json:"instructions,omitempty"`
+}
+
+// Tool MCP协议中的工具描述（tools/list 返回）
+type Tool struct {
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema"` // 参数的JSON Schema
+}
+
+// ListToolsParams tools/list 请求参数
+type ListToolsParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ListToolsResult tools/list 响应
+type ListToolsResult struct {
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// CallToolParams tools/call 请求参数
+type CallToolParams struct {
+	Name      string       `json:"name"`
+	Arguments any          `json:"arguments,omitempty"`
+	Meta      *RequestMeta `json:"_meta,omitempty"`
+}
+
+// RequestMeta 请求元数据
+type RequestMeta struct {
+	ProgressToken json.RawMessage `json:"progressToken,omitempty"` // 设置后服务端可发送进度通知
+}
+
+// ProgressParams notifications/progress 通知参数
+type ProgressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// Content 工具结果内容
+type Content struct {
+	Type     string            `json:"type"`               // text、image、audio、resource、resource_link
+	Text     string            `json:"text,omitempty"`     // text
+	Data     string            `json:"data,omitempty"`     // image/audio（base64）
+	MimeType string            `json:"mimeType,omitempty"` // image/audio
+	Resource *ResourceContents `json:"resource,omitempty"` // resource
+}
+
+// ResourceContents 嵌入的资源内容
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// TextContent 创建文本内容
+func TextContent(text string) Content {
+	return Content{Type: "text", Text: text}
+}
+
+// CallToolResult tools/call 响应
+type CallToolResult struct {
+	Content           []Content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// Text 返回结果中的文本（多个文本内容以换行连接）；没有文本时返回结构化结果
+func (r *CallToolResult) Text() string {
+	var parts []string
+	for _, c := range r.Content {
+		switch {
+		case c.Type == "text":
+			parts = append(parts, c.Text)
+		case c.Resource != nil && c.Resource.Text != "":
+			parts = append(parts, c.Resource.Text)
+		}
+	}
+	if len(parts) == 0 && len(r.StructuredContent) > 0 {
+		return string(r.StructuredContent)
+	}
+	return strings.Join(parts, "\n")
+}
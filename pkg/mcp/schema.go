@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/eino-contrib/jsonschema"
+)
+
+// ErrInvalidArguments 工具参数不符合参数的 JSON Schema
+var ErrInvalidArguments = errors.New("invalid tool arguments")
+
+// Schema 将 Parameters 转换为 JSON Schema，未设置参数时返回 nil
+// Parameters 通常由 CreateParameterSchema 等函数构建，顶层未指定类型时视为 object。
+func (d ToolDefinition) Schema() (*jsonschema.Schema, error) {
+	if d.Parameters == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(d.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters for tool %s: %w", d.Name, err)
+	}
+	var s jsonschema.Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid parameter schema for tool %s: %w", d.Name, err)
+	}
+	if s.Type == "" && len(s.TypeEnhanced) == 0 {
+		s.Type = "object"
+	}
+	return &s, nil
+}
+
+// ValidateArguments 按 JSON Schema 校验工具参数（类型、必填、枚举、嵌套对象和数组）
+// 返回的错误包装 ErrInvalidArguments，并列出所有不符合的参数，便于模型一次改正。
+func ValidateArguments(s *jsonschema.Schema, args map[string]interface{}) error {
+	if s == nil {
+		return nil
+	}
+	var problems []string
+	validateValue(s, "", args, &problems)
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidArguments, strings.Join(problems, "; "))
+}
+
+// validateValue 递归校验一个值，问题追加到 problems
+func validateValue(s *jsonschema.Schema, path string, value interface{}, problems *[]string) {
+	if s == nil {
+		return
+	}
+	name := path
+	if name == "" {
+		name = "arguments"
+	}
+
+	types := s.TypeEnhanced
+	if s.Type != "" {
+		types = []string{s.Type}
+	}
+	if len(types) > 0 && !matchesAnyType(types, value) {
+		*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", name, strings.Join(types, " or "), jsonType(value)))
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		allowed := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			b, _ := json.Marshal(e)
+			allowed = append(allowed, string(b))
+		}
+		*problems = append(*problems, fmt.Sprintf("%s: must be one of %s", name, strings.Join(allowed, ", ")))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: required", joinPath(path, key)))
+			}
+		}
+		if s.Properties != nil {
+			for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				if child, ok := v[pair.Key]; ok {
+					validateValue(pair.Value, joinPath(path, pair.Key), child, problems)
+				}
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			validateValue(s.Items, fmt.Sprintf("%s[%d]", name, i), item, problems)
+		}
+	}
+}
+
+// joinPath 拼接参数路径
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// matchesAnyType 值是否符合任一 JSON Schema 类型
+func matchesAnyType(types []string, value interface{}) bool {
+	for _, t := range types {
+		switch t {
+		case "object":
+			if _, ok := value.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]interface{}); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "number":
+			if _, ok := toFloat(value); ok {
+				return true
+			}
+		case "integer":
+			if f, ok := toFloat(value); ok && f == math.Trunc(f) {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType 返回值对应的 JSON 类型名称
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if f, ok := toFloat(value); ok {
+		if f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// toFloat 将数字转换为 float64（JSON 解析得到 float64，直接调用时可能为整数类型）
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// inEnum 值是否在枚举中（数字按数值比较）
+func inEnum(enum []any, value interface{}) bool {
+	for _, e := range enum {
+		if ef, ok := toFloat(e); ok {
+			if vf, ok := toFloat(value); ok && ef == vf {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,323 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HTTP 会话的默认限制
+const (
+	DefaultSessionIdleTTL = 30 * time.Minute // 会话空闲超过该时间后被清理
+	DefaultMaxSessions    = 1000             // 最大会话数，超出时淘汰最久未使用的会话
+)
+
+// ServerOption 服务端选项
+type ServerOption func(*Server)
+
+// WithServerInfo 设置 initialize 时返回的服务端名称和版本
+func WithServerInfo(name, version string) ServerOption {
+	return func(s *Server) {
+		s.info = Implementation{Name: name, Version: version}
+	}
+}
+
+// WithInstructions 设置 initialize 时返回的使用说明
+func WithInstructions(instructions string) ServerOption {
+	return func(s *Server) {
+		s.instructions = instructions
+	}
+}
+
+// WithAuthToken 设置 HTTP 访问令牌，请求须携带 Authorization: Bearer <token>（空字符串表示不校验）
+func WithAuthToken(token string) ServerOption {
+	return func(s *Server) {
+		s.authToken = token
+	}
+}
+
+// WithAllowedOrigins 设置 HTTP 允许的浏览器来源（Origin 请求头，如 https://app.example.com）
+// 本机来源（localhost、127.0.0.1、[::1]）始终允许；其他来源返回 403，防止 DNS 重绑定攻击。
+func WithAllowedOrigins(origins ...string) ServerOption {
+	return func(s *Server) {
+		s.allowedOrigins = append(s.allowedOrigins, origins...)
+	}
+}
+
+// WithSessionLimits 设置 HTTP 会话的空闲超时和最大数量（<= 0 时使用默认值）
+func WithSessionLimits(idleTTL time.Duration, maxSessions int) ServerOption {
+	return func(s *Server) {
+		if idleTTL > 0 {
+			s.sessionIdleTTL = idleTTL
+		}
+		if maxSessions > 0 {
+			s.maxSessions = maxSessions
+		}
+	}
+}
+
+// Server MCP服务端，将 ToolRegistry 中的工具发布给其他Agent（IDE、桌面助手等）
+// 同一个 Server 可同时通过 stdio（Serve/ServeStdio）和 HTTP（HTTPHandler）提供服务。
+type Server struct {
+	registry     *ToolRegistry
+	info         Implementation
+	instructions string
+
+	// HTTP 访问控制与会话限制
+	authToken      string
+	allowedOrigins []string
+	sessionIdleTTL time.Duration
+	maxSessions    int
+}
+
+// NewServer 创建MCP服务端
+func NewServer(registry *ToolRegistry, opts ...ServerOption) *Server {
+	s := &Server{
+		registry:       registry,
+		info:           Implementation{Name: "ai-bridge", Version: "1.0.0"},
+		sessionIdleTTL: DefaultSessionIdleTTL,
+		maxSessions:    DefaultMaxSessions,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ServeStdio 通过标准输入输出提供服务，直至标准输入关闭或 ctx 取消
+func (s *Server) ServeStdio(ctx context.Context) error {
+	return s.Serve(ctx, NewStreamTransport(os.Stdin, os.Stdout))
+}
+
+// Serve 在传输上提供服务，直至传输关闭或 ctx 取消
+// 输入结束时等待处理中的请求完成后返回；ctx 取消时处理中的请求也被取消。
+func (s *Server) Serve(ctx context.Context, transport Transport) error {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(ctx, func() { transport.Close() })
+
+	session := newServerSession(s)
+	send := func(msg *Message) error {
+		return transport.Send(ctx, msg)
+	}
+
+	var wg sync.WaitGroup
+	var err error
+	for {
+		msg, recvErr := transport.Recv()
+		if recvErr != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			} else if !errors.Is(recvErr, io.EOF) {
+				err = fmt.Errorf("mcp server receive failed: %w", recvErr)
+			}
+			break
+		}
+		if msg.IsRequest() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				session.handleRequest(ctx, msg, send)
+			}()
+			continue
+		}
+		session.handleNotification(msg)
+	}
+
+	wg.Wait()
+	cancel()
+	stop()
+	transport.Close()
+	return err
+}
+
+// tools 返回按名称排序的工具列表
+func (s *Server) tools() ([]Tool, error) {
+	all := s.registry.GetAll()
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Definition.Name < all[j].Definition.Name
+	})
+	tools := make([]Tool, 0, len(all))
+	for _, t := range all {
+		inputSchema, err := t.Definition.InputSchema()
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, Tool{
+			Name:        t.Definition.Name,
+			Description: t.Definition.Description,
+			InputSchema: inputSchema,
+		})
+	}
+	return tools, nil
+}
+
+// callTool 调用工具；工具执行失败时返回 isError 结果，由模型自行处理
+func (s *Server) callTool(ctx context.Context, name string, arguments map[string]interface{}) (*CallToolResult, error) {
+	t, ok := s.registry.Get(name)
+	if !ok {
+		return nil, &RPCError{Code: CodeInvalidParams, Message: "unknown tool: " + name}
+	}
+	text, err := t.Call(ctx, arguments)
+	if err != nil {
+		return &CallToolResult{Content: []Content{TextContent(err.Error())}, IsError: true}, nil
+	}
+	return &CallToolResult{Content: []Content{TextContent(text)}}, nil
+}
+
+// serverSession 一个客户端连接（stdio 连接或 HTTP 会话）的状态
+type serverSession struct {
+	server *Server
+
+	mu       sync.Mutex
+	inflight map[string]context.CancelFunc // 处理中的请求，用于 notifications/cancelled
+}
+
+// newServerSession 创建会话
+func newServerSession(server *Server) *serverSession {
+	return &serverSession{
+		server:   server,
+		inflight: make(map[string]context.CancelFunc),
+	}
+}
+
+// handleRequest 处理请求并通过 send 发送响应；请求被取消时不发送响应
+func (s *serverSession) handleRequest(ctx context.Context, msg *Message, send func(*Message) error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	id := string(msg.ID)
+	s.mu.Lock()
+	s.inflight[id] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.inflight, id)
+		s.mu.Unlock()
+	}()
+
+	result, err := s.dispatch(ctx, msg, send)
+	if ctx.Err() != nil {
+		return
+	}
+
+	var resp *Message
+	if err == nil {
+		resp, err = newResponse(msg.ID, result)
+	}
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: CodeInternalError, Message: err.Error()}
+		}
+		resp = &Message{JSONRPC: jsonrpcVersion, ID: msg.ID, Error: rpcErr}
+	}
+	send(resp)
+}
+
+// handleNotification 处理通知；客户端的响应被忽略（服务端不发起请求）
+func (s *serverSession) handleNotification(msg *Message) {
+	if msg.Method != NotificationCancelled {
+		return
+	}
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if json.Unmarshal(msg.Params, &params) != nil {
+		return
+	}
+	s.mu.Lock()
+	cancel, ok := s.inflight[string(params.RequestID)]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// dispatch 按方法名处理请求
+func (s *serverSession) dispatch(ctx context.Context, msg *Message, send func(*Message) error) (any, error) {
+	switch msg.Method {
+	case MethodInitialize:
+		var params InitializeParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		version := ProtocolVersion
+		if slices.Contains(supportedProtocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return InitializeResult{
+			ProtocolVersion: version,
+			Capabilities:    ServerCapabilities{Tools: &ToolsCapability{}},
+			ServerInfo:      s.server.info,
+			Instructions:    s.server.instructions,
+		}, nil
+	case MethodPing:
+		return struct{}{}, nil
+	case MethodToolsList:
+		tools, err := s.server.tools()
+		if err != nil {
+			return nil, err
+		}
+		return ListToolsResult{Tools: tools}, nil
+	case MethodToolsCall:
+		var params struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+			Meta      *RequestMeta           `json:"_meta"`
+		}
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		if params.Meta != nil && len(params.Meta.ProgressToken) > 0 {
+			ctx = withProgress(ctx, params.Meta.ProgressToken, send)
+		}
+		return s.server.callTool(ctx, params.Name, params.Arguments)
+	default:
+		return nil, &RPCError{Code: CodeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+}
+
+// decodeParams 解析请求参数
+func decodeParams(data json.RawMessage, v any) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return &RPCError{Code: CodeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+// progressKey 进度上报函数的 context key
+type progressKey struct{}
+
+// withProgress 将进度上报函数放入 context
+func withProgress(ctx context.Context, token json.RawMessage, send func(*Message) error) context.Context {
+	report := func(progress, total float64, message string) {
+		msg, err := newRequest(nil, NotificationProgress, ProgressParams{
+			ProgressToken: token,
+			Progress:      progress,
+			Total:         total,
+			Message:       message,
+		})
+		if err == nil {
+			send(msg)
+		}
+	}
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// ReportProgress 在工具处理函数中上报进度（notifications/progress）
+// 仅当客户端在请求中提供了 progressToken 时发送；progress 应单调递增，total 未知时传 0。
+func ReportProgress(ctx context.Context, progress, total float64, message string) {
+	if report, ok := ctx.Value(progressKey{}).(func(float64, float64, string)); ok {
+		report(progress, total, message)
+	}
+}
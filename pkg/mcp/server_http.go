@@ -0,0 +1,240 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPHandler 返回 Streamable HTTP 处理器，可挂载到任意路径（如 /mcp）
+// initialize 时分配会话ID（Mcp-Session-Id），DELETE 结束会话；
+// 客户端接受 text/event-stream 时以SSE流返回进度通知和响应，否则返回JSON响应。
+// 服务端不主动推送消息，因此 GET 返回 405。
+// 来自非本机且未通过 WithAllowedOrigins 允许的浏览器来源的请求返回 403；
+// 通过 WithAuthToken 设置令牌后，未携带正确 Bearer 令牌的请求返回 401。
+// 空闲超时的会话在后续请求时被清理，会话数达到上限时淘汰最久未使用的会话（见 WithSessionLimits）。
+func (s *Server) HTTPHandler() http.Handler {
+	return &httpHandler{
+		server:   s,
+		sessions: make(map[string]*httpSession),
+		now:      time.Now,
+	}
+}
+
+// httpHandler Streamable HTTP 处理器
+type httpHandler struct {
+	server *Server
+	now    func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// httpSession HTTP 会话及其最近使用时间
+type httpSession struct {
+	*serverSession
+	lastUsed time.Time
+}
+
+// ServeHTTP 实现 http.Handler
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.allowOrigin(r.Header.Get("Origin")) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePost(w, r)
+	case http.MethodDelete:
+		id := r.Header.Get(HeaderSessionID)
+		h.mu.Lock()
+		_, ok := h.sessions[id]
+		delete(h.sessions, id)
+		h.mu.Unlock()
+		if !ok {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// allowOrigin 校验浏览器来源：未携带 Origin（非浏览器客户端）、本机来源或允许列表中的来源
+func (h *httpHandler) allowOrigin(origin string) bool {
+	if origin == "" || slices.Contains(h.server.allowedOrigins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// authorized 校验 Bearer 令牌（未设置令牌时不校验）
+func (h *httpHandler) authorized(r *http.Request) bool {
+	if h.server.authToken == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.server.authToken)) == 1
+}
+
+// addSession 创建会话；先清理空闲超时的会话，达到上限时淘汰最久未使用的会话
+func (h *httpHandler) addSession(id string) *serverSession {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	var oldestID string
+	for sid, sess := range h.sessions {
+		if now.Sub(sess.lastUsed) > h.server.sessionIdleTTL {
+			delete(h.sessions, sid)
+			continue
+		}
+		if oldestID == "" || sess.lastUsed.Before(h.sessions[oldestID].lastUsed) {
+			oldestID = sid
+		}
+	}
+	if len(h.sessions) >= h.server.maxSessions && oldestID != "" {
+		delete(h.sessions, oldestID)
+	}
+
+	session := newServerSession(h.server)
+	h.sessions[id] = &httpSession{serverSession: session, lastUsed: now}
+	return session
+}
+
+// session 查找会话并刷新使用时间，会话不存在或已空闲超时时返回 nil
+func (h *httpHandler) session(id string) *serverSession {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sess, ok := h.sessions[id]
+	if !ok {
+		return nil
+	}
+	now := h.now()
+	if now.Sub(sess.lastUsed) > h.server.sessionIdleTTL {
+		delete(h.sessions, id)
+		return nil
+	}
+	sess.lastUsed = now
+	return sess.serverSession
+}
+
+// handlePost 处理客户端发送的一条消息
+func (h *httpHandler) handlePost(w http.ResponseWriter, r *http.Request) {
+	var msg Message
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMessageSize)).Decode(&msg); err != nil {
+		writeJSON(w, http.StatusBadRequest, newErrorResponse(nil, CodeParseError, "parse error: "+err.Error()))
+		return
+	}
+	if version := r.Header.Get(HeaderProtocolVersion); version != "" && !slices.Contains(supportedProtocolVersions, version) {
+		http.Error(w, "unsupported protocol version: "+version, http.StatusBadRequest)
+		return
+	}
+
+	var session *serverSession
+	if msg.Method == MethodInitialize {
+		id, err := newSessionID()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		session = h.addSession(id)
+		w.Header().Set(HeaderSessionID, id)
+	} else {
+		id := r.Header.Get(HeaderSessionID)
+		if id == "" {
+			http.Error(w, "missing "+HeaderSessionID+" header", http.StatusBadRequest)
+			return
+		}
+		session = h.session(id)
+		if session == nil {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	if !msg.IsRequest() {
+		session.handleNotification(&msg)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// 不接受SSE时只返回最终响应，进度通知被丢弃
+	flusher, canStream := w.(http.Flusher)
+	if !canStream || !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		var resp *Message
+		session.handleRequest(r.Context(), &msg, func(m *Message) error {
+			if m.IsResponse() {
+				resp = m
+			}
+			return nil
+		})
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var mu sync.Mutex
+	session.handleRequest(r.Context(), &msg, func(m *Message) error {
+		data, err := json.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+// writeJSON 写入JSON响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// newSessionID 生成随机会话ID
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,348 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// newTestRegistry 创建测试用工具注册表
+func newTestRegistry(started chan<- struct{}, cancelled chan<- struct{}) *ToolRegistry {
+	registry := NewToolRegistry()
+	registry.Register(NewTool("echo", "Echo text",
+		CreateParameterSchema(map[string]interface{}{"text": CreateStringProperty("text")}, []string{"text"}),
+		func(ctx context.Context, params map[string]interface{}) (string, error) {
+			return fmt.Sprint(params["text"]), nil
+		}))
+	registry.Register(NewTool("fail", "Always fails", nil,
+		func(ctx context.Context, params map[string]interface{}) (string, error) {
+			return "", errors.New("disk full")
+		}))
+	registry.Register(NewTool("panic", "Always panics", nil,
+		func(ctx context.Context, params map[string]interface{}) (string, error) {
+			panic("boom")
+		}))
+	registry.Register(NewTool("progress", "Reports progress", nil,
+		func(ctx context.Context, params map[string]interface{}) (string, error) {
+			ReportProgress(ctx, 1, 2, "half")
+			ReportProgress(ctx, 2, 2, "done")
+			return "finished", nil
+		}))
+	registry.Register(NewTool("block", "Blocks until cancelled", nil,
+		func(ctx context.Context, params map[string]interface{}) (string, error) {
+			started <- struct{}{}
+			<-ctx.Done()
+			cancelled <- struct{}{}
+			return "", ctx.Err()
+		}))
+	return registry
+}
+
+// serveStdio 通过内存管道连接服务端，返回客户端侧的传输
+func serveStdio(t *testing.T, server *Server) Transport {
+	t.Helper()
+	clientRead, serverWrite := io.Pipe()
+	serverRead, clientWrite := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, NewStreamTransport(serverRead, serverWrite))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("Serve() error: %v", err)
+		}
+	})
+	return NewStreamTransport(clientRead, clientWrite)
+}
+
+func TestServer_Stdio(t *testing.T) {
+	server := NewServer(newTestRegistry(nil, nil), WithServerInfo("test-server", "0.1.0"), WithInstructions("use echo"))
+	ctx := context.Background()
+	client, err := NewClient(ctx, serveStdio(t, server))
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+	defer client.Close()
+
+	if info := client.ServerInfo(); info.Name != "test-server" || info.Version != "0.1.0" {
+		t.Errorf("ServerInfo() = %+v", info)
+	}
+	if client.Instructions() != "use echo" {
+		t.Errorf("Instructions() = %q", client.Instructions())
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools() error: %v", err)
+	}
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "block,echo,fail,panic,progress" {
+		t.Errorf("tool names = %s", got)
+	}
+	var inputSchema map[string]interface{}
+	json.Unmarshal(tools[1].InputSchema, &inputSchema)
+	if inputSchema["type"] != "object" || fmt.Sprint(inputSchema["required"]) != "[text]" {
+		t.Errorf("echo inputSchema = %s", tools[1].InputSchema)
+	}
+	if string(tools[2].InputSchema) != `{"type":"object"}` {
+		t.Errorf("fail inputSchema = %s", tools[2].InputSchema)
+	}
+
+	result, err := client.CallTool(ctx, "echo", map[string]any{"text": "hello"})
+	if err != nil || result.IsError || result.Text() != "hello" {
+		t.Errorf("CallTool(echo) = %+v, %v", result, err)
+	}
+
+	// 工具执行失败和 panic 返回 isError 结果
+	for name, want := range map[string]string{"fail": "disk full", "panic": "tool panic panicked: boom"} {
+		result, err := client.CallTool(ctx, name, nil)
+		if err != nil || !result.IsError || result.Text() != want {
+			t.Errorf("CallTool(%s) = %+v, %v", name, result, err)
+		}
+	}
+
+	// 未知工具返回协议错误
+	var rpcErr *RPCError
+	if _, err := client.CallTool(ctx, "missing", nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidParams {
+		t.Errorf("CallTool(missing) error = %v", err)
+	}
+
+	// 通过 Eino 工具调用
+	einoTools, err := client.Tools(ctx)
+	if err != nil {
+		t.Fatalf("Tools() error: %v", err)
+	}
+	out, err := einoTools[1].(tool.InvokableTool).InvokableRun(ctx, `{"text":"via eino"}`)
+	if err != nil || out != "via eino" {
+		t.Errorf("InvokableRun(echo) = %q, %v", out, err)
+	}
+}
+
+func TestServer_Progress(t *testing.T) {
+	transport := serveStdio(t, NewServer(newTestRegistry(nil, nil)))
+	ctx := context.Background()
+
+	req, _ := newRequest(json.RawMessage(`1`), MethodToolsCall, CallToolParams{
+		Name: "progress",
+		Meta: &RequestMeta{ProgressToken: json.RawMessage(`"p1"`)},
+	})
+	if err := transport.Send(ctx, req); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	var progress []ProgressParams
+	for {
+		msg, err := transport.Recv()
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		if msg.IsResponse() {
+			var result CallToolResult
+			json.Unmarshal(msg.Result, &result)
+			if result.Text() != "finished" {
+				t.Errorf("result = %s", msg.Result)
+			}
+			break
+		}
+		if msg.Method != NotificationProgress {
+			t.Fatalf("unexpected message %+v", msg)
+		}
+		var params ProgressParams
+		json.Unmarshal(msg.Params, &params)
+		progress = append(progress, params)
+	}
+	if len(progress) != 2 || string(progress[0].ProgressToken) != `"p1"` ||
+		progress[0].Progress != 1 || progress[1].Total != 2 || progress[1].Message != "done" {
+		t.Errorf("progress = %+v", progress)
+	}
+}
+
+func TestServer_Cancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	cancelled := make(chan struct{}, 1)
+	ctx := context.Background()
+	client, err := NewClient(ctx, serveStdio(t, NewServer(newTestRegistry(started, cancelled))))
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+	defer client.Close()
+
+	callCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := client.CallTool(callCtx, "block", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("CallTool(block) error = %v, want canceled", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool handler was not cancelled")
+	}
+}
+
+func TestServer_HTTP(t *testing.T) {
+	handler := NewServer(newTestRegistry(nil, nil)).HTTPHandler()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx := context.Background()
+	client, err := NewStreamableHTTPClient(ctx, HTTPConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPClient() error: %v", err)
+	}
+	tools, err := client.ListTools(ctx)
+	if err != nil || len(tools) != 5 {
+		t.Fatalf("ListTools() = %d tools, %v", len(tools), err)
+	}
+	// SSE响应中的进度通知被客户端忽略，只返回最终结果
+	result, err := client.CallTool(ctx, "progress", nil)
+	if err != nil || result.Text() != "finished" {
+		t.Errorf("CallTool(progress) = %+v, %v", result, err)
+	}
+	result, err = client.CallTool(ctx, "fail", nil)
+	if err != nil || !result.IsError {
+		t.Errorf("CallTool(fail) = %+v, %v", result, err)
+	}
+	client.Close()
+	if n := len(handler.(*httpHandler).sessions); n != 0 {
+		t.Errorf("sessions after close = %d, want 0", n)
+	}
+
+	// 不接受SSE时返回JSON；没有会话ID时返回 400
+	post := func(body string, headers map[string]string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST error: %v", err)
+		}
+		return resp
+	}
+	resp := post(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`, nil)
+	var msg Message
+	json.NewDecoder(resp.Body).Decode(&msg)
+	resp.Body.Close()
+	var init InitializeResult
+	json.Unmarshal(msg.Result, &init)
+	sessionID := resp.Header.Get(HeaderSessionID)
+	if sessionID == "" || init.ProtocolVersion != "2024-11-05" {
+		t.Fatalf("initialize = %s, session %q", msg.Result, sessionID)
+	}
+
+	resp = post(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"json"}}}`,
+		map[string]string{HeaderSessionID: sessionID})
+	msg = Message{}
+	json.NewDecoder(resp.Body).Decode(&msg)
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "application/json" || !strings.Contains(string(msg.Result), `"text":"json"`) {
+		t.Errorf("tools/call = %s (%s)", msg.Result, resp.Header.Get("Content-Type"))
+	}
+
+	resp = post(`{"jsonrpc":"2.0","id":3,"method":"ping"}`, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("ping without session status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestServer_HTTPAccessControl(t *testing.T) {
+	handler := NewServer(newTestRegistry(nil, nil),
+		WithAuthToken("secret"),
+		WithAllowedOrigins("https://app.example.com"),
+	).HTTPHandler()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	initialize := func(headers map[string]string) int {
+		req, _ := http.NewRequest(http.MethodPost, server.URL,
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST error: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	cases := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"no token", nil, http.StatusUnauthorized},
+		{"wrong token", map[string]string{"Authorization": "Bearer wrong"}, http.StatusUnauthorized},
+		{"token", map[string]string{"Authorization": "Bearer secret"}, http.StatusOK},
+		{"localhost origin", map[string]string{"Authorization": "Bearer secret", "Origin": "http://localhost:3000"}, http.StatusOK},
+		{"allowed origin", map[string]string{"Authorization": "Bearer secret", "Origin": "https://app.example.com"}, http.StatusOK},
+		{"foreign origin", map[string]string{"Authorization": "Bearer secret", "Origin": "https://evil.example.com"}, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if got := initialize(tc.headers); got != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	// 客户端通过 Headers 携带令牌
+	client, err := NewStreamableHTTPClient(context.Background(), HTTPConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	})
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPClient() error: %v", err)
+	}
+	client.Close()
+}
+
+func TestServer_HTTPSessionLimits(t *testing.T) {
+	handler := NewServer(newTestRegistry(nil, nil), WithSessionLimits(time.Minute, 2)).HTTPHandler().(*httpHandler)
+	now := time.Now()
+	handler.now = func() time.Time { return now }
+
+	// 达到上限时淘汰最久未使用的会话
+	handler.addSession("a")
+	now = now.Add(time.Second)
+	handler.addSession("b")
+	now = now.Add(time.Second)
+	if handler.session("a") == nil {
+		t.Fatal("session a should exist")
+	}
+	handler.addSession("c")
+	if handler.session("b") != nil || handler.session("a") == nil || handler.session("c") == nil {
+		t.Errorf("least recently used session b should be evicted, sessions = %v", handler.sessions)
+	}
+
+	// 空闲超时的会话不可再用，并在创建新会话时被清理
+	now = now.Add(2 * time.Minute)
+	if handler.session("a") != nil {
+		t.Error("idle session a should expire")
+	}
+	handler.addSession("d")
+	if n := len(handler.sessions); n != 1 {
+		t.Errorf("sessions = %d, want 1 after idle cleanup", n)
+	}
+}
@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// sseEvent Server-Sent Events 事件
+type sseEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// sseReader Server-Sent Events 读取器
+type sseReader struct {
+	scanner *bufio.Scanner
+}
+
+// newSSEReader 创建SSE读取器
+func newSSEReader(r io.Reader) *sseReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	return &sseReader{scanner: scanner}
+}
+
+// Next 读取下一个事件，流结束时返回 io.EOF
+func (r *sseReader) Next() (*sseEvent, error) {
+	var event sseEvent
+	var data []string
+	hasField := false
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+
+		// 空行表示一个事件结束
+		if line == "" {
+			if hasField {
+				event.Data = strings.Join(data, "\n")
+				return &event, nil
+			}
+			continue
+		}
+
+		// 注释行
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			event.ID = value
+			hasField = true
+		case "event":
+			event.Event = value
+			hasField = true
+		case "data":
+			data = append(data, value)
+			hasField = true
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// 流结束时可能没有结尾空行
+	if hasField {
+		event.Data = strings.Join(data, "\n")
+		return &event, nil
+	}
+
+	return nil, io.EOF
+}
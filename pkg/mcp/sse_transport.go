@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// SSETransport 旧版 HTTP+SSE 传输（协议版本 2024-11-05）
+// 通过 GET 建立SSE流，服务端先发送 endpoint 事件告知消息地址，之后的响应和通知都通过该流返回；
+// 客户端消息通过 POST 发送到消息地址。流断开后自动重连（重连后服务端通常会分配新会话）。
+type SSETransport struct {
+	messageQueue
+	cfg HTTPConfig
+
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	mu       sync.Mutex
+	endpoint string
+	ready    chan struct{} // 收到 endpoint 事件后关闭
+}
+
+// NewSSETransport 连接SSE端点并等待服务端告知消息地址
+func NewSSETransport(ctx context.Context, cfg HTTPConfig) (*SSETransport, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("mcp sse url is required")
+	}
+	t := &SSETransport{
+		messageQueue: newMessageQueue(),
+		cfg:          cfg.withDefaults(),
+		ready:        make(chan struct{}),
+	}
+
+	// 首次连接失败直接返回错误，不重连
+	resp, err := t.connect()
+	if err != nil {
+		t.cancel()
+		return nil, err
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.run(resp)
+	}()
+
+	if _, err := t.waitEndpoint(ctx); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewSSEClient 通过旧版 HTTP+SSE 连接MCP服务端并创建客户端
+func NewSSEClient(ctx context.Context, cfg HTTPConfig, opts ...ClientOption) (*Client, error) {
+	transport, err := NewSSETransport(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(ctx, transport, opts...)
+}
+
+// Send 通过 POST 发送一条消息到消息地址
+func (t *SSETransport) Send(ctx context.Context, msg *Message) error {
+	endpoint, err := t.waitEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	req, err := t.cfg.newHTTPRequest(ctx, http.MethodPost, endpoint, data)
+	if err != nil {
+		return err
+	}
+	resp, err := t.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("mcp http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError(resp)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Close 断开SSE流并关闭传输
+func (t *SSETransport) Close() error {
+	t.closeOnce.Do(func() {
+		t.cancel()
+		t.wg.Wait()
+	})
+	return nil
+}
+
+// waitEndpoint 等待消息地址可用
+func (t *SSETransport) waitEndpoint(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	ready := t.ready
+	t.mu.Unlock()
+
+	select {
+	case <-ready:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.endpoint, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-t.ctx.Done():
+		return "", ErrClientClosed
+	}
+}
+
+// connect 建立SSE流
+func (t *SSETransport) connect() (*http.Response, error) {
+	req, err := t.cfg.newHTTPRequest(t.ctx, http.MethodGet, t.cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := t.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("mcp sse connect failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || mediaType(resp) != "text/event-stream" {
+		err := statusError(resp)
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// run 读取SSE流，断开后重连；重连次数用完时关闭传输
+func (t *SSETransport) run(resp *http.Response) {
+	for failures := 0; ; {
+		if resp != nil {
+			failures = 0
+			t.readEvents(resp.Body)
+			t.resetEndpoint()
+		}
+		if t.ctx.Err() != nil {
+			return
+		}
+
+		failures++
+		if t.cfg.MaxReconnects < 0 || failures > t.cfg.MaxReconnects {
+			t.cancel()
+			return
+		}
+		if !sleepContext(t.ctx, t.cfg.reconnectDelay(failures)) {
+			return
+		}
+		resp, _ = t.connect()
+	}
+}
+
+// readEvents 读取事件直至流结束
+func (t *SSETransport) readEvents(body io.ReadCloser) {
+	defer body.Close()
+	reader := newSSEReader(body)
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			return
+		}
+		switch event.Event {
+		case "endpoint":
+			t.setEndpoint(event.Data)
+		case "", "message":
+			var msg Message
+			if json.Unmarshal([]byte(event.Data), &msg) == nil {
+				t.deliver(&msg)
+			}
+		}
+	}
+}
+
+// setEndpoint 解析消息地址（可为相对地址）
+func (t *SSETransport) setEndpoint(data string) {
+	base, err := url.Parse(t.cfg.URL)
+	if err != nil {
+		return
+	}
+	ref, err := url.Parse(data)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endpoint = base.ResolveReference(ref).String()
+	select {
+	case <-t.ready:
+	default:
+		close(t.ready)
+	}
+}
+
+// resetEndpoint 流断开后清除消息地址，等待重连后的 endpoint 事件
+func (t *SSETransport) resetEndpoint() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.ready:
+		t.ready = make(chan struct{})
+	default:
+	}
+	t.endpoint = ""
+}
@@ -3,7 +3,9 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
@@ -39,26 +41,66 @@ func NewTool(name, description string, params map[string]interface{}, handler To
 }
 
 // ToEinoTool 转换为Eino工具
+// 参数校验失败时将错误作为工具结果返回给模型，而不是中断调用流程，便于模型改正后重试。
 func (t *MCPTool) ToEinoTool() tool.BaseTool {
 	toolInfo := &schema.ToolInfo{
 		Name: t.Definition.Name,
 		Desc: t.Definition.Description,
 	}
 
-	// 设置参数
-	if t.Definition.Parameters != nil {
-		toolInfo.ParamsOneOf = schema.NewParamsOneOfByJSONSchema(nil)
+	// 设置参数（无法转换的参数在调用时返回错误）
+	if paramsSchema, err := t.Definition.Schema(); err == nil && paramsSchema != nil {
+		toolInfo.ParamsOneOf = schema.NewParamsOneOfByJSONSchema(paramsSchema)
 	}
 
-	return utils.NewTool(toolInfo, func(ctx context.Context, input *schema.ToolCall) (string, error) {
-		params, err := ParseToolArguments(input.Function.Arguments)
-		if err != nil {
-			return "", err
+	// 参数JSON直接解析为 map，与 ToolHandler 的参数类型一致
+	return utils.NewTool(toolInfo, func(ctx context.Context, params map[string]interface{}) (string, error) {
+		result, err := t.Call(ctx, params)
+		if errors.Is(err, ErrInvalidArguments) {
+			return "Error: " + err.Error(), nil
 		}
-		return t.Handler(ctx, params)
+		return result, err
 	})
 }
 
+// Call 校验参数后调用工具处理函数，处理函数 panic 时返回错误
+// 参数不符合 JSON Schema 时返回包装 ErrInvalidArguments 的错误，不调用处理函数。
+func (t *MCPTool) Call(ctx context.Context, params map[string]interface{}) (result string, err error) {
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	paramsSchema, err := t.Definition.Schema()
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateArguments(paramsSchema, params); err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("tool %s panicked: %v", t.Definition.Name, r)
+		}
+	}()
+	return t.Handler(ctx, params)
+}
+
+// InputSchema 返回参数的 JSON Schema（MCP tools/list 中的 inputSchema），未设置参数时为空对象
+func (d ToolDefinition) InputSchema() (json.RawMessage, error) {
+	paramsSchema, err := d.Schema()
+	if err != nil {
+		return nil, err
+	}
+	if paramsSchema == nil {
+		return json.RawMessage(`{"type":"object"}`), nil
+	}
+	data, err := json.Marshal(paramsSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters for tool %s: %w", d.Name, err)
+	}
+	return data, nil
+}
+
 // ParseToolArguments 解析工具参数
 func ParseToolArguments(arguments string) (map[string]interface{}, error) {
 	var params map[string]interface{}
@@ -70,6 +112,7 @@ func ParseToolArguments(arguments string) (map[string]interface{}, error) {
 
 // ToolRegistry 工具注册表
 type ToolRegistry struct {
+	mu    sync.RWMutex
 	tools map[string]*MCPTool
 }
 
@@ -82,17 +125,23 @@ func NewToolRegistry() *ToolRegistry {
 
 // Register 注册工具
 func (r *ToolRegistry) Register(tool *MCPTool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.tools[tool.Definition.Name] = tool
 }
 
 // Get 获取工具
 func (r *ToolRegistry) Get(name string) (*MCPTool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	tool, ok := r.tools[name]
 	return tool, ok
 }
 
 // GetAll 获取所有工具
 func (r *ToolRegistry) GetAll() []*MCPTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	result := make([]*MCPTool, 0, len(r.tools))
 	for _, tool := range r.tools {
 		result = append(result, tool)
@@ -102,6 +151,8 @@ func (r *ToolRegistry) GetAll() []*MCPTool {
 
 // ToEinoTools 转换为Eino工具列表
 func (r *ToolRegistry) ToEinoTools() []tool.BaseTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	result := make([]tool.BaseTool, 0, len(r.tools))
 	for _, t := range r.tools {
 		result = append(result, t.ToEinoTool())
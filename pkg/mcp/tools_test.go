@@ -2,8 +2,13 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
 )
 
 func TestNewToolRegistry(t *testing.T) {
@@ -201,3 +206,142 @@ func TestExampleTools(t *testing.T) {
 		t.Error("calculator tool not found in example tools")
 	}
 }
+
+// orderToolParams 嵌套对象和数组的参数定义
+func orderToolParams() map[string]interface{} {
+	return CreateParameterSchema(
+		map[string]interface{}{
+			"customer": CreateParameterSchema(
+				map[string]interface{}{
+					"name":  CreateStringProperty("Customer name"),
+					"level": CreateEnumProperty("Customer level", []string{"normal", "vip"}),
+				},
+				[]string{"name"},
+			),
+			"items": CreateArrayProperty("Order items", CreateParameterSchema(
+				map[string]interface{}{
+					"sku":      CreateStringProperty("SKU"),
+					"quantity": CreateIntegerProperty("Quantity"),
+				},
+				[]string{"sku", "quantity"},
+			)),
+			"express": CreateBooleanProperty("Express delivery"),
+		},
+		[]string{"customer", "items"},
+	)
+}
+
+func TestValidateArguments(t *testing.T) {
+	paramsSchema, err := ToolDefinition{Name: "order", Parameters: orderToolParams()}.Schema()
+	if err != nil {
+		t.Fatalf("Schema() error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		arguments string
+		problems  []string
+	}{
+		{
+			name:      "valid",
+			arguments: `{"customer":{"name":"Ann","level":"vip"},"items":[{"sku":"A1","quantity":2}],"express":true}`,
+		},
+		{
+			name:      "missing required",
+			arguments: `{"customer":{}}`,
+			problems:  []string{"items: required", "customer.name: required"},
+		},
+		{
+			name:      "wrong types",
+			arguments: `{"customer":"Ann","items":[{"sku":1,"quantity":1.5}],"express":"yes"}`,
+			problems: []string{
+				"customer: expected object, got string",
+				"items[0].quantity: expected integer, got number",
+				"items[0].sku: expected string, got integer",
+				"express: expected boolean, got string",
+			},
+		},
+		{
+			name:      "enum",
+			arguments: `{"customer":{"name":"Ann","level":"gold"},"items":[]}`,
+			problems:  []string{`customer.level: must be one of "normal", "vip"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseToolArguments(tt.arguments)
+			if err != nil {
+				t.Fatalf("ParseToolArguments() error: %v", err)
+			}
+			err = ValidateArguments(paramsSchema, params)
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Errorf("ValidateArguments() error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidArguments) {
+				t.Fatalf("ValidateArguments() error = %v, want ErrInvalidArguments", err)
+			}
+			for _, problem := range tt.problems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("error %q does not contain %q", err, problem)
+				}
+			}
+		})
+	}
+}
+
+func TestMCPTool_ToEinoTool(t *testing.T) {
+	called := false
+	mcpTool := NewTool("order", "Place an order", orderToolParams(),
+		func(ctx context.Context, params map[string]interface{}) (string, error) {
+			called = true
+			return "ok", nil
+		})
+	einoTool := mcpTool.ToEinoTool().(tool.InvokableTool)
+	ctx := context.Background()
+
+	// 模型看到的参数 schema
+	info, err := einoTool.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error: %v", err)
+	}
+	if info.ParamsOneOf == nil {
+		t.Fatal("ParamsOneOf is nil")
+	}
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil || js == nil {
+		t.Fatalf("ToJSONSchema() = %v, %v", js, err)
+	}
+	data, _ := json.Marshal(js)
+	for _, want := range []string{`"required":["customer","items"]`, `"enum":["normal","vip"]`, `"quantity":{"description":"Quantity","type":"integer"}`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("schema %s does not contain %s", data, want)
+		}
+	}
+
+	// 参数不合法时错误作为结果返回，不调用处理函数
+	out, err := einoTool.InvokableRun(ctx, `{"customer":{"name":"Ann"}}`)
+	if err != nil {
+		t.Fatalf("InvokableRun() error: %v", err)
+	}
+	if called || !strings.Contains(out, "items: required") {
+		t.Errorf("InvokableRun() = %q, handler called: %v", out, called)
+	}
+
+	out, err = einoTool.InvokableRun(ctx, `{"customer":{"name":"Ann"},"items":[{"sku":"A1","quantity":1}]}`)
+	if err != nil || out != "ok" || !called {
+		t.Errorf("InvokableRun() = %q, %v", out, err)
+	}
+
+	// 未设置参数的工具没有参数 schema
+	noParams := NewTool("ping", "Ping", nil, func(ctx context.Context, params map[string]interface{}) (string, error) {
+		return "pong", nil
+	})
+	info, _ = noParams.ToEinoTool().Info(ctx)
+	if info.ParamsOneOf != nil {
+		t.Errorf("ParamsOneOf = %+v, want nil", info.ParamsOneOf)
+	}
+}
@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Transport JSON-RPC 消息传输
+type Transport interface {
+	// Send 发送一条消息
+	Send(ctx context.Context, msg *Message) error
+
+	// Recv 接收下一条消息，传输关闭后返回 io.EOF
+	Recv() (*Message, error)
+
+	// Close 关闭传输
+	Close() error
+}
+
+// maxMessageSize 单条消息的最大长度
+const maxMessageSize = 16 * 1024 * 1024
+
+// StreamTransport 基于字节流的传输，每行一条JSON消息（MCP stdio 格式）
+type StreamTransport struct {
+	reader *bufio.Reader
+	writer io.Writer
+	closer []io.Closer
+
+	mu sync.Mutex // 保护写入
+}
+
+// NewStreamTransport 创建字节流传输；r、w 实现 io.Closer 时在 Close 中关闭
+func NewStreamTransport(r io.Reader, w io.Writer) *StreamTransport {
+	t := &StreamTransport{
+		reader: bufio.NewReaderSize(r, 64*1024),
+		writer: w,
+	}
+	if c, ok := w.(io.Closer); ok {
+		t.closer = append(t.closer, c)
+	}
+	if c, ok := r.(io.Closer); ok {
+		t.closer = append(t.closer, c)
+	}
+	return t
+}
+
+// Send 发送一条消息
+func (t *StreamTransport) Send(ctx context.Context, msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	data = append(data, '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, err := t.writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Recv 接收下一条消息，跳过空行和无法解析的行
+func (t *StreamTransport) Recv() (*Message, error) {
+	for {
+		line, err := t.readLine()
+		if len(bytes.TrimSpace(line)) > 0 {
+			var msg Message
+			if jsonErr := json.Unmarshal(line, &msg); jsonErr == nil {
+				return &msg, nil
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readLine 读取一行（不超过 maxMessageSize）
+func (t *StreamTransport) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := t.reader.ReadLine()
+		line = append(line, chunk...)
+		if err != nil {
+			return line, err
+		}
+		if len(line) > maxMessageSize {
+			return nil, fmt.Errorf("message exceeds %d bytes", maxMessageSize)
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+// Close 关闭底层读写流
+func (t *StreamTransport) Close() error {
+	var errs []error
+	for _, c := range t.closer {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StdioConfig 子进程MCP服务端配置
+type StdioConfig struct {
+	Command string    // 可执行文件
+	Args    []string  // 命令行参数
+	Env     []string  // 环境变量（KEY=VALUE），为空时继承当前进程
+	Dir     string    // 工作目录
+	Stderr  io.Writer // 子进程的标准错误输出（默认丢弃）
+}
+
+// stdioCloseTimeout 关闭标准输入后等待子进程退出的时间，超时后强制结束
+const stdioCloseTimeout = 5 * time.Second
+
+// StdioTransport 启动MCP服务端子进程，通过标准输入输出通信
+type StdioTransport struct {
+	*StreamTransport
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	closeOnce sync.Once
+}
+
+// NewStdioTransport 启动子进程并创建传输
+func NewStdioTransport(cfg StdioConfig) (*StdioTransport, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("mcp stdio command is required")
+	}
+
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Env = cfg.Env
+	cmd.Dir = cfg.Dir
+	cmd.Stderr = cfg.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start mcp server %s: %w", cfg.Command, err)
+	}
+
+	return &StdioTransport{
+		StreamTransport: NewStreamTransport(stdout, stdin),
+		cmd:             cmd,
+		stdin:           stdin,
+	}, nil
+}
+
+// Close 关闭标准输入并等待子进程退出，超时后强制结束（子进程的退出状态不作为错误返回）
+func (t *StdioTransport) Close() error {
+	t.closeOnce.Do(func() {
+		t.stdin.Close()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- t.cmd.Wait()
+		}()
+		select {
+		case <-done:
+		case <-time.After(stdioCloseTimeout):
+			t.cmd.Process.Kill()
+			<-done
+		}
+	})
+	return nil
+}
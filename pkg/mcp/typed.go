@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/eino-contrib/jsonschema"
+)
+
+// NewTypedTool 根据Go类型创建工具，可直接注册到 ToolRegistry
+// 参数 schema 由 In 的字段和标签生成：json 标签决定参数名，没有 omitempty 的字段为必填；
+// jsonschema 标签补充描述和约束，如 `jsonschema:"description=温度单位,enum=celsius,enum=fahrenheit"`。
+// 调用时参数解码为 In，返回值编码为JSON（Out 为 string 时直接返回）。
+// In 必须是结构体（或结构体指针、map），否则 panic。
+func NewTypedTool[In, Out any](name, description string, fn func(ctx context.Context, in In) (Out, error)) *MCPTool {
+	params, err := typedParameters(reflect.TypeFor[In]())
+	if err != nil {
+		panic(fmt.Sprintf("mcp: NewTypedTool %s: %v", name, err))
+	}
+
+	return NewTool(name, description, params, func(ctx context.Context, params map[string]interface{}) (string, error) {
+		var in In
+		data, err := json.Marshal(params)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode tool arguments: %w", err)
+		}
+		if err := json.Unmarshal(data, &in); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+		}
+
+		out, err := fn(ctx, in)
+		if err != nil {
+			return "", err
+		}
+		if s, ok := any(out).(string); ok {
+			return s, nil
+		}
+		result, err := json.Marshal(out)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode tool result: %w", err)
+		}
+		return string(result), nil
+	})
+}
+
+// typedParameters 由Go类型生成参数 schema（ToolDefinition.Parameters 格式）
+func typedParameters(t reflect.Type) (map[string]interface{}, error) {
+	r := &jsonschema.Reflector{
+		Anonymous:      true,
+		DoNotReference: true,
+	}
+	s := r.ReflectFromType(t)
+	s.Version = ""
+	if s.Type != "object" {
+		return nil, fmt.Errorf("input type %s is not an object", t)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("failed to decode schema: %w", err)
+	}
+	return params, nil
+}
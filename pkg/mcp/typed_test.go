@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+type weatherInput struct {
+	City  string   `json:"city" jsonschema:"description=城市名称"`
+	Unit  string   `json:"unit,omitempty" jsonschema:"description=温度单位,enum=celsius,enum=fahrenheit"`
+	Days  int      `json:"days,omitempty"`
+	Hours []string `json:"hours,omitempty"`
+}
+
+type weatherOutput struct {
+	City        string  `json:"city"`
+	Temperature float64 `json:"temperature"`
+	Unit        string  `json:"unit"`
+}
+
+func newWeatherTool(calls *int) *MCPTool {
+	return NewTypedTool("weather", "查询天气", func(ctx context.Context, in weatherInput) (weatherOutput, error) {
+		*calls++
+		if in.City == "nowhere" {
+			return weatherOutput{}, errors.New("unknown city")
+		}
+		unit := in.Unit
+		if unit == "" {
+			unit = "celsius"
+		}
+		return weatherOutput{City: in.City, Temperature: 25, Unit: unit}, nil
+	})
+}
+
+func TestNewTypedTool_Schema(t *testing.T) {
+	var calls int
+	inputSchema, err := newWeatherTool(&calls).Definition.InputSchema()
+	if err != nil {
+		t.Fatalf("InputSchema() error: %v", err)
+	}
+
+	var s struct {
+		Type       string                            `json:"type"`
+		Required   []string                          `json:"required"`
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(inputSchema, &s); err != nil {
+		t.Fatalf("invalid schema %s: %v", inputSchema, err)
+	}
+	if s.Type != "object" || len(s.Required) != 1 || s.Required[0] != "city" {
+		t.Errorf("schema = %s", inputSchema)
+	}
+	if s.Properties["city"]["description"] != "城市名称" || s.Properties["days"]["type"] != "integer" {
+		t.Errorf("schema = %s", inputSchema)
+	}
+	if enum, _ := json.Marshal(s.Properties["unit"]["enum"]); string(enum) != `["celsius","fahrenheit"]` {
+		t.Errorf("unit enum = %s", enum)
+	}
+	if s.Properties["hours"]["type"] != "array" {
+		t.Errorf("hours = %v", s.Properties["hours"])
+	}
+}
+
+func TestNewTypedTool_Call(t *testing.T) {
+	var calls int
+	registry := NewToolRegistry()
+	registry.Register(newWeatherTool(&calls))
+	registry.Register(NewTypedTool("greet", "问候", func(ctx context.Context, in struct {
+		Name string `json:"name"`
+	}) (string, error) {
+		return "hello " + in.Name, nil
+	}))
+	weather, _ := registry.Get("weather")
+	greet, _ := registry.Get("greet")
+	ctx := context.Background()
+
+	params, _ := ParseToolArguments(`{"city":"北京","unit":"fahrenheit","days":3}`)
+	out, err := weather.Call(ctx, params)
+	if err != nil || out != `{"city":"北京","temperature":25,"unit":"fahrenheit"}` {
+		t.Errorf("Call() = %s, %v", out, err)
+	}
+
+	// 返回值为 string 时不编码
+	out, err = greet.Call(ctx, map[string]interface{}{"name": "Ann"})
+	if err != nil || out != "hello Ann" {
+		t.Errorf("Call(greet) = %q, %v", out, err)
+	}
+
+	// 参数不合法时不调用处理函数
+	calls = 0
+	for _, arguments := range []string{`{"unit":"celsius"}`, `{"city":"北京","unit":"kelvin"}`, `{"city":"北京","days":1.5}`} {
+		params, _ := ParseToolArguments(arguments)
+		if _, err := weather.Call(ctx, params); !errors.Is(err, ErrInvalidArguments) {
+			t.Errorf("Call(%s) error = %v, want ErrInvalidArguments", arguments, err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("handler called %d times for invalid arguments", calls)
+	}
+
+	if _, err := weather.Call(ctx, map[string]interface{}{"city": "nowhere"}); err == nil || err.Error() != "unknown city" {
+		t.Errorf("Call(nowhere) error = %v", err)
+	}
+
+	// 转换为 Eino 工具
+	result, err := weather.ToEinoTool().(tool.InvokableTool).InvokableRun(ctx, `{"city":"上海"}`)
+	if err != nil || !strings.Contains(result, `"unit":"celsius"`) {
+		t.Errorf("InvokableRun() = %s, %v", result, err)
+	}
+}
+
+func TestNewTypedTool_NonObject(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "not an object") {
+			t.Errorf("recover() = %v, want not an object panic", r)
+		}
+	}()
+	NewTypedTool("bad", "", func(ctx context.Context, in string) (string, error) {
+		return in, nil
+	})
+}
@@ -0,0 +1,205 @@
+// Package metrics 提供 Prometheus 指标
+// 通过 Metrics.Middleware 记录每次调用的请求数、错误数、耗时、首个token耗时、token用量和进行中的请求数，
+// 标签为 provider、model、endpoint；通过 Metrics.OnRetry 作为适配器的重试钩子记录重试次数。
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/circuit"
+	"ai-bridge/pkg/ratelimit"
+	"ai-bridge/pkg/types"
+)
+
+// namespace 指标名前缀
+const namespace = "ai_bridge"
+
+// 标签名
+var (
+	labels      = []string{"provider", "model", "endpoint"}
+	errorLabels = []string{"provider", "model", "endpoint", "type"}
+	tokenLabels = []string{"provider", "model", "endpoint", "type"}
+)
+
+// Metrics Prometheus 指标集合
+type Metrics struct {
+	requests         *prometheus.CounterVec
+	errors           *prometheus.CounterVec
+	duration         *prometheus.HistogramVec
+	timeToFirstToken *prometheus.HistogramVec
+	tokens           *prometheus.CounterVec
+	inFlight         *prometheus.GaugeVec
+	retries          *prometheus.CounterVec
+}
+
+// New 创建指标并注册到 reg（通常为 prometheus.DefaultRegisterer）
+func New(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "requests_total",
+			Help: "Total number of model calls.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "errors_total",
+			Help: "Total number of failed model calls by error type.",
+		}, errorLabels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "request_duration_seconds",
+			Help:    "Model call latency; streaming calls are measured until the stream ends.",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+		}, labels),
+		timeToFirstToken: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "time_to_first_token_seconds",
+			Help:    "Time until the first chunk of a streaming response.",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+		}, labels),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "tokens_total",
+			Help: "Total number of tokens by type (input, output).",
+		}, tokenLabels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "in_flight_requests",
+			Help: "Number of model calls in progress, including open streams.",
+		}, labels),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "retries_total",
+			Help: "Total number of upstream retries by the error that triggered them.",
+		}, errorLabels),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.errors, m.duration, m.timeToFirstToken, m.tokens, m.inFlight, m.retries} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// endpointKey 上下文中 endpoint 标签的键
+type endpointKey struct{}
+
+// WithEndpoint 设置本次调用的 endpoint 标签（如服务端路由 /chat），未设置时使用调用类型
+func WithEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
+
+// Middleware 返回记录指标的调用中间件
+func (m *Metrics) Middleware() types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			values := labelValues(ctx, req)
+			m.requests.WithLabelValues(values...).Inc()
+			m.inFlight.WithLabelValues(values...).Inc()
+			start := time.Now()
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				m.finish(values, start, usage{}, err)
+				return nil, err
+			}
+			if resp != nil && resp.Stream != nil {
+				resp.Stream = m.observeStream(values, start, resp.Stream)
+				return resp, nil
+			}
+
+			var u usage
+			if resp != nil {
+				u.add(resp.Message)
+			}
+			m.finish(values, start, u, nil)
+			return resp, nil
+		}
+	}
+}
+
+// observeStream 转发流式响应，记录首个token耗时，流结束时记录耗时和用量
+func (m *Metrics) observeStream(values []string, start time.Time, stream *schema.StreamReader[*schema.Message]) *schema.StreamReader[*schema.Message] {
+	var (
+		u     usage
+		first = true
+	)
+	return adapters.RelayStream(stream, func(msg *schema.Message) {
+		if first {
+			first = false
+			m.timeToFirstToken.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+		}
+		u.add(msg)
+	}, func(err error) {
+		if errors.Is(err, adapters.ErrStreamClosed) {
+			err = nil
+		}
+		m.finish(values, start, u, err)
+	})
+}
+
+// finish 记录一次调用结束
+func (m *Metrics) finish(values []string, start time.Time, u usage, err error) {
+	m.inFlight.WithLabelValues(values...).Dec()
+	m.duration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+	if u.input > 0 {
+		m.tokens.WithLabelValues(append(values, "input")...).Add(float64(u.input))
+	}
+	if u.output > 0 {
+		m.tokens.WithLabelValues(append(values, "output")...).Add(float64(u.output))
+	}
+	if err != nil {
+		m.errors.WithLabelValues(append(values, ErrorType(err))...).Inc()
+	}
+}
+
+// usage 从响应（或流式块）中收集的token用量，后到的用量覆盖先到的
+type usage struct {
+	input, output int
+}
+
+// add 合并一条响应消息或流式块中的用量
+func (u *usage) add(msg *schema.Message) {
+	if msg != nil && msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
+		u.input, u.output = msg.ResponseMeta.Usage.PromptTokens, msg.ResponseMeta.Usage.CompletionTokens
+	}
+}
+
+// OnRetry 记录一次重试，可用作 options.WithOnRetry 或 bridge.ProviderConfig.OnRetry 的钩子
+func (m *Metrics) OnRetry(ctx context.Context, event types.RetryEvent) {
+	endpoint, _ := ctx.Value(endpointKey{}).(string)
+	m.retries.WithLabelValues(string(event.Provider), event.Model, endpoint, ErrorType(event.Err)).Inc()
+}
+
+// labelValues 计算 provider、model、endpoint 标签值
+func labelValues(ctx context.Context, req *types.Request) []string {
+	values := make([]string, 3, 4)
+	if req.Info != nil {
+		values[0], values[1] = string(req.Info.Provider), req.Info.Name
+	}
+	values[2] = string(req.Op)
+	if endpoint, ok := ctx.Value(endpointKey{}).(string); ok && endpoint != "" {
+		values[2] = endpoint
+	}
+	return values
+}
+
+// ErrorType 错误分类，用作 errors_total 的 type 标签
+// 取值：timeout、canceled、rate_limited、circuit_open、HTTP状态码、other
+func ErrorType(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, ratelimit.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, circuit.ErrOpen):
+		return "circuit_open"
+	}
+	if code := adapters.StatusCodeOf(err); code != 0 {
+		return strconv.Itoa(code)
+	}
+	return "other"
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/circuit"
+	"ai-bridge/pkg/ratelimit"
+	"ai-bridge/pkg/types"
+)
+
+var gpt4o = &types.ModelInfo{Provider: types.ProviderGPT, Name: "gpt-4o"}
+
+// withUsage 带用量的响应
+func withUsage(content string, input, output int) *schema.Message {
+	msg := schema.AssistantMessage(content, nil)
+	msg.ResponseMeta = &schema.ResponseMeta{Usage: &schema.TokenUsage{PromptTokens: input, CompletionTokens: output}}
+	return msg
+}
+
+func TestMiddlewareChat(t *testing.T) {
+	m, err := New(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	handler := m.Middleware()(func(ctx context.Context, req *types.Request) (*types.Response, error) {
+		if got := testutil.ToFloat64(m.inFlight.WithLabelValues("gpt", "gpt-4o", "/chat")); got != 1 {
+			t.Errorf("in-flight during call = %v, want 1", got)
+		}
+		return &types.Response{Message: withUsage("hi", 10, 2)}, nil
+	})
+
+	ctx := WithEndpoint(context.Background(), "/chat")
+	if _, err := handler(ctx, &types.Request{Op: types.OpChat, Info: gpt4o}); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("gpt", "gpt-4o", "/chat")); got != 1 {
+		t.Errorf("requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.inFlight.WithLabelValues("gpt", "gpt-4o", "/chat")); got != 0 {
+		t.Errorf("in-flight = %v, want 0", got)
+	}
+	if in, out := testutil.ToFloat64(m.tokens.WithLabelValues("gpt", "gpt-4o", "/chat", "input")),
+		testutil.ToFloat64(m.tokens.WithLabelValues("gpt", "gpt-4o", "/chat", "output")); in != 10 || out != 2 {
+		t.Errorf("tokens = %v/%v, want 10/2", in, out)
+	}
+	if n := testutil.CollectAndCount(m.duration); n != 1 {
+		t.Errorf("duration series = %d, want 1", n)
+	}
+}
+
+func TestMiddlewareStream(t *testing.T) {
+	m, _ := New(prometheus.NewRegistry())
+	handler := m.Middleware()(func(ctx context.Context, req *types.Request) (*types.Response, error) {
+		return &types.Response{Stream: schema.StreamReaderFromArray([]*schema.Message{
+			schema.AssistantMessage("a", nil),
+			withUsage("b", 3, 2),
+		})}, nil
+	})
+
+	resp, err := handler(context.Background(), &types.Request{Op: types.OpChatStream, Info: gpt4o})
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	inFlight := m.inFlight.WithLabelValues("gpt", "gpt-4o", "chat_stream")
+	if got := testutil.ToFloat64(inFlight); got != 1 {
+		t.Errorf("in-flight while streaming = %v, want 1", got)
+	}
+	for {
+		if _, err := resp.Stream.Recv(); err == io.EOF {
+			break
+		}
+	}
+	resp.Stream.Close()
+
+	// 流在转发协程中结束
+	for i := 0; i < 100 && testutil.ToFloat64(inFlight) != 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if got := testutil.ToFloat64(inFlight); got != 0 {
+		t.Errorf("in-flight after stream = %v, want 0", got)
+	}
+	if n := testutil.CollectAndCount(m.timeToFirstToken); n != 1 {
+		t.Errorf("ttft series = %d, want 1", n)
+	}
+	if got := testutil.ToFloat64(m.tokens.WithLabelValues("gpt", "gpt-4o", "chat_stream", "output")); got != 2 {
+		t.Errorf("output tokens = %v, want 2", got)
+	}
+}
+
+func TestMiddlewareErrors(t *testing.T) {
+	m, _ := New(prometheus.NewRegistry())
+	handler := m.Middleware()(func(ctx context.Context, req *types.Request) (*types.Response, error) {
+		return nil, &adapters.APIError{StatusCode: 503}
+	})
+
+	handler(context.Background(), &types.Request{Op: types.OpChat, Info: gpt4o})
+	if got := testutil.ToFloat64(m.errors.WithLabelValues("gpt", "gpt-4o", "chat", "503")); got != 1 {
+		t.Errorf("errors{type=503} = %v, want 1", got)
+	}
+}
+
+func TestOnRetry(t *testing.T) {
+	m, _ := New(prometheus.NewRegistry())
+	ctx := WithEndpoint(context.Background(), "/chat")
+	for attempt := 1; attempt <= 2; attempt++ {
+		m.OnRetry(ctx, types.RetryEvent{Provider: types.ProviderGPT, Model: "gpt-4o", Attempt: attempt, Err: &adapters.APIError{StatusCode: 503}})
+	}
+	if got := testutil.ToFloat64(m.retries.WithLabelValues("gpt", "gpt-4o", "/chat", "503")); got != 2 {
+		t.Errorf("retries{type=503} = %v, want 2", got)
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{context.DeadlineExceeded, "timeout"},
+		{&ratelimit.LimitError{Scope: "gpt", Limit: "rpm"}, "rate_limited"},
+		{circuit.ErrOpen, "circuit_open"},
+		{&adapters.APIError{StatusCode: 429}, "429"},
+		{io.ErrUnexpectedEOF, "other"},
+	}
+	for _, tt := range tests {
+		if got := ErrorType(tt.err); got != tt.want {
+			t.Errorf("ErrorType(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNewRegistersOnce(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := New(reg); err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := New(reg); err == nil {
+		t.Error("registering the same metrics twice should fail")
+	}
+}
@@ -1,6 +1,9 @@
 package options
 
 import (
+	"context"
+	"log/slog"
+	"net/http"
 	"time"
 
 	"ai-bridge/pkg/types"
@@ -39,6 +42,28 @@ func WithMaxRetries(retries int) Option {
 	}
 }
 
+// WithRetryBackoff 设置重试退避的初始和最大等待时间
+func WithRetryBackoff(base, max time.Duration) Option {
+	return func(c *types.Config) {
+		c.RetryBaseDelay = base
+		c.RetryMaxDelay = max
+	}
+}
+
+// WithOnRetry 设置重试钩子（每次重试等待前调用）
+func WithOnRetry(hook func(ctx context.Context, event types.RetryEvent)) Option {
+	return func(c *types.Config) {
+		c.OnRetry = hook
+	}
+}
+
+// WithRetryIf 设置自定义可重试判断（如排除密钥相关错误，交由上层换用其他密钥）
+func WithRetryIf(retryable func(err error) bool) Option {
+	return func(c *types.Config) {
+		c.RetryIf = retryable
+	}
+}
+
 // WithTemperature 设置温度参数
 func WithTemperature(temp float32) Option {
 	return func(c *types.Config) {
@@ -110,6 +135,35 @@ func WithProxy(proxy string) Option {
 	}
 }
 
+// WithCAFile 设置自定义CA证书文件
+func WithCAFile(file string) Option {
+	return func(c *types.Config) {
+		c.CAFile = file
+	}
+}
+
+// WithClientCert 设置mTLS客户端证书和私钥文件
+func WithClientCert(certFile, keyFile string) Option {
+	return func(c *types.Config) {
+		c.CertFile = certFile
+		c.KeyFile = keyFile
+	}
+}
+
+// WithInsecureSkipVerify 设置是否跳过服务端证书校验（仅用于测试环境）
+func WithInsecureSkipVerify(skip bool) Option {
+	return func(c *types.Config) {
+		c.InsecureSkipVerify = skip
+	}
+}
+
+// WithTransportWrapper 设置底层HTTP传输包装器（如 cassette.Recorder.Wrap）
+func WithTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) Option {
+	return func(c *types.Config) {
+		c.TransportWrapper = wrap
+	}
+}
+
 // WithEnableLog 设置是否启用日志
 func WithEnableLog(enable bool) Option {
 	return func(c *types.Config) {
@@ -117,6 +171,21 @@ func WithEnableLog(enable bool) Option {
 	}
 }
 
+// WithLogHandler 设置自定义日志处理器（同时启用日志）
+func WithLogHandler(handler slog.Handler) Option {
+	return func(c *types.Config) {
+		c.LogHandler = handler
+		c.EnableLog = true
+	}
+}
+
+// WithLogPayload 设置是否记录完整请求/响应内容（密钥会脱敏）
+func WithLogPayload(enable bool) Option {
+	return func(c *types.Config) {
+		c.LogPayload = enable
+	}
+}
+
 // WithSystemPrompt 设置系统提示词模板
 // 用于定义AI的角色和行为准则
 func WithSystemPrompt(prompt string) Option {
@@ -149,6 +218,20 @@ func WithSkillDir(dir string) Option {
 	}
 }
 
+// WithSafetySettings 设置安全过滤（仅Gemini使用）
+func WithSafetySettings(settings ...types.SafetySetting) Option {
+	return func(c *types.Config) {
+		c.SafetySettings = append(c.SafetySettings, settings...)
+	}
+}
+
+// WithMiddleware 添加调用中间件（按添加顺序由外到内执行）
+func WithMiddleware(middlewares ...types.Middleware) Option {
+	return func(c *types.Config) {
+		c.Middlewares = append(c.Middlewares, middlewares...)
+	}
+}
+
 // ApplyOptions 应用配置选项
 func ApplyOptions(opts ...Option) *types.Config {
 	config := types.DefaultConfig()
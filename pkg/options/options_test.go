@@ -1,8 +1,12 @@
 package options
 
 import (
+	"io"
+	"log/slog"
 	"testing"
 	"time"
+
+	"ai-bridge/pkg/types"
 )
 
 func TestApplyOptions(t *testing.T) {
@@ -47,6 +51,13 @@ func TestWithMaxRetries(t *testing.T) {
 	}
 }
 
+func TestWithRetryBackoff(t *testing.T) {
+	config := ApplyOptions(WithRetryBackoff(100*time.Millisecond, 5*time.Second))
+	if config.RetryBaseDelay != 100*time.Millisecond || config.RetryMaxDelay != 5*time.Second {
+		t.Errorf("Expected backoff 100ms/5s, got %v/%v", config.RetryBaseDelay, config.RetryMaxDelay)
+	}
+}
+
 func TestWithTemperature(t *testing.T) {
 	config := ApplyOptions(WithTemperature(0.5))
 	if config.Temperature != 0.5 {
@@ -110,6 +121,23 @@ func TestWithProxy(t *testing.T) {
 	}
 }
 
+func TestWithTLSOptions(t *testing.T) {
+	config := ApplyOptions(
+		WithCAFile("/etc/ca.pem"),
+		WithClientCert("/etc/client.pem", "/etc/client-key.pem"),
+		WithInsecureSkipVerify(true),
+	)
+	if config.CAFile != "/etc/ca.pem" {
+		t.Errorf("Expected CA file '/etc/ca.pem', got '%s'", config.CAFile)
+	}
+	if config.CertFile != "/etc/client.pem" || config.KeyFile != "/etc/client-key.pem" {
+		t.Errorf("Unexpected client cert: %s, %s", config.CertFile, config.KeyFile)
+	}
+	if !config.InsecureSkipVerify {
+		t.Error("Expected InsecureSkipVerify to be true")
+	}
+}
+
 func TestWithEnableLog(t *testing.T) {
 	config := ApplyOptions(WithEnableLog(true))
 	if !config.EnableLog {
@@ -117,6 +145,30 @@ func TestWithEnableLog(t *testing.T) {
 	}
 }
 
+func TestWithLogHandler(t *testing.T) {
+	handler := slog.NewTextHandler(io.Discard, nil)
+	config := ApplyOptions(WithLogHandler(handler), WithLogPayload(true))
+	if config.LogHandler != handler {
+		t.Error("Expected custom log handler")
+	}
+	if !config.EnableLog || !config.LogPayload {
+		t.Errorf("Expected logging and payload logging enabled, got %v/%v", config.EnableLog, config.LogPayload)
+	}
+}
+
+func TestWithSafetySettings(t *testing.T) {
+	config := ApplyOptions(WithSafetySettings(
+		types.SafetySetting{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_ONLY_HIGH"},
+		types.SafetySetting{Category: "HARM_CATEGORY_HATE_SPEECH", Threshold: "BLOCK_NONE"},
+	))
+	if len(config.SafetySettings) != 2 {
+		t.Fatalf("Expected 2 safety settings, got %d", len(config.SafetySettings))
+	}
+	if config.SafetySettings[1].Threshold != "BLOCK_NONE" {
+		t.Errorf("Expected threshold 'BLOCK_NONE', got '%s'", config.SafetySettings[1].Threshold)
+	}
+}
+
 func TestMultipleOptions(t *testing.T) {
 	config := ApplyOptions(
 		WithAPIKey("my-key"),
@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// perMessageTokens 每条消息的格式开销（角色、分隔符等）
+const perMessageTokens = 4
+
+// EstimateTokens 估算一次调用消耗的token数，用于TPM预扣
+// 输入按 ASCII 字符 4 个约 1 token、其他字符（如中文）1 个约 1 token 粗略估算，
+// 再加上 maxOutput（最大输出token数，与厂商按 max_tokens 计入TPM的做法一致）。
+func EstimateTokens(messages []*schema.Message, maxOutput int) int {
+	ascii, other := 0, 0
+	count := func(s string) {
+		for _, r := range s {
+			if r < utf8.RuneSelf {
+				ascii++
+			} else {
+				other++
+			}
+		}
+	}
+
+	tokens := 0
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		tokens += perMessageTokens
+		count(msg.Content)
+		count(msg.ReasoningContent)
+		for _, call := range msg.ToolCalls {
+			count(call.Function.Name)
+			count(call.Function.Arguments)
+		}
+	}
+	return tokens + (ascii+3)/4 + other + max(maxOutput, 0)
+}
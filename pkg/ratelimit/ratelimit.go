@@ -0,0 +1,298 @@
+// Package ratelimit 提供客户端侧的RPM/TPM令牌桶限流
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Mode 超出限制时的处理方式
+type Mode string
+
+const (
+	ModeWait     Mode = "wait"      // 阻塞等待直到有可用额度（默认）
+	ModeFailFast Mode = "fail_fast" // 立即返回 *LimitError
+)
+
+// ErrRateLimited 超出客户端速率限制（可通过 errors.Is 判断）
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Limits 速率限制，0 表示不限制
+type Limits struct {
+	RPM int // 每分钟请求数
+	TPM int // 每分钟token数（调用前按估算值预扣，调用结束后按实际用量修正）
+}
+
+// IsZero 是否未设置任何限制
+func (l Limits) IsZero() bool {
+	return l.RPM <= 0 && l.TPM <= 0
+}
+
+// LimitError 超出速率限制的错误
+type LimitError struct {
+	Scope      string        // 限流作用域，如 gpt、gpt/gpt-4o
+	Limit      string        // 触发的限制：rpm 或 tpm
+	RetryAfter time.Duration // 预计恢复所需的等待时间
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded: %s %s, retry after %s", e.Scope, e.Limit, e.RetryAfter.Round(time.Millisecond))
+}
+
+// Is 使 errors.Is(err, ErrRateLimited) 成立
+func (e *LimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// bucket 令牌桶，容量为每分钟限额，按秒匀速补充
+type bucket struct {
+	capacity float64
+	tokens   float64
+	rate     float64 // 每秒补充的令牌数
+	last     time.Time
+}
+
+// newBucket 创建满额令牌桶，perMinute <= 0 时返回 nil（不限制）
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+// advance 按经过的时间补充令牌
+func (b *bucket) advance(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(b.capacity, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// wait 获取 n 个令牌需要等待的时间（n 超过容量时按容量计算，避免永远无法满足）
+func (b *bucket) wait(n float64) time.Duration {
+	n = min(n, b.capacity)
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
+
+// take 扣除令牌（允许为负，表示已被预约）
+func (b *bucket) take(n float64) {
+	b.tokens -= min(n, b.capacity)
+}
+
+// give 归还令牌（不超过容量）
+func (b *bucket) give(n float64) {
+	b.tokens = min(b.capacity, b.tokens+n)
+}
+
+// Limiter 单个作用域（厂商、模型或密钥）的RPM与TPM限制器（并发安全）
+type Limiter struct {
+	mu       sync.Mutex
+	scope    string
+	limits   Limits
+	requests *bucket
+	tokens   *bucket
+	now      func() time.Time
+}
+
+// NewLimiter 创建限制器，limits 为零值时返回 nil（不限制）
+func NewLimiter(scope string, limits Limits) *Limiter {
+	if limits.IsZero() {
+		return nil
+	}
+	now := time.Now()
+	return &Limiter{
+		scope:    scope,
+		limits:   limits,
+		requests: newBucket(limits.RPM, now),
+		tokens:   newBucket(limits.TPM, now),
+		now:      time.Now,
+	}
+}
+
+// Scope 获取限流作用域
+func (l *Limiter) Scope() string {
+	return l.scope
+}
+
+// Limits 获取限制配置
+func (l *Limiter) Limits() Limits {
+	return l.limits
+}
+
+// reserve 预约一次请求和 tokens 个token，返回需要等待的时间
+// failFast 为 true 时，额度不足则不扣除并返回 *LimitError
+func (l *Limiter) reserve(tokens int, failFast bool) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	limit := ""
+	if l.requests != nil {
+		l.requests.advance(now)
+		if w := l.requests.wait(1); w > wait {
+			wait, limit = w, "rpm"
+		}
+	}
+	if l.tokens != nil && tokens > 0 {
+		l.tokens.advance(now)
+		if w := l.tokens.wait(float64(tokens)); w > wait {
+			wait, limit = w, "tpm"
+		}
+	}
+
+	if failFast && wait > 0 {
+		return 0, &LimitError{Scope: l.scope, Limit: limit, RetryAfter: wait}
+	}
+	if l.requests != nil {
+		l.requests.take(1)
+	}
+	if l.tokens != nil && tokens > 0 {
+		l.tokens.take(float64(tokens))
+	}
+	return wait, nil
+}
+
+// cancel 撤销一次预约
+func (l *Limiter) cancel(tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.requests != nil {
+		l.requests.give(1)
+	}
+	if l.tokens != nil && tokens > 0 {
+		l.tokens.give(float64(tokens))
+	}
+}
+
+// adjust 按实际用量修正token预扣（delta > 0 补扣，delta < 0 归还）
+func (l *Limiter) adjust(delta int) {
+	if l.tokens == nil || delta == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens.advance(l.now())
+	if delta > 0 {
+		l.tokens.take(float64(delta))
+	} else {
+		l.tokens.give(float64(-delta))
+	}
+}
+
+// Reservation 一次调用的额度预约
+type Reservation struct {
+	limiters []*Limiter
+	tokens   int
+}
+
+// Settle 调用结束后按实际token用量修正预扣额度
+// actual 为 0 表示调用未消耗token（如请求失败），预扣的token全部归还
+func (r *Reservation) Settle(actual int) {
+	if r == nil {
+		return
+	}
+	for _, l := range r.limiters {
+		l.adjust(actual - r.tokens)
+	}
+	r.tokens = actual
+}
+
+// Acquire 从所有限制器预约一次请求和 tokens 个token
+// ModeWait 下阻塞直到额度可用或 ctx 结束；ModeFailFast 下额度不足立即返回 *LimitError。
+// nil 限制器会被忽略。
+func Acquire(ctx context.Context, mode Mode, tokens int, limiters ...*Limiter) (*Reservation, error) {
+	failFast := mode == ModeFailFast
+	res := &Reservation{tokens: tokens}
+
+	var wait time.Duration
+	for _, l := range limiters {
+		if l == nil {
+			continue
+		}
+		w, err := l.reserve(tokens, failFast)
+		if err != nil {
+			res.cancel()
+			return nil, err
+		}
+		res.limiters = append(res.limiters, l)
+		wait = max(wait, w)
+	}
+
+	if wait <= 0 {
+		return res, nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return res, nil
+	case <-ctx.Done():
+		res.cancel()
+		return nil, ctx.Err()
+	}
+}
+
+// cancel 撤销所有已成功的预约
+func (r *Reservation) cancel() {
+	for _, l := range r.limiters {
+		l.cancel(r.tokens)
+	}
+}
+
+// modeKey 上下文中限流模式的键
+type modeKey struct{}
+
+// WithMode 在上下文中指定本次调用的限流模式（覆盖客户端默认模式）
+func WithMode(ctx context.Context, mode Mode) context.Context {
+	return context.WithValue(ctx, modeKey{}, mode)
+}
+
+// ModeFromContext 获取上下文中的限流模式，未指定时返回 def
+func ModeFromContext(ctx context.Context, def Mode) Mode {
+	if mode, ok := ctx.Value(modeKey{}).(Mode); ok && mode != "" {
+		return mode
+	}
+	return def
+}
+
+// Registry 限制器注册表（并发安全），同一作用域共享同一个限制器
+type Registry struct {
+	mu       sync.Mutex
+	limiters map[string]*Limiter
+}
+
+// NewRegistry 创建限制器注册表
+func NewRegistry() *Registry {
+	return &Registry{limiters: make(map[string]*Limiter)}
+}
+
+// Get 获取或创建作用域的限制器，limits 为零值时返回 nil
+// 作用域的限制器在首次创建后保持不变
+func (r *Registry) Get(scope string, limits Limits) *Limiter {
+	if limits.IsZero() {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if l, ok := r.limiters[scope]; ok {
+		return l
+	}
+	l := NewLimiter(scope, limits)
+	r.limiters[scope] = l
+	return l
+}
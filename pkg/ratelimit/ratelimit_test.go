@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// fakeClock 可控时钟
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// newTestLimiter 创建使用可控时钟的限制器
+func newTestLimiter(scope string, limits Limits) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	l := NewLimiter(scope, limits)
+	l.now = clock.Now
+	l.requests = newBucket(limits.RPM, clock.now)
+	l.tokens = newBucket(limits.TPM, clock.now)
+	return l, clock
+}
+
+func TestAcquireFailFast(t *testing.T) {
+	l, clock := newTestLimiter("gpt/gpt-4o", Limits{RPM: 2, TPM: 1000})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := Acquire(ctx, ModeFailFast, 400, l); err != nil {
+			t.Fatalf("Acquire(%d) error: %v", i, err)
+		}
+	}
+
+	_, err := Acquire(ctx, ModeFailFast, 100, l)
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected *LimitError, got %v", err)
+	}
+	if limitErr.Limit != "rpm" || limitErr.Scope != "gpt/gpt-4o" || limitErr.RetryAfter != 30*time.Second {
+		t.Errorf("LimitError = %+v, want rpm with 30s retry", limitErr)
+	}
+
+	// 半分钟后补充一个请求额度和500个token，共700个token，不足1000
+	clock.now = clock.now.Add(30 * time.Second)
+	_, err = Acquire(ctx, ModeFailFast, 1000, l)
+	if !errors.As(err, &limitErr) || limitErr.Limit != "tpm" {
+		t.Fatalf("expected tpm limit, got %v", err)
+	}
+	if _, err := Acquire(ctx, ModeFailFast, 100, l); err != nil {
+		t.Errorf("Acquire() after refill error: %v", err)
+	}
+}
+
+func TestAcquireMultipleLimiters(t *testing.T) {
+	provider, _ := newTestLimiter("gpt", Limits{RPM: 10})
+	model, _ := newTestLimiter("gpt/gpt-4o", Limits{RPM: 1})
+
+	if _, err := Acquire(context.Background(), ModeFailFast, 0, provider, nil, model); err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	if _, err := Acquire(context.Background(), ModeFailFast, 0, provider, model); err == nil {
+		t.Fatal("expected model limit error")
+	}
+
+	// 失败时撤销已成功的预约
+	provider.mu.Lock()
+	tokens := provider.requests.tokens
+	provider.mu.Unlock()
+	if tokens != 9 {
+		t.Errorf("provider requests left = %v, want 9", tokens)
+	}
+}
+
+func TestAcquireWait(t *testing.T) {
+	l := NewLimiter("gpt", Limits{TPM: 6000}) // 每秒补充100个token
+	ctx := context.Background()
+
+	if _, err := Acquire(ctx, ModeWait, 6000, l); err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := Acquire(ctx, ModeWait, 20, l); err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("waited %v, want about 200ms", elapsed)
+	}
+
+	// 等待期间上下文取消
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := Acquire(ctx, ModeWait, 6000, l); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestReservationSettle(t *testing.T) {
+	l, _ := newTestLimiter("gpt", Limits{TPM: 1000})
+
+	res, err := Acquire(context.Background(), ModeFailFast, 800, l)
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	// 实际只用了 300 个token，归还 500
+	res.Settle(300)
+	if _, err := Acquire(context.Background(), ModeFailFast, 700, l); err != nil {
+		t.Errorf("Acquire() after settle error: %v", err)
+	}
+}
+
+func TestModeFromContext(t *testing.T) {
+	ctx := context.Background()
+	if got := ModeFromContext(ctx, ModeWait); got != ModeWait {
+		t.Errorf("ModeFromContext() = %s, want default", got)
+	}
+	if got := ModeFromContext(WithMode(ctx, ModeFailFast), ModeWait); got != ModeFailFast {
+		t.Errorf("ModeFromContext() = %s, want fail_fast", got)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if r.Get("gpt", Limits{}) != nil {
+		t.Error("zero limits should return nil limiter")
+	}
+	a := r.Get("gpt", Limits{RPM: 10})
+	if a == nil || r.Get("gpt", Limits{RPM: 10}) != a {
+		t.Error("same scope should share limiter")
+	}
+	if r.Get("gpt/gpt-4o", Limits{RPM: 10}) == a {
+		t.Error("different scopes should not share limiter")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	messages := []*schema.Message{
+		schema.SystemMessage("abcdefgh"), // 2 tokens
+		schema.UserMessage("你好"),         // 2 tokens
+	}
+	if got, want := EstimateTokens(messages, 100), 2*perMessageTokens+2+2+100; got != want {
+		t.Errorf("EstimateTokens() = %d, want %d", got, want)
+	}
+}
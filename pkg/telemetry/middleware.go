@@ -0,0 +1,179 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/types"
+)
+
+// observer 记录一次调用的链路和指标
+type observer struct {
+	tracer trace.Tracer
+	ins    *instruments
+}
+
+// Middleware 返回记录链路和指标的调用中间件
+// 每次 Chat/ChatStream/Generate/GenerateStream 调用创建一个 "chat {model}" Client span，
+// 记录厂商、模型、token用量、结束原因和响应ID；流式调用在流结束时结束 span，
+// 并记录首个token耗时和输出速度。
+func Middleware(opts ...Option) types.Middleware {
+	cfg := newConfig(opts...)
+	o := &observer{
+		tracer: cfg.TracerProvider.Tracer(instrumentationName),
+		ins:    newInstruments(cfg.MeterProvider.Meter(instrumentationName)),
+	}
+
+	return func(next types.Handler) types.Handler {
+		return func(ctx context.Context, req *types.Request) (*types.Response, error) {
+			attrs := requestAttributes(req)
+			ctx, span := o.tracer.Start(ctx, spanName(req), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+			start := time.Now()
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				o.finish(ctx, span, start, attrs, responseInfo{}, err)
+				return nil, err
+			}
+			if resp != nil && resp.Stream != nil {
+				resp.Stream = o.observeStream(ctx, span, start, attrs, resp.Stream)
+				return resp, nil
+			}
+
+			var info responseInfo
+			if resp != nil {
+				info.add(resp.Message)
+			}
+			o.finish(ctx, span, start, attrs, info, nil)
+			return resp, nil
+		}
+	}
+}
+
+// observeStream 转发流式响应，首个块到达时记录首个token耗时，流结束时结束 span
+func (o *observer) observeStream(ctx context.Context, span trace.Span, start time.Time, attrs []attribute.KeyValue, stream *schema.StreamReader[*schema.Message]) *schema.StreamReader[*schema.Message] {
+	var (
+		info       responseInfo
+		firstChunk time.Time
+	)
+	return adapters.RelayStream(stream, func(msg *schema.Message) {
+		if firstChunk.IsZero() {
+			firstChunk = time.Now()
+			span.AddEvent("gen_ai.first_token")
+			o.ins.timeToFirstToken.Record(ctx, firstChunk.Sub(start).Seconds(), metric.WithAttributes(attrs...))
+		}
+		info.add(msg)
+	}, func(err error) {
+		// 调用方提前关闭，按已收到的内容结束
+		if errors.Is(err, adapters.ErrStreamClosed) {
+			err = nil
+		}
+		o.finishStream(ctx, span, start, firstChunk, attrs, info, err)
+	})
+}
+
+// finishStream 结束流式调用，有输出token时记录输出速度
+func (o *observer) finishStream(ctx context.Context, span trace.Span, start, firstChunk time.Time, attrs []attribute.KeyValue, info responseInfo, err error) {
+	if !firstChunk.IsZero() && info.outputTokens > 0 {
+		if elapsed := time.Since(firstChunk).Seconds(); elapsed > 0 {
+			o.ins.tokensPerSecond.Record(ctx, float64(info.outputTokens)/elapsed, metric.WithAttributes(attrs...))
+		}
+	}
+	o.finish(ctx, span, start, attrs, info, err)
+}
+
+// finish 记录响应属性、耗时和token用量并结束 span
+func (o *observer) finish(ctx context.Context, span trace.Span, start time.Time, attrs []attribute.KeyValue, info responseInfo, err error) {
+	defer span.End()
+
+	if info.id != "" {
+		span.SetAttributes(AttrResponseID.String(info.id))
+	}
+	if info.finishReason != "" {
+		span.SetAttributes(AttrResponseFinishReasons.StringSlice([]string{info.finishReason}))
+	}
+	if info.hasUsage {
+		span.SetAttributes(AttrUsageInputTokens.Int(info.inputTokens), AttrUsageOutputTokens.Int(info.outputTokens))
+		o.ins.tokenUsage.Record(ctx, int64(info.inputTokens), metric.WithAttributes(append(attrs, AttrTokenType.String("input"))...))
+		o.ins.tokenUsage.Record(ctx, int64(info.outputTokens), metric.WithAttributes(append(attrs, AttrTokenType.String("output"))...))
+	}
+
+	durationAttrs := attrs
+	if err != nil {
+		errType := errorType(err)
+		durationAttrs = append(attrs[:len(attrs):len(attrs)], AttrErrorType.String(errType))
+		span.SetAttributes(AttrErrorType.String(errType))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	o.ins.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(durationAttrs...))
+}
+
+// responseInfo 从响应（或流式块）中收集的信息
+type responseInfo struct {
+	id           string
+	finishReason string
+	hasUsage     bool
+	inputTokens  int
+	outputTokens int
+}
+
+// add 合并一条响应消息或流式块中的信息，后到的结束原因和用量覆盖先到的
+func (r *responseInfo) add(msg *schema.Message) {
+	if msg == nil {
+		return
+	}
+	if r.id == "" {
+		r.id = adapters.RequestIDOf(msg)
+	}
+	if meta := msg.ResponseMeta; meta != nil {
+		if meta.FinishReason != "" {
+			r.finishReason = meta.FinishReason
+		}
+		if u := meta.Usage; u != nil {
+			r.hasUsage = true
+			r.inputTokens, r.outputTokens = u.PromptTokens, u.CompletionTokens
+		}
+	}
+}
+
+// requestAttributes 请求属性（同时用作指标维度，保持低基数）
+func requestAttributes(req *types.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{AttrOperationName.String(OperationChat)}
+	if req.Info != nil {
+		attrs = append(attrs, AttrSystem.String(System(req.Info.Provider)), AttrRequestModel.String(req.Info.Name))
+	}
+	return attrs
+}
+
+// spanName 按语义约定命名为 "{operation} {model}"
+func spanName(req *types.Request) string {
+	if req.Info == nil || req.Info.Name == "" {
+		return OperationChat
+	}
+	return OperationChat + " " + req.Info.Name
+}
+
+// errorType error.type 取值：HTTP状态码、超时或错误类型名
+func errorType(err error) string {
+	if code := adapters.StatusCodeOf(err); code != 0 {
+		return strconv.Itoa(code)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	return fmt.Sprintf("%T", err)
+}
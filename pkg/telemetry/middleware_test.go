@@ -0,0 +1,254 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"ai-bridge/pkg/adapters"
+	"ai-bridge/pkg/options"
+	"ai-bridge/pkg/types"
+)
+
+// testProviders 创建记录到内存的 TracerProvider 和 MeterProvider
+func testProviders() (*tracetest.SpanRecorder, *sdktrace.TracerProvider, *sdkmetric.ManualReader, *sdkmetric.MeterProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	return recorder, tp, reader, mp
+}
+
+// assistantWithMeta 带结束原因和用量的响应
+func assistantWithMeta(content, finishReason string, usage *schema.TokenUsage) *schema.Message {
+	msg := schema.AssistantMessage(content, nil)
+	msg.ResponseMeta = &schema.ResponseMeta{FinishReason: finishReason, Usage: usage}
+	return msg
+}
+
+// spanAttrs 将 span 属性转换为 map
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// collectMetrics 收集指标，返回 名称 -> 数据点数量
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]uint64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	counts := make(map[string]uint64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += dp.Count
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += dp.Count
+				}
+			}
+		}
+	}
+	return counts
+}
+
+var gpt4o = &types.ModelInfo{Provider: types.ProviderGPT, Name: "gpt-4o"}
+
+func TestMiddlewareChat(t *testing.T) {
+	recorder, tp, reader, mp := testProviders()
+	resp := assistantWithMeta("hi", "stop", &schema.TokenUsage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15})
+	resp.Extra = map[string]any{adapters.ExtraKeyRequestID: "req-1"}
+
+	handler := Middleware(WithTracerProvider(tp), WithMeterProvider(mp))(func(ctx context.Context, req *types.Request) (*types.Response, error) {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			t.Error("next handler should run inside the span")
+		}
+		return &types.Response{Message: resp}, nil
+	})
+	if _, err := handler(context.Background(), &types.Request{Op: types.OpChat, Info: gpt4o}); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "chat gpt-4o" || span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("span = %q kind %v", span.Name(), span.SpanKind())
+	}
+	attrs := spanAttrs(span)
+	if attrs[AttrSystem].AsString() != "openai" || attrs[AttrRequestModel].AsString() != "gpt-4o" ||
+		attrs[AttrOperationName].AsString() != "chat" || attrs[AttrResponseID].AsString() != "req-1" {
+		t.Errorf("request attributes = %v", attrs)
+	}
+	if attrs[AttrUsageInputTokens].AsInt64() != 12 || attrs[AttrUsageOutputTokens].AsInt64() != 3 {
+		t.Errorf("usage attributes = %v", attrs)
+	}
+	if reasons := attrs[AttrResponseFinishReasons].AsStringSlice(); len(reasons) != 1 || reasons[0] != "stop" {
+		t.Errorf("finish reasons = %v", reasons)
+	}
+
+	metrics := collectMetrics(t, reader)
+	if metrics[MetricOperationDuration] != 1 || metrics[MetricTokenUsage] != 2 {
+		t.Errorf("metrics = %v", metrics)
+	}
+}
+
+func TestMiddlewareStream(t *testing.T) {
+	recorder, tp, reader, mp := testProviders()
+	chunks := []*schema.Message{
+		schema.AssistantMessage("你好", nil),
+		assistantWithMeta("，世界", "stop", &schema.TokenUsage{PromptTokens: 5, CompletionTokens: 4}),
+	}
+	handler := Middleware(WithTracerProvider(tp), WithMeterProvider(mp))(func(ctx context.Context, req *types.Request) (*types.Response, error) {
+		return &types.Response{Stream: schema.StreamReaderFromArray(chunks)}, nil
+	})
+
+	resp, err := handler(context.Background(), &types.Request{Op: types.OpChatStream, Info: gpt4o})
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if len(recorder.Ended()) != 0 {
+		t.Fatal("stream span should stay open until the stream ends")
+	}
+
+	var content string
+	for {
+		msg, err := resp.Stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error: %v", err)
+		}
+		content += msg.Content
+	}
+	resp.Stream.Close()
+	if content != "你好，世界" {
+		t.Errorf("content = %q", content)
+	}
+
+	// span 在转发协程中结束
+	var spans []sdktrace.ReadOnlySpan
+	for i := 0; i < 100 && len(spans) == 0; i++ {
+		spans = recorder.Ended()
+		if len(spans) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+	attrs := spanAttrs(spans[0])
+	if attrs[AttrUsageOutputTokens].AsInt64() != 4 || attrs[AttrResponseFinishReasons].AsStringSlice()[0] != "stop" {
+		t.Errorf("stream attributes = %v", attrs)
+	}
+	if events := spans[0].Events(); len(events) != 1 || events[0].Name != "gen_ai.first_token" {
+		t.Errorf("events = %v", events)
+	}
+
+	metrics := collectMetrics(t, reader)
+	if metrics[MetricTimeToFirstToken] != 1 || metrics[MetricTokensPerSecond] != 1 || metrics[MetricOperationDuration] != 1 {
+		t.Errorf("metrics = %v", metrics)
+	}
+}
+
+func TestMiddlewareError(t *testing.T) {
+	recorder, tp, _, mp := testProviders()
+	handler := Middleware(WithTracerProvider(tp), WithMeterProvider(mp))(func(ctx context.Context, req *types.Request) (*types.Response, error) {
+		return nil, &adapters.APIError{StatusCode: 429, Message: "rate limited"}
+	})
+
+	if _, err := handler(context.Background(), &types.Request{Op: types.OpChat, Info: gpt4o}); err == nil {
+		t.Fatal("expected error")
+	}
+	span := recorder.Ended()[0]
+	if span.Status().Code != codes.Error || spanAttrs(span)[AttrErrorType].AsString() != "429" {
+		t.Errorf("status = %v, attrs = %v", span.Status(), spanAttrs(span))
+	}
+}
+
+func TestStartToolSpan(t *testing.T) {
+	recorder, tp, _, _ := testProviders()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	_, end := StartToolSpan(context.Background(), "get_weather", "call-1")
+	end(nil)
+
+	span := recorder.Ended()[0]
+	attrs := spanAttrs(span)
+	if span.Name() != "execute_tool get_weather" || attrs[AttrToolName].AsString() != "get_weather" || attrs[AttrToolCallID].AsString() != "call-1" {
+		t.Errorf("span = %q, attrs = %v", span.Name(), attrs)
+	}
+}
+
+func TestTracePropagation(t *testing.T) {
+	recorder, tp, _, _ := testProviders()
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	}()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	// 上游厂商收到的请求头应携带同一个 trace
+	var upstreamHeader string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamHeader = r.Header.Get("traceparent")
+	}))
+	defer upstream.Close()
+
+	client, err := adapters.NewHTTPClient(options.ApplyOptions())
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error: %v", err)
+	}
+	server := Handler("POST /chat", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, upstream.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("upstream request error: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/chat", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	server.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].SpanContext().TraceID().String() != traceID || spans[0].SpanKind() != trace.SpanKindServer {
+		t.Fatalf("server spans = %v", spans)
+	}
+	if want := "00-" + traceID + "-" + spans[0].SpanContext().SpanID().String() + "-01"; upstreamHeader != want {
+		t.Errorf("upstream traceparent = %q, want %q", upstreamHeader, want)
+	}
+}
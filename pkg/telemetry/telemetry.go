@@ -0,0 +1,142 @@
+// Package telemetry 提供 OpenTelemetry 链路追踪与指标
+// 属性遵循 GenAI 语义约定（gen_ai.*），默认使用全局 TracerProvider/MeterProvider，
+// 未配置时为 no-op。
+package telemetry
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+
+	"ai-bridge/pkg/types"
+)
+
+// instrumentationName Tracer/Meter 名称
+const instrumentationName = "ai-bridge"
+
+// GenAI 语义约定属性
+const (
+	AttrSystem                = attribute.Key("gen_ai.system")
+	AttrOperationName         = attribute.Key("gen_ai.operation.name")
+	AttrRequestModel          = attribute.Key("gen_ai.request.model")
+	AttrResponseID            = attribute.Key("gen_ai.response.id")
+	AttrResponseFinishReasons = attribute.Key("gen_ai.response.finish_reasons")
+	AttrUsageInputTokens      = attribute.Key("gen_ai.usage.input_tokens")
+	AttrUsageOutputTokens     = attribute.Key("gen_ai.usage.output_tokens")
+	AttrTokenType             = attribute.Key("gen_ai.token.type")
+	AttrToolName              = attribute.Key("gen_ai.tool.name")
+	AttrToolCallID            = attribute.Key("gen_ai.tool.call.id")
+	AttrErrorType             = attribute.Key("error.type")
+)
+
+// GenAI 操作名称
+const (
+	OperationChat        = "chat"
+	OperationExecuteTool = "execute_tool"
+)
+
+// 指标名称
+const (
+	MetricOperationDuration = "gen_ai.client.operation.duration"       // 调用耗时（秒）
+	MetricTokenUsage        = "gen_ai.client.token.usage"              // token用量
+	MetricTimeToFirstToken  = "gen_ai.client.time_to_first_token"      // 流式首个token耗时（秒）
+	MetricTokensPerSecond   = "gen_ai.client.output_tokens_per_second" // 流式输出速度
+)
+
+// Config 遥测配置
+type Config struct {
+	TracerProvider trace.TracerProvider // 默认 otel.GetTracerProvider()
+	MeterProvider  metric.MeterProvider // 默认 otel.GetMeterProvider()
+}
+
+// Option 遥测配置选项
+type Option func(*Config)
+
+// WithTracerProvider 设置 TracerProvider
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Config) {
+		c.TracerProvider = tp
+	}
+}
+
+// WithMeterProvider 设置 MeterProvider
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *Config) {
+		c.MeterProvider = mp
+	}
+}
+
+// newConfig 应用选项，未设置的 Provider 使用全局默认值
+func newConfig(opts ...Option) *Config {
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+	return cfg
+}
+
+// instruments 指标记录器
+type instruments struct {
+	duration         metric.Float64Histogram
+	tokenUsage       metric.Int64Histogram
+	timeToFirstToken metric.Float64Histogram
+	tokensPerSecond  metric.Float64Histogram
+}
+
+// newInstruments 创建指标记录器，创建失败时交给全局错误处理器并使用 no-op 记录器
+func newInstruments(meter metric.Meter) *instruments {
+	return &instruments{
+		duration: float64Histogram(meter, MetricOperationDuration,
+			metric.WithDescription("GenAI operation duration"), metric.WithUnit("s")),
+		tokenUsage: int64Histogram(meter, MetricTokenUsage,
+			metric.WithDescription("Number of input and output tokens used"), metric.WithUnit("{token}")),
+		timeToFirstToken: float64Histogram(meter, MetricTimeToFirstToken,
+			metric.WithDescription("Time to receive the first chunk of a streaming response"), metric.WithUnit("s")),
+		tokensPerSecond: float64Histogram(meter, MetricTokensPerSecond,
+			metric.WithDescription("Output tokens per second after the first chunk of a streaming response"), metric.WithUnit("{token}/s")),
+	}
+}
+
+// float64Histogram 创建浮点直方图
+func float64Histogram(meter metric.Meter, name string, opts ...metric.Float64HistogramOption) metric.Float64Histogram {
+	h, err := meter.Float64Histogram(name, opts...)
+	if err != nil {
+		otel.Handle(err)
+		return noop.Float64Histogram{}
+	}
+	return h
+}
+
+// int64Histogram 创建整数直方图
+func int64Histogram(meter metric.Meter, name string, opts ...metric.Int64HistogramOption) metric.Int64Histogram {
+	h, err := meter.Int64Histogram(name, opts...)
+	if err != nil {
+		otel.Handle(err)
+		return noop.Int64Histogram{}
+	}
+	return h
+}
+
+// System 将厂商转换为 gen_ai.system 取值（语义约定中已定义的使用约定值，其余使用厂商名）
+func System(provider types.Provider) string {
+	switch provider {
+	case types.ProviderGPT:
+		return "openai"
+	case types.ProviderClaude:
+		return "anthropic"
+	case types.ProviderGemini:
+		return "gcp.gemini"
+	case types.ProviderGrok:
+		return "xai"
+	default:
+		return string(provider)
+	}
+}
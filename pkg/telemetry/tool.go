@@ -0,0 +1,30 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartToolSpan 开始一次工具执行的 "execute_tool {name}" span（使用全局 TracerProvider）
+// 返回的 end 函数用于结束 span，err 非 nil 时标记为失败
+func StartToolSpan(ctx context.Context, name, callID string) (context.Context, func(err error)) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, OperationExecuteTool+" "+name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			AttrOperationName.String(OperationExecuteTool),
+			AttrToolName.String(name),
+			AttrToolCallID.String(callID),
+		),
+	)
+	return ctx, func(err error) {
+		if err != nil {
+			span.SetAttributes(AttrErrorType.String(errorType(err)))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -2,6 +2,8 @@ package types
 
 import (
 	"context"
+	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/cloudwego/eino/components/tool"
@@ -22,6 +24,18 @@ type Config struct {
 	// MaxRetries 最大重试次数
 	MaxRetries int
 
+	// RetryBaseDelay 重试退避的初始等待时间（默认500ms，每次重试翻倍）
+	RetryBaseDelay time.Duration
+
+	// RetryMaxDelay 重试退避的最大等待时间（默认30s）
+	RetryMaxDelay time.Duration
+
+	// OnRetry 重试钩子，每次重试等待前调用
+	OnRetry func(ctx context.Context, event RetryEvent)
+
+	// RetryIf 自定义可重试判断（为空时使用 adapters.IsRetryableError）
+	RetryIf func(err error) bool
+
 	// Temperature 温度参数
 	Temperature float32
 
@@ -43,12 +57,35 @@ type Config struct {
 	// Organization OpenAI组织ID（仅OpenAI使用）
 	Organization string
 
-	// Proxy 代理地址
+	// Proxy 代理地址（支持 http://、https://、socks5://）
 	Proxy string
 
+	// CAFile 自定义CA证书文件（PEM格式，追加到系统证书池）
+	CAFile string
+
+	// CertFile mTLS客户端证书文件（PEM格式）
+	CertFile string
+
+	// KeyFile mTLS客户端私钥文件（PEM格式）
+	KeyFile string
+
+	// InsecureSkipVerify 是否跳过服务端证书校验（仅用于测试环境）
+	InsecureSkipVerify bool
+
+	// TransportWrapper 包装底层HTTP传输（可选，如 cassette.Recorder 录制/回放上游请求）
+	// 位于最内层（代理和TLS之上），可看到最终发送的请求
+	TransportWrapper func(http.RoundTripper) http.RoundTripper
+
 	// EnableLog 是否启用日志
+	// 启用后记录厂商、模型、耗时、token用量、结束原因和错误
 	EnableLog bool
 
+	// LogHandler 自定义日志处理器（可选，默认使用 slog.Default()）
+	LogHandler slog.Handler
+
+	// LogPayload 是否以Debug级别记录完整请求/响应内容（需同时启用 EnableLog，密钥会脱敏）
+	LogPayload bool
+
 	// SystemPrompt 系统提示词模板
 	// 用于设置AI的角色和行为准则
 	SystemPrompt string
@@ -59,6 +96,43 @@ type Config struct {
 
 	// SkillPaths Skill 文件夹路径列表
 	SkillPaths []string
+
+	// SafetySettings 安全过滤设置（仅Gemini使用）
+	SafetySettings []SafetySetting
+
+	// Middlewares 调用中间件（可选，第一个位于最外层）
+	Middlewares []Middleware
+}
+
+// SafetySetting 安全过滤设置
+// 参考: https://ai.google.dev/gemini-api/docs/safety-settings
+type SafetySetting struct {
+	// Category 危害类别，如 HARM_CATEGORY_HARASSMENT
+	Category string `json:"category"`
+
+	// Threshold 拦截阈值，如 BLOCK_ONLY_HIGH、BLOCK_NONE
+	Threshold string `json:"threshold"`
+}
+
+// RetryEvent 重试事件
+type RetryEvent struct {
+	// Provider 厂商
+	Provider Provider
+
+	// Model 模型名称
+	Model string
+
+	// Attempt 失败的尝试次数（从1开始）
+	Attempt int
+
+	// MaxRetries 最大重试次数
+	MaxRetries int
+
+	// Delay 下一次重试前的等待时间
+	Delay time.Duration
+
+	// Err 本次失败的错误
+	Err error
 }
 
 // DefaultConfig 返回默认配置
@@ -119,10 +193,15 @@ type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+	CachedTokens     int `json:"cached_tokens,omitempty"` // 命中缓存的输入token数（包含在 PromptTokens 中）
 }
 
 // StreamChunk 流式响应块
+// 最后一个块（Done=true）携带结束原因、token使用情况和请求ID
 type StreamChunk struct {
-	Content string `json:"content"`
-	Done    bool   `json:"done"`
+	Content      string `json:"content"`
+	Done         bool   `json:"done"`
+	FinishReason string `json:"finish_reason,omitempty"`
+	Usage        *Usage `json:"usage,omitempty"`
+	RequestID    string `json:"request_id,omitempty"`
 }
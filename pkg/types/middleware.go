@@ -0,0 +1,79 @@
+package types
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Operation AIBridge 调用类型
+type Operation string
+
+const (
+	OpChat           Operation = "chat"            // Chat
+	OpChatStream     Operation = "chat_stream"     // ChatStream
+	OpGenerate       Operation = "generate"        // Generate
+	OpGenerateStream Operation = "generate_stream" // GenerateStream
+)
+
+// Request 中间件看到的调用请求，中间件可以修改后传给下一个处理器
+type Request struct {
+	// Op 调用类型
+	Op Operation
+
+	// Info 被调用的模型信息
+	Info *ModelInfo
+
+	// Messages 完整消息列表（Chat/ChatStream）
+	Messages []*schema.Message
+
+	// Prompt 提示词（Generate/GenerateStream）
+	Prompt string
+
+	// Options 本次调用的模型选项（如 model.WithTemperature），传给底层 ChatModel
+	Options []model.Option
+}
+
+// Response 中间件看到的调用结果，按调用类型填充其中一个字段
+type Response struct {
+	// Message 完整响应（Chat）
+	Message *schema.Message
+
+	// Stream 流式响应（ChatStream），中间件可包装或替换
+	Stream *schema.StreamReader[*schema.Message]
+
+	// Text 生成的文本（Generate/GenerateStream）
+	Text string
+}
+
+// Handler 处理一次 AIBridge 调用
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware 中间件，包装下一个处理器
+// 可在调用前修改请求、调用后修改响应，或不调用 next 直接返回（短路）
+type Middleware func(next Handler) Handler
+
+// Chain 将多个中间件组合为一个，第一个中间件位于最外层
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next Handler) Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// modelOptionsKey 上下文中模型选项的键
+type modelOptionsKey struct{}
+
+// WithModelOptions 将本次调用的模型选项写入上下文，适配器调用 ChatModel 时传入
+func WithModelOptions(ctx context.Context, opts ...model.Option) context.Context {
+	return context.WithValue(ctx, modelOptionsKey{}, opts)
+}
+
+// ModelOptionsFromContext 获取上下文中的模型选项
+func ModelOptionsFromContext(ctx context.Context) []model.Option {
+	opts, _ := ctx.Value(modelOptionsKey{}).([]model.Option)
+	return opts
+}
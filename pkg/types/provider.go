@@ -14,66 +14,71 @@ const (
 	ProviderGrok     Provider = "grok"     // xAI Grok
 	ProviderDeepseek Provider = "deepseek" // Deepseek
 	ProviderOllama   Provider = "ollama"   // Ollama本地模型
+	ProviderMock     Provider = "mock"     // 脚本化模拟厂商（离线测试）
 )
 
 // ModelInfo 模型信息
+// 价格单位为 美元/百万token，仅供参考，以厂商官网为准（可通过 cost.PriceTable 覆盖）
 type ModelInfo struct {
-	Name        string
-	Provider    Provider
-	MaxTokens   int
-	Description string
+	Name             string
+	Provider         Provider
+	MaxTokens        int
+	Description      string
+	InputPrice       float64 // 输入价格
+	OutputPrice      float64 // 输出价格
+	CachedInputPrice float64 // 命中缓存的输入价格（0表示按输入价格计算）
 }
 
 // ModelRegistry 模型注册表
 var ModelRegistry = map[Provider][]ModelInfo{
 	ProviderQWen: {
-		{Name: "qwen-turbo", Provider: ProviderQWen, MaxTokens: 8192, Description: "通义千问Turbo"},
-		{Name: "qwen-plus", Provider: ProviderQWen, MaxTokens: 32768, Description: "通义千问Plus"},
-		{Name: "qwen-max", Provider: ProviderQWen, MaxTokens: 32768, Description: "通义千问Max"},
-		{Name: "qwen-coder-plus", Provider: ProviderQWen, MaxTokens: 32768, Description: "通义千问代码模型"},
+		{Name: "qwen-turbo", Provider: ProviderQWen, MaxTokens: 8192, Description: "通义千问Turbo", InputPrice: 0.05, OutputPrice: 0.2},
+		{Name: "qwen-plus", Provider: ProviderQWen, MaxTokens: 32768, Description: "通义千问Plus", InputPrice: 0.4, OutputPrice: 1.2},
+		{Name: "qwen-max", Provider: ProviderQWen, MaxTokens: 32768, Description: "通义千问Max", InputPrice: 1.6, OutputPrice: 6.4},
+		{Name: "qwen-coder-plus", Provider: ProviderQWen, MaxTokens: 32768, Description: "通义千问代码模型", InputPrice: 0.35, OutputPrice: 1.4},
 	},
 	ProviderKimi: {
-		{Name: "moonshot-v1-8k", Provider: ProviderKimi, MaxTokens: 8192, Description: "Kimi 8K"},
-		{Name: "moonshot-v1-32k", Provider: ProviderKimi, MaxTokens: 32768, Description: "Kimi 32K"},
-		{Name: "moonshot-v1-128k", Provider: ProviderKimi, MaxTokens: 131072, Description: "Kimi 128K"},
+		{Name: "moonshot-v1-8k", Provider: ProviderKimi, MaxTokens: 8192, Description: "Kimi 8K", InputPrice: 1.65, OutputPrice: 1.65},
+		{Name: "moonshot-v1-32k", Provider: ProviderKimi, MaxTokens: 32768, Description: "Kimi 32K", InputPrice: 3.3, OutputPrice: 3.3},
+		{Name: "moonshot-v1-128k", Provider: ProviderKimi, MaxTokens: 131072, Description: "Kimi 128K", InputPrice: 8.3, OutputPrice: 8.3},
 	},
 	ProviderGLM: {
-		{Name: "glm-4", Provider: ProviderGLM, MaxTokens: 8192, Description: "GLM-4"},
-		{Name: "glm-4-plus", Provider: ProviderGLM, MaxTokens: 8192, Description: "GLM-4 Plus"},
-		{Name: "glm-4-flash", Provider: ProviderGLM, MaxTokens: 8192, Description: "GLM-4 Flash"},
-		{Name: "glm-4v", Provider: ProviderGLM, MaxTokens: 2048, Description: "GLM-4V多模态"},
+		{Name: "glm-4", Provider: ProviderGLM, MaxTokens: 8192, Description: "GLM-4", InputPrice: 13.8, OutputPrice: 13.8},
+		{Name: "glm-4-plus", Provider: ProviderGLM, MaxTokens: 8192, Description: "GLM-4 Plus", InputPrice: 6.9, OutputPrice: 6.9},
+		{Name: "glm-4-flash", Provider: ProviderGLM, MaxTokens: 8192, Description: "GLM-4 Flash", InputPrice: 0, OutputPrice: 0},
+		{Name: "glm-4v", Provider: ProviderGLM, MaxTokens: 2048, Description: "GLM-4V多模态", InputPrice: 6.9, OutputPrice: 6.9},
 	},
 	ProviderMiniMax: {
-		{Name: "abab6.5s-chat", Provider: ProviderMiniMax, MaxTokens: 8192, Description: "MiniMax 6.5s"},
-		{Name: "abab6.5-chat", Provider: ProviderMiniMax, MaxTokens: 8192, Description: "MiniMax 6.5"},
-		{Name: "abab6-chat", Provider: ProviderMiniMax, MaxTokens: 8192, Description: "MiniMax 6"},
+		{Name: "abab6.5s-chat", Provider: ProviderMiniMax, MaxTokens: 8192, Description: "MiniMax 6.5s", InputPrice: 1.4, OutputPrice: 1.4},
+		{Name: "abab6.5-chat", Provider: ProviderMiniMax, MaxTokens: 8192, Description: "MiniMax 6.5", InputPrice: 4.1, OutputPrice: 4.1},
+		{Name: "abab6-chat", Provider: ProviderMiniMax, MaxTokens: 8192, Description: "MiniMax 6", InputPrice: 13.8, OutputPrice: 13.8},
 	},
 	ProviderClaude: {
-		{Name: "claude-3-opus-20240229", Provider: ProviderClaude, MaxTokens: 200000, Description: "Claude 3 Opus"},
-		{Name: "claude-3-sonnet-20240229", Provider: ProviderClaude, MaxTokens: 200000, Description: "Claude 3 Sonnet"},
-		{Name: "claude-3-haiku-20240307", Provider: ProviderClaude, MaxTokens: 200000, Description: "Claude 3 Haiku"},
-		{Name: "claude-3-5-sonnet-20240620", Provider: ProviderClaude, MaxTokens: 200000, Description: "Claude 3.5 Sonnet"},
+		{Name: "claude-3-opus-20240229", Provider: ProviderClaude, MaxTokens: 200000, Description: "Claude 3 Opus", InputPrice: 15, OutputPrice: 75, CachedInputPrice: 1.5},
+		{Name: "claude-3-sonnet-20240229", Provider: ProviderClaude, MaxTokens: 200000, Description: "Claude 3 Sonnet", InputPrice: 3, OutputPrice: 15, CachedInputPrice: 0.3},
+		{Name: "claude-3-haiku-20240307", Provider: ProviderClaude, MaxTokens: 200000, Description: "Claude 3 Haiku", InputPrice: 0.25, OutputPrice: 1.25, CachedInputPrice: 0.03},
+		{Name: "claude-3-5-sonnet-20240620", Provider: ProviderClaude, MaxTokens: 200000, Description: "Claude 3.5 Sonnet", InputPrice: 3, OutputPrice: 15, CachedInputPrice: 0.3},
 	},
 	ProviderGPT: {
-		{Name: "gpt-3.5-turbo", Provider: ProviderGPT, MaxTokens: 16385, Description: "GPT-3.5 Turbo"},
-		{Name: "gpt-4", Provider: ProviderGPT, MaxTokens: 8192, Description: "GPT-4"},
-		{Name: "gpt-4-turbo", Provider: ProviderGPT, MaxTokens: 128000, Description: "GPT-4 Turbo"},
-		{Name: "gpt-4o", Provider: ProviderGPT, MaxTokens: 128000, Description: "GPT-4o"},
-		{Name: "gpt-4o-mini", Provider: ProviderGPT, MaxTokens: 128000, Description: "GPT-4o Mini"},
+		{Name: "gpt-3.5-turbo", Provider: ProviderGPT, MaxTokens: 16385, Description: "GPT-3.5 Turbo", InputPrice: 0.5, OutputPrice: 1.5},
+		{Name: "gpt-4", Provider: ProviderGPT, MaxTokens: 8192, Description: "GPT-4", InputPrice: 30, OutputPrice: 60},
+		{Name: "gpt-4-turbo", Provider: ProviderGPT, MaxTokens: 128000, Description: "GPT-4 Turbo", InputPrice: 10, OutputPrice: 30},
+		{Name: "gpt-4o", Provider: ProviderGPT, MaxTokens: 128000, Description: "GPT-4o", InputPrice: 2.5, OutputPrice: 10, CachedInputPrice: 1.25},
+		{Name: "gpt-4o-mini", Provider: ProviderGPT, MaxTokens: 128000, Description: "GPT-4o Mini", InputPrice: 0.15, OutputPrice: 0.6, CachedInputPrice: 0.075},
 	},
 	ProviderGemini: {
-		{Name: "gemini-1.5-pro", Provider: ProviderGemini, MaxTokens: 2097152, Description: "Gemini 1.5 Pro"},
-		{Name: "gemini-1.5-flash", Provider: ProviderGemini, MaxTokens: 1048576, Description: "Gemini 1.5 Flash"},
-		{Name: "gemini-1.0-pro", Provider: ProviderGemini, MaxTokens: 32768, Description: "Gemini 1.0 Pro"},
+		{Name: "gemini-1.5-pro", Provider: ProviderGemini, MaxTokens: 2097152, Description: "Gemini 1.5 Pro", InputPrice: 1.25, OutputPrice: 5, CachedInputPrice: 0.3125},
+		{Name: "gemini-1.5-flash", Provider: ProviderGemini, MaxTokens: 1048576, Description: "Gemini 1.5 Flash", InputPrice: 0.075, OutputPrice: 0.3, CachedInputPrice: 0.01875},
+		{Name: "gemini-1.0-pro", Provider: ProviderGemini, MaxTokens: 32768, Description: "Gemini 1.0 Pro", InputPrice: 0.5, OutputPrice: 1.5},
 	},
 	ProviderGrok: {
-		{Name: "grok-1", Provider: ProviderGrok, MaxTokens: 131072, Description: "Grok-1"},
-		{Name: "grok-2", Provider: ProviderGrok, MaxTokens: 131072, Description: "Grok-2"},
+		{Name: "grok-1", Provider: ProviderGrok, MaxTokens: 131072, Description: "Grok-1", InputPrice: 5, OutputPrice: 15},
+		{Name: "grok-2", Provider: ProviderGrok, MaxTokens: 131072, Description: "Grok-2", InputPrice: 2, OutputPrice: 10},
 	},
 	ProviderDeepseek: {
-		{Name: "deepseek-chat", Provider: ProviderDeepseek, MaxTokens: 32768, Description: "Deepseek Chat"},
-		{Name: "deepseek-coder", Provider: ProviderDeepseek, MaxTokens: 32768, Description: "Deepseek Coder"},
-		{Name: "deepseek-reasoner", Provider: ProviderDeepseek, MaxTokens: 32768, Description: "Deepseek Reasoner"},
+		{Name: "deepseek-chat", Provider: ProviderDeepseek, MaxTokens: 32768, Description: "Deepseek Chat", InputPrice: 0.27, OutputPrice: 1.1, CachedInputPrice: 0.07},
+		{Name: "deepseek-coder", Provider: ProviderDeepseek, MaxTokens: 32768, Description: "Deepseek Coder", InputPrice: 0.27, OutputPrice: 1.1, CachedInputPrice: 0.07},
+		{Name: "deepseek-reasoner", Provider: ProviderDeepseek, MaxTokens: 32768, Description: "Deepseek Reasoner", InputPrice: 0.55, OutputPrice: 2.19, CachedInputPrice: 0.14},
 	},
 }
 
@@ -99,8 +104,8 @@ func GetModelInfo(provider Provider, modelName string) *ModelInfo {
 
 // IsValidProvider 检查厂商是否有效
 func IsValidProvider(provider Provider) bool {
-	// Ollama是特殊厂商，允许任意模型名
-	if provider == ProviderOllama {
+	// Ollama和Mock是特殊厂商，允许任意模型名
+	if provider == ProviderOllama || provider == ProviderMock {
 		return true
 	}
 	_, ok := ModelRegistry[provider]